@@ -1,17 +1,25 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"syscall"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/yourorg/apidoc/internal/config"
 	"github.com/yourorg/apidoc/internal/generator"
-	"github.com/yourorg/apidoc/internal/har"
+	"github.com/yourorg/apidoc/internal/importer"
+	"github.com/yourorg/apidoc/internal/proxy"
 	"github.com/yourorg/apidoc/internal/server"
 	"github.com/yourorg/apidoc/internal/store"
 )
@@ -23,12 +31,29 @@ const defaultConfigContent = `llm:
   model: "gpt-4o"
   max_tokens: 4096
   temperature: 0.2
+  # directory overriding the built-in prompts: system.txt, user_example.txt,
+  # translate.tmpl; cached batches are redone when the prompts change
+  # prompt_dir: "./prompts"
 
 output:
   dir: "./output"
+  # markdown, openapi (YAML), openapi-json, postman, html
   formats:
     - markdown
     - openapi
+  # "3.0" or "3.1"
+  openapi_version: "3.0"
+  # call chain diagrams in README.md: mermaid, plantuml
+  diagrams:
+    - mermaid
+  # Mermaid script the html site loads to draw diagrams; unset, the site
+  # makes no external requests and shows the diagram sources as text
+  # mermaid_url: "https://cdn.jsdelivr.net/npm/mermaid@10/dist/mermaid.min.js"
+  # directory of Markdown templates overriding readme.md.tmpl / endpoint.md.tmpl
+  # templates_dir: "./doc-templates"
+  # doc languages (zh, en); each is written to <dir>/<lang>/, others are
+  # translated from zh by a second LLM pass
+  # languages: [zh, en]
 
 filter:
   ignore_extensions:
@@ -74,6 +99,17 @@ server:
   host: "127.0.0.1"
   port: 3000
   cors_extension_id: ""
+  # per-request cap for traffic uploads and chunks (after gzip decoding)
+  max_body_bytes: 33554432
+  # caps for one chunked upload: chunk count and total size of its chunks
+  max_upload_chunks: 1000
+  max_upload_bytes: 268435456
+
+proxy:
+  listen: "127.0.0.1:9000"
+  max_body_bytes: 1048576
+  # hosts decrypted and recorded in --forward mode ("*.example.com" allowed)
+  allow_hosts: []
 
 log:
   level: "info"
@@ -104,6 +140,12 @@ func newRootCmd() *cobra.Command {
 	root.AddCommand(newGenerateCmd(&cfgPath, &verbose))
 	root.AddCommand(newImportCmd(&cfgPath))
 	root.AddCommand(newServeCmd(&cfgPath))
+	root.AddCommand(newProxyCmd(&cfgPath))
+	root.AddCommand(newReplayCmd(&cfgPath))
+	root.AddCommand(newMockCmd(&cfgPath))
+	root.AddCommand(newTestgenCmd(&cfgPath))
+	root.AddCommand(newSDKCmd(&cfgPath))
+	root.AddCommand(newVerifyCmd(&cfgPath))
 	root.AddCommand(newListCmd(&cfgPath))
 	root.AddCommand(newShowCmd(&cfgPath))
 	root.AddCommand(newDeleteCmd(&cfgPath))
@@ -166,12 +208,12 @@ func newInitCmd() *cobra.Command {
 }
 
 func newGenerateCmd(cfgPath *string, verbose *bool) *cobra.Command {
-	var harPath, scenario string
+	var harPath, filePath, format, scenario string
 	var noCache, resume bool
 
 	cmd := &cobra.Command{
 		Use:   "generate",
-		Short: "Generate API docs from HAR file",
+		Short: "Generate API docs from a HAR, Charles or mitmproxy capture",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cfg, s, err := openStore(*cfgPath)
 			if err != nil {
@@ -183,11 +225,15 @@ func newGenerateCmd(cfgPath *string, verbose *bool) *cobra.Command {
 				return err
 			}
 
-			// Parse HAR
-			fmt.Fprintf(cmd.OutOrStdout(), "parsing %s...\n", harPath)
-			logs, err := har.Parse(harPath)
+			// Parse capture
+			path, source, err := resolveCapture(harPath, filePath, format)
 			if err != nil {
-				return fmt.Errorf("parse HAR: %w", err)
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "parsing %s...\n", path)
+			logs, err := importer.Parse(path, source)
+			if err != nil {
+				return fmt.Errorf("parse %s: %w", source, err)
 			}
 			fmt.Fprintf(cmd.OutOrStdout(), "found %d requests\n", len(logs))
 
@@ -198,7 +244,7 @@ func newGenerateCmd(cfgPath *string, verbose *bool) *cobra.Command {
 			}
 
 			// Create session and save logs
-			sess, err := s.CreateSession("har", scenario, host)
+			sess, err := s.CreateSession(source, scenario, host)
 			if err != nil {
 				return err
 			}
@@ -214,7 +260,7 @@ func newGenerateCmd(cfgPath *string, verbose *bool) *cobra.Command {
 				}
 			}
 
-			doc, err := generator.Generate(sess, logs, cfg.LLM, s, progress, noCache, resume)
+			doc, err := generator.GenerateWithConfig(sess, logs, cfg, s, progress, noCache, resume)
 			if err != nil {
 				return fmt.Errorf("generate: %w", err)
 			}
@@ -224,21 +270,24 @@ func newGenerateCmd(cfgPath *string, verbose *bool) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&harPath, "har", "", "HAR file path (required)")
+	cmd.Flags().StringVar(&harPath, "har", "", "HAR file path")
+	cmd.Flags().StringVar(&filePath, "file", "", "capture file path (HAR, Charles .chlsj or mitmproxy JSON)")
+	cmd.Flags().StringVar(&format, "format", importer.FormatAuto, "capture format: auto|har|charles|mitmproxy")
 	cmd.Flags().StringVar(&scenario, "scenario", "", "scenario description (required)")
 	cmd.Flags().BoolVar(&noCache, "no-cache", false, "discard cache, regenerate all")
 	cmd.Flags().BoolVar(&resume, "resume", false, "resume from failed batches")
-	_ = cmd.MarkFlagRequired("har")
+	cmd.MarkFlagsOneRequired("har", "file")
+	cmd.MarkFlagsMutuallyExclusive("har", "file")
 	_ = cmd.MarkFlagRequired("scenario")
 	return cmd
 }
 
 func newImportCmd(cfgPath *string) *cobra.Command {
-	var harPath, scenario string
+	var harPath, filePath, format, scenario, sessionID string
 
 	cmd := &cobra.Command{
 		Use:   "import",
-		Short: "Import HAR file into database (without generating)",
+		Short: "Import a HAR, Charles or mitmproxy capture into database (without generating)",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			_, s, err := openStore(*cfgPath)
 			if err != nil {
@@ -246,9 +295,27 @@ func newImportCmd(cfgPath *string) *cobra.Command {
 			}
 			defer s.Close()
 
-			logs, err := har.Parse(harPath)
+			path, source, err := resolveCapture(harPath, filePath, format)
+			if err != nil {
+				return err
+			}
+			logs, err := importer.Parse(path, source)
 			if err != nil {
-				return fmt.Errorf("parse HAR: %w", err)
+				return fmt.Errorf("parse %s: %w", source, err)
+			}
+
+			if sessionID != "" {
+				if _, err := s.GetSession(sessionID); err != nil {
+					return fmt.Errorf("session not found: %s", sessionID)
+				}
+				if err := s.AppendLogs(sessionID, logs); err != nil {
+					return err
+				}
+				if err := s.ClearBatchCaches(sessionID, generator.StaleBatchKeys(logs)); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "appended %d logs → session %s\n", len(logs), sessionID)
+				return nil
 			}
 
 			host := "unknown"
@@ -256,7 +323,7 @@ func newImportCmd(cfgPath *string) *cobra.Command {
 				host = logs[0].Host
 			}
 
-			sess, err := s.CreateSession("har", scenario, host)
+			sess, err := s.CreateSession(source, scenario, host)
 			if err != nil {
 				return err
 			}
@@ -269,11 +336,32 @@ func newImportCmd(cfgPath *string) *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&harPath, "har", "", "HAR file path")
+	cmd.Flags().StringVar(&filePath, "file", "", "capture file path (HAR, Charles .chlsj or mitmproxy JSON)")
+	cmd.Flags().StringVar(&format, "format", importer.FormatAuto, "capture format: auto|har|charles|mitmproxy")
 	cmd.Flags().StringVar(&scenario, "scenario", "", "scenario description")
-	_ = cmd.MarkFlagRequired("har")
+	cmd.Flags().StringVar(&sessionID, "session", "", "append to an existing session instead of creating one")
+	cmd.MarkFlagsOneRequired("har", "file")
+	cmd.MarkFlagsMutuallyExclusive("har", "file")
+	cmd.MarkFlagsMutuallyExclusive("session", "scenario")
 	return cmd
 }
 
+// resolveCapture returns the capture path and its concrete format, which is
+// also used as the session source.
+func resolveCapture(harPath, filePath, format string) (string, string, error) {
+	if harPath != "" {
+		return harPath, importer.FormatHAR, nil
+	}
+	if format == "" || format == importer.FormatAuto {
+		detected, err := importer.DetectFormat(filePath)
+		if err != nil {
+			return "", "", err
+		}
+		format = detected
+	}
+	return filePath, format, nil
+}
+
 func newServeCmd(cfgPath *string) *cobra.Command {
 	var host string
 	var port int
@@ -314,6 +402,193 @@ func newServeCmd(cfgPath *string) *cobra.Command {
 	return cmd
 }
 
+func newProxyCmd(cfgPath *string) *cobra.Command {
+	var target, listen, scenario string
+	var forward bool
+	var allowHosts []string
+	var maxBody int64
+
+	cmd := &cobra.Command{
+		Use:   "proxy",
+		Short: "Run a recording reverse (--target) or forward (--forward) proxy",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, s, err := openStore(*cfgPath)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			if listen != "" {
+				cfg.Proxy.Listen = listen
+			}
+			if maxBody != 0 {
+				cfg.Proxy.MaxBodyBytes = maxBody
+			}
+			if len(allowHosts) > 0 {
+				cfg.Proxy.AllowHosts = allowHosts
+			}
+
+			var rec *proxy.Recorder
+			var handler http.Handler
+			var upstream string
+			if forward {
+				ca, err := loadCA()
+				if err != nil {
+					return err
+				}
+				if len(cfg.Proxy.AllowHosts) == 0 {
+					return errors.New("--forward requires --allow or proxy.allow_hosts")
+				}
+				// A forward proxy records whichever allowed hosts the client
+				// calls, so the session has no single host; each log keeps its own.
+				rec, err = proxy.NewRecorder(s, "proxy", scenario, "", cfg.Proxy.MaxBodyBytes)
+				if err != nil {
+					return err
+				}
+				fwd, err := proxy.NewForward(rec, ca, cfg.Proxy.AllowHosts)
+				if err != nil {
+					_ = rec.Close()
+					return err
+				}
+				handler = fwd
+				upstream = "forward proxy, recording " + strings.Join(cfg.Proxy.AllowHosts, ", ")
+			} else {
+				targetURL, err := url.Parse(target)
+				if err != nil {
+					return fmt.Errorf("parse target: %w", err)
+				}
+				rec, err = proxy.NewRecorder(s, "proxy", scenario, targetURL.Host, cfg.Proxy.MaxBodyBytes)
+				if err != nil {
+					return err
+				}
+				handler, err = proxy.NewReverse(targetURL, rec)
+				if err != nil {
+					_ = rec.Close()
+					return err
+				}
+				upstream = targetURL.String()
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			httpSrv := &http.Server{Addr: cfg.Proxy.Listen, Handler: handler}
+			errCh := make(chan error, 1)
+			go func() { errCh <- httpSrv.ListenAndServe() }()
+
+			fmt.Fprintf(cmd.OutOrStdout(), "proxying http://%s → %s\n", cfg.Proxy.Listen, upstream)
+			fmt.Fprintf(cmd.OutOrStdout(), "recording into session %s (Ctrl+C to stop)\n", rec.Session().ID)
+
+			select {
+			case err = <-errCh:
+			case <-ctx.Done():
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				err = httpSrv.Shutdown(shutdownCtx)
+				cancel()
+			}
+			if errors.Is(err, http.ErrServerClosed) {
+				err = nil
+			}
+			if closeErr := rec.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "recorded %d requests → session %s\n", rec.Count(), rec.Session().ID)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&target, "target", "", "upstream base URL for reverse mode, e.g. http://localhost:8080")
+	cmd.Flags().BoolVar(&forward, "forward", false, "run as HTTP(S) forward proxy using the local CA")
+	cmd.Flags().StringSliceVar(&allowHosts, "allow", nil, "hosts to decrypt and record in forward mode (repeatable, supports *.example.com)")
+	cmd.Flags().StringVar(&listen, "listen", "", "override proxy listen address")
+	cmd.Flags().StringVar(&scenario, "scenario", "", "scenario description")
+	cmd.Flags().Int64Var(&maxBody, "max-body", 0, "override max bytes of each body to record")
+	cmd.MarkFlagsOneRequired("target", "forward")
+	cmd.MarkFlagsMutuallyExclusive("target", "forward")
+
+	cmd.AddCommand(newProxyCACmd())
+	return cmd
+}
+
+func newProxyCACmd() *cobra.Command {
+	ca := &cobra.Command{
+		Use:   "ca",
+		Short: "Manage the local CA used to intercept HTTPS in forward mode",
+	}
+
+	var force bool
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "Generate the local CA under ~/.apidoc/ca",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := caDir()
+			if err != nil {
+				return err
+			}
+			if _, err := os.Stat(filepath.Join(dir, proxy.CACertFile)); err == nil && !force {
+				fmt.Fprintln(cmd.OutOrStdout(), "exists", filepath.Join(dir, proxy.CACertFile))
+				return nil
+			}
+			c, err := proxy.NewCA("apidoc local CA")
+			if err != nil {
+				return err
+			}
+			if err := c.Save(dir); err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "created", filepath.Join(dir, proxy.CACertFile))
+			fmt.Fprintln(cmd.OutOrStdout(), "install it as a trusted root on the client: apidoc proxy ca export --out apidoc-ca.pem")
+			return nil
+		},
+	}
+	initCmd.Flags().BoolVar(&force, "force", false, "regenerate even if a CA exists")
+
+	var out string
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Print or write the CA certificate (PEM) for installing on clients",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := loadCA()
+			if err != nil {
+				return err
+			}
+			if out == "" {
+				_, err := cmd.OutOrStdout().Write(c.CertPEM())
+				return err
+			}
+			if err := os.WriteFile(out, c.CertPEM(), 0o644); err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "wrote", out)
+			return nil
+		},
+	}
+	exportCmd.Flags().StringVar(&out, "out", "", "output file (default stdout)")
+
+	ca.AddCommand(initCmd, exportCmd)
+	return ca
+}
+
+func caDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".apidoc", "ca"), nil
+}
+
+func loadCA() (*proxy.CA, error) {
+	dir, err := caDir()
+	if err != nil {
+		return nil, err
+	}
+	c, err := proxy.LoadCA(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errors.New("local CA not found, run: apidoc proxy ca init")
+	}
+	return c, err
+}
+
 func newListCmd(cfgPath *string) *cobra.Command {
 	return &cobra.Command{
 		Use:   "list",
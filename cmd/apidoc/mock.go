@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yourorg/apidoc/internal/generator"
+	"github.com/yourorg/apidoc/internal/mock"
+)
+
+func newMockCmd(cfgPath *string) *cobra.Command {
+	var session, host, latency string
+	var port int
+
+	cmd := &cobra.Command{
+		Use:   "mock",
+		Short: "Serve a session's recorded responses as a mock API",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, s, err := openStore(*cfgPath)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			sess, err := s.GetSession(session)
+			if err != nil {
+				return fmt.Errorf("session not found: %w", err)
+			}
+			logs, err := s.GetLogs(sess.ID)
+			if err != nil {
+				return err
+			}
+			doc, err := generator.LoadDoc(s, sess, cfg)
+			if err != nil && !errors.Is(err, generator.ErrNoDoc) {
+				return err
+			}
+
+			handler, err := mock.New(logs, doc, mock.Options{Latency: latency})
+			if err != nil {
+				return err
+			}
+			addr := fmt.Sprintf("%s:%d", host, port)
+			endpoints := 0
+			if doc != nil {
+				endpoints = len(doc.Endpoints)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "mocking session %s (%d recorded responses, %d documented endpoints) on http://%s\n",
+				sess.ID, len(logs), endpoints, addr)
+			return http.ListenAndServe(addr, handler)
+		},
+	}
+
+	cmd.Flags().StringVar(&session, "session", "", "session id to serve")
+	cmd.Flags().StringVar(&host, "host", "127.0.0.1", "listen host")
+	cmd.Flags().IntVar(&port, "port", 4010, "listen port")
+	cmd.Flags().StringVar(&latency, "latency", mock.LatencyOff, "latency simulation: off|recorded|<duration> (e.g. 200ms)")
+	_ = cmd.MarkFlagRequired("session")
+	return cmd
+}
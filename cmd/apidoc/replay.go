@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yourorg/apidoc/internal/replay"
+)
+
+func newReplayCmd(cfgPath *string) *cobra.Command {
+	var session, baseURL, reportPath string
+	var strict bool
+
+	cmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Replay a recorded session against another environment and compare responses",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, s, err := openStore(*cfgPath)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			base, err := url.Parse(baseURL)
+			if err != nil || base.Scheme == "" || base.Host == "" {
+				return fmt.Errorf("--base-url must be an absolute URL, got %q", baseURL)
+			}
+			sess, err := s.GetSession(session)
+			if err != nil {
+				return fmt.Errorf("session not found: %w", err)
+			}
+			logs, err := s.GetLogs(sess.ID)
+			if err != nil {
+				return err
+			}
+
+			opts := replay.Options{BaseURL: base}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			rep, err := replay.Run(ctx, s, sess, logs, opts)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "SEQ\tREQUEST\tORIGINAL\tREPLAY\tSUBS\tRESULT")
+			for _, r := range rep.Results {
+				fmt.Fprintf(w, "%d\t%s %s\t%d\t%d\t%d\t%s\n",
+					r.Seq, r.Method, truncate(r.Path, 40), r.OriginalStatus, r.ReplayStatus, r.Substitutions, resultText(r))
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "replayed %d requests → session %s\n", len(rep.Results), rep.SessionID)
+
+			if reportPath != "" {
+				data, err := json.MarshalIndent(rep, "", "  ")
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(reportPath, data, 0o644); err != nil {
+					return err
+				}
+			}
+			if strict && !rep.Passed() {
+				return errors.New("replay differs from the recording")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&session, "session", "", "session id to replay")
+	cmd.Flags().StringVar(&baseURL, "base-url", "", "target base URL, e.g. https://staging.example.com")
+	cmd.Flags().StringVar(&reportPath, "report", "", "write the comparison as JSON to this file")
+	cmd.Flags().BoolVar(&strict, "strict", false, "exit with an error when any status or response shape differs")
+	_ = cmd.MarkFlagRequired("session")
+	_ = cmd.MarkFlagRequired("base-url")
+	return cmd
+}
+
+func resultText(r replay.Result) string {
+	switch {
+	case r.Error != "":
+		return "error: " + r.Error
+	case !r.StatusMatch:
+		return "status differs"
+	case !r.ShapeMatch:
+		return "shape differs: " + strings.Join(r.ShapeDiffs, ", ")
+	default:
+		return "ok"
+	}
+}
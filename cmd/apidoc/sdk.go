@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yourorg/apidoc/internal/generator"
+	"github.com/yourorg/apidoc/internal/sdkgen"
+)
+
+func newSDKCmd(cfgPath *string) *cobra.Command {
+	var session, lang, outDir, pkg, baseURL string
+
+	cmd := &cobra.Command{
+		Use:   "sdk",
+		Short: "Generate a typed API client from a session's docs",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, s, err := openStore(*cfgPath)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			sess, err := s.GetSession(session)
+			if err != nil {
+				return fmt.Errorf("session not found: %w", err)
+			}
+			doc, err := generator.LoadDoc(s, sess, cfg)
+			if err != nil {
+				return err
+			}
+			if baseURL == "" {
+				logs, err := s.GetLogs(sess.ID)
+				if err != nil {
+					return err
+				}
+				if servers := generator.ServerURLs(logs, sess.Host); len(servers) > 0 {
+					baseURL = servers[0]
+				}
+			}
+			files, err := sdkgen.Generate(doc, sdkgen.Options{Lang: lang, Package: pkg, BaseURL: baseURL})
+			if err != nil {
+				return err
+			}
+
+			if outDir == "" {
+				outDir = filepath.Join(cfg.Output.Dir, "sdk", lang)
+			}
+			if err := os.MkdirAll(outDir, 0o755); err != nil {
+				return err
+			}
+			for _, f := range files {
+				path := filepath.Join(outDir, f.Name)
+				if err := os.WriteFile(path, f.Content, 0o644); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "wrote %s\n", path)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&session, "session", "", "session id to generate the client from")
+	cmd.Flags().StringVar(&lang, "lang", sdkgen.LangGo, "client language: go or typescript")
+	cmd.Flags().StringVar(&outDir, "out", "", "output directory (default <output.dir>/sdk/<lang>)")
+	cmd.Flags().StringVar(&pkg, "package", "apiclient", "Go package name of the client")
+	cmd.Flags().StringVar(&baseURL, "base-url", "", "default server URL (default: the most recorded server)")
+	_ = cmd.MarkFlagRequired("session")
+	return cmd
+}
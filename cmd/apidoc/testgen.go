@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yourorg/apidoc/internal/generator"
+	"github.com/yourorg/apidoc/internal/testgen"
+)
+
+func newTestgenCmd(cfgPath *string) *cobra.Command {
+	var session, outDir, pkg, baseURL string
+	var langs []string
+
+	cmd := &cobra.Command{
+		Use:   "testgen",
+		Short: "Generate contract tests that replay a session's call chain",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, s, err := openStore(*cfgPath)
+			if err != nil {
+				return err
+			}
+			defer s.Close()
+
+			sess, err := s.GetSession(session)
+			if err != nil {
+				return fmt.Errorf("session not found: %w", err)
+			}
+			logs, err := s.GetLogs(sess.ID)
+			if err != nil {
+				return err
+			}
+			doc, err := generator.LoadDoc(s, sess, cfg)
+			if err != nil {
+				return err
+			}
+			if baseURL == "" {
+				if servers := generator.ServerURLs(logs, sess.Host); len(servers) > 0 {
+					baseURL = servers[0]
+				}
+			}
+			files, err := testgen.Generate(doc, logs, testgen.Options{
+				Langs:    langs,
+				Package:  pkg,
+				BaseURL:  baseURL,
+				Filter:   cfg.Filter,
+				Sanitize: cfg.Sanitize,
+			})
+			if err != nil {
+				return err
+			}
+
+			if outDir == "" {
+				outDir = filepath.Join(cfg.Output.Dir, "tests")
+			}
+			if err := os.MkdirAll(outDir, 0o755); err != nil {
+				return err
+			}
+			for _, f := range files {
+				path := filepath.Join(outDir, f.Name)
+				if err := os.WriteFile(path, f.Content, 0o644); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "wrote %s\n", path)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&session, "session", "", "session id to generate tests from")
+	cmd.Flags().StringSliceVar(&langs, "lang", []string{testgen.LangGo}, "output languages: go, k6, hurl (comma-separated)")
+	cmd.Flags().StringVar(&outDir, "out", "", "output directory (default <output.dir>/tests)")
+	cmd.Flags().StringVar(&pkg, "package", "contract", "Go package name for generated tests")
+	cmd.Flags().StringVar(&baseURL, "base-url", "", "default target URL (default: the most recorded server)")
+	_ = cmd.MarkFlagRequired("session")
+	return cmd
+}
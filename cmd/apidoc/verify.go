@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/yourorg/apidoc/internal/config"
+	"github.com/yourorg/apidoc/internal/filter"
+	"github.com/yourorg/apidoc/internal/har"
+	"github.com/yourorg/apidoc/internal/verify"
+)
+
+func newVerifyCmd(cfgPath *string) *cobra.Command {
+	var specPath, harPath, reportPath string
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check captured traffic for conformance with an OpenAPI spec",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(*cfgPath)
+			if err != nil {
+				return err
+			}
+			spec, err := verify.LoadSpec(specPath)
+			if err != nil {
+				return err
+			}
+			logs, err := har.Parse(harPath)
+			if err != nil {
+				return fmt.Errorf("parse har: %w", err)
+			}
+			logs = filter.Apply(logs, cfg.Filter)
+
+			rep := verify.Check(spec, logs)
+			out := cmd.OutOrStdout()
+			for _, ep := range rep.Endpoints {
+				switch {
+				case ep.Undocumented:
+					fmt.Fprintf(out, "✗ %s %s (%d requests): not in spec\n", ep.Method, ep.Path, ep.Requests)
+				case len(ep.Violations) > 0:
+					fmt.Fprintf(out, "✗ %s %s (%d requests)\n", ep.Method, ep.Path, ep.Requests)
+					for _, v := range ep.Violations {
+						fmt.Fprintf(out, "    seq %d: %s\n", v.Seq, v.Message)
+					}
+				default:
+					fmt.Fprintf(out, "✓ %s %s (%d requests)\n", ep.Method, ep.Path, ep.Requests)
+				}
+			}
+			fmt.Fprintf(out, "checked %d requests, %d violations\n", rep.Checked, rep.Violations())
+
+			if reportPath != "" {
+				data, err := json.MarshalIndent(rep, "", "  ")
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(reportPath, data, 0o644); err != nil {
+					return err
+				}
+			}
+			if !rep.Passed() {
+				return fmt.Errorf("traffic does not conform to %s", specPath)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&specPath, "spec", "", "OpenAPI spec (YAML or JSON)")
+	cmd.Flags().StringVar(&harPath, "har", "", "HAR capture to check")
+	cmd.Flags().StringVar(&reportPath, "report", "", "write the report as JSON to this file")
+	_ = cmd.MarkFlagRequired("spec")
+	_ = cmd.MarkFlagRequired("har")
+	return cmd
+}
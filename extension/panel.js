@@ -85,8 +85,8 @@ chrome.devtools.network.onRequestFinished.addListener((request) => {
 
   requestQueue = requestQueue.then(() => {
     return new Promise((resolve) => {
-      request.getContent((body) => {
-        const entry = buildEntry(request, body);
+      request.getContent((body, encoding) => {
+        const entry = buildEntry(request, body, encoding);
         chrome.runtime.sendMessage({ type: 'APPEND_REQUEST', entry }, (resp) => {
           const seq = resp ? resp.count : 0;
           addRow(seq, entry);
@@ -120,7 +120,7 @@ function shouldIgnore(request) {
   return false;
 }
 
-function buildEntry(request, responseBody) {
+function buildEntry(request, responseBody, responseEncoding) {
   const url = new URL(request.request.url);
   const queryParams = {};
   url.searchParams.forEach((val, key) => {
@@ -128,29 +128,56 @@ function buildEntry(request, responseBody) {
     queryParams[key].push(val);
   });
 
-  const reqHeaders = {};
-  (request.request.headers || []).forEach(h => { reqHeaders[h.name] = h.value; });
-
-  const respHeaders = {};
-  (request.response.headers || []).forEach(h => { respHeaders[h.name] = h.value; });
+  const reqHeaders = collectHeaders(request.request.headers);
+  const respHeaders = collectHeaders(request.response.headers);
 
   return {
     method: request.request.method,
     url: request.request.url,
+    scheme: url.protocol.replace(':', ''),
     host: url.host,
     path: url.pathname,
+    http_version: request.response.httpVersion || request.request.httpVersion || '',
+    started_at: request.startedDateTime,
     query_params: queryParams,
     request_headers: reqHeaders,
+    request_cookies: collectCookies(request.request.cookies),
     request_body: request.request.postData ? request.request.postData.text || '' : '',
+    request_body_encoding: request.request.postData && request.request.postData.encoding === 'base64' ? 'base64' : 'plain',
     content_type: request.request.postData ? request.request.postData.mimeType || '' : '',
     status_code: request.response.status,
     response_headers: respHeaders,
+    response_cookies: collectCookies(request.response.cookies),
     response_body: responseBody || '',
+    response_body_encoding: responseEncoding === 'base64' ? 'base64' : 'plain',
     response_content_type: request.response.content.mimeType || '',
     latency_ms: Math.round(request.time || 0),
+    timings: request.timings || null,
   };
 }
 
+// Keep repeated headers (Set-Cookie, Vary) as arrays of values.
+function collectHeaders(headers) {
+  const out = {};
+  (headers || []).forEach(h => {
+    if (!out[h.name]) out[h.name] = [];
+    out[h.name].push(h.value);
+  });
+  return out;
+}
+
+function collectCookies(cookies) {
+  return (cookies || []).map(c => ({
+    name: c.name,
+    value: c.value,
+    path: c.path || '',
+    domain: c.domain || '',
+    expires: c.expires || '',
+    http_only: !!c.httpOnly,
+    secure: !!c.secure,
+  }));
+}
+
 function addRow(seq, entry) {
   const tr = document.createElement('tr');
   tr.dataset.path = entry.path.toLowerCase();
@@ -64,14 +64,10 @@ btnSendBackend.addEventListener('click', () => {
     btnSendBackend.textContent = '⏳ Sending...';
     try {
       const base = backendUrl.value.trim().replace(/\/$/, '');
-      // Step 1: send traffic
-      const trafficResp = await fetch(`${base}/api/traffic`, {
-        method: 'POST',
-        headers: { 'Content-Type': 'application/json' },
-        body: JSON.stringify({ scenario, logs: requests }),
+      // Step 1: send traffic in resumable chunks
+      const session_id = await uploadTraffic(base, scenario, requests, (sent, total) => {
+        btnSendBackend.textContent = `⏳ Uploading ${sent}/${total}...`;
       });
-      if (!trafficResp.ok) throw new Error(`Traffic upload failed: ${trafficResp.status}`);
-      const { session_id } = await trafficResp.json();
 
       // Step 2: trigger generation
       btnSendBackend.textContent = '⏳ Generating docs...';
@@ -92,28 +88,111 @@ btnSendBackend.addEventListener('click', () => {
   });
 });
 
+// Chunked upload: open → PUT chunks (gzip when supported) → commit.
+// Every step is idempotent on the server, so failed requests are retried.
+const CHUNK_BYTES = 4 * 1024 * 1024;
+const MAX_ATTEMPTS = 4;
+
+async function uploadTraffic(base, scenario, requests, onProgress) {
+  const uploadId = `ext-${Date.now()}-${Math.random().toString(36).slice(2, 10)}`;
+  await withRetry(() => sendJSON('POST', `${base}/api/uploads`, { upload_id: uploadId, scenario }));
+
+  const chunks = splitChunks(requests);
+  for (let i = 0; i < chunks.length; i++) {
+    await withRetry(() => sendJSON('PUT', `${base}/api/uploads/${uploadId}/chunks/${i}`, { logs: chunks[i] }));
+    onProgress(i + 1, chunks.length);
+  }
+  const result = await withRetry(() => sendJSON('POST', `${base}/api/uploads/${uploadId}/commit`, { chunks: chunks.length }));
+  return result.session_id;
+}
+
+function splitChunks(requests) {
+  const chunks = [];
+  let current = [];
+  let size = 0;
+  for (const r of requests) {
+    const n = JSON.stringify(r).length;
+    if (current.length > 0 && size + n > CHUNK_BYTES) {
+      chunks.push(current);
+      current = [];
+      size = 0;
+    }
+    current.push(r);
+    size += n;
+  }
+  if (current.length > 0) chunks.push(current);
+  return chunks;
+}
+
+async function sendJSON(method, url, payload) {
+  const headers = { 'Content-Type': 'application/json' };
+  let body = JSON.stringify(payload);
+  if (typeof CompressionStream !== 'undefined') {
+    const stream = new Blob([body]).stream().pipeThrough(new CompressionStream('gzip'));
+    body = await new Response(stream).blob();
+    headers['Content-Encoding'] = 'gzip';
+  }
+  const resp = await fetch(url, { method, headers, body });
+  if (!resp.ok) {
+    const err = new Error(`Upload failed: ${resp.status} ${await resp.text()}`);
+    err.retryable = resp.status >= 500 || resp.status === 429;
+    throw err;
+  }
+  return resp.json();
+}
+
+async function withRetry(fn) {
+  for (let attempt = 1; ; attempt++) {
+    try {
+      return await fn();
+    } catch (e) {
+      // Network errors have no retryable flag; HTTP 4xx are permanent.
+      if (attempt >= MAX_ATTEMPTS || e.retryable === false) throw e;
+      await new Promise((r) => setTimeout(r, 500 * 2 ** (attempt - 1)));
+    }
+  }
+}
+
 function buildHAR(requests) {
   return {
     log: {
       version: '1.2',
       creator: { name: 'API Doc Recorder', version: '1.0.0' },
       entries: requests.map((r) => ({
-        startedDateTime: new Date().toISOString(),
+        startedDateTime: r.started_at || new Date().toISOString(),
         time: r.latency_ms,
         request: {
           method: r.method,
           url: r.url,
-          headers: Object.entries(r.request_headers || {}).map(([name, value]) => ({ name, value })),
-          queryString: Object.entries(r.query_params || ).flatMap(([name, vals]) => vals.map(v => ({ name, value: v }))),
-          postData: r.request_body ? { mimeType: r.content_type || '', text: r.request_body } : undefined,
+          httpVersion: r.http_version || '',
+          headers: harHeaders(r.request_headers),
+          queryString: Object.entries(r.query_params || {}).flatMap(([name, vals]) => vals.map(v => ({ name, value: v }))),
+          postData: r.request_body ? {
+            mimeType: r.content_type || '',
+            text: r.request_body,
+            encoding: r.request_body_encoding === 'base64' ? 'base64' : undefined,
+          } : undefined,
         },
         response: {
           status: r.status_code,
           statusText: '',
-          headers: Object.entries(r.response_headers || {}).map(([name, value]) => ({ name, value })),
-          content: { size: (r.response_body || '').length, mimeType: r.response_content_type || '', text: r.response_body || '' },
+          httpVersion: r.http_version || '',
+          headers: harHeaders(r.response_headers),
+          content: {
+            size: (r.response_body || '').length,
+            mimeType: r.response_content_type || '',
+            text: r.response_body || '',
+            encoding: r.response_body_encoding === 'base64' ? 'base64' : undefined,
+          },
         },
+        timings: r.timings || { send: 0, wait: r.latency_ms || 0, receive: 0 },
       })),
     },
   };
 }
+
+// Headers are recorded as name → [values]; older captures used name → value.
+function harHeaders(headers) {
+  return Object.entries(headers || {}).flatMap(([name, value]) =>
+    (Array.isArray(value) ? value : [value]).map(v => ({ name, value: v })));
+}
@@ -20,11 +20,27 @@ type LLMConfig struct {
 	Model       string  `yaml:"model"`
 	MaxTokens   int     `yaml:"max_tokens"`
 	Temperature float64 `yaml:"temperature"`
+	// PromptDir holds prompt files overriding the built-in system.txt,
+	// user_example.txt and translate.tmpl.
+	PromptDir string `yaml:"prompt_dir"`
 }
 
 type OutputConfig struct {
 	Dir     string   `yaml:"dir"`
 	Formats []string `yaml:"formats"`
+	// OpenAPIVersion selects the OpenAPI flavour: "3.0" or "3.1".
+	OpenAPIVersion string `yaml:"openapi_version"`
+	// Diagrams lists the call chain diagram formats: mermaid, plantuml.
+	Diagrams []string `yaml:"diagrams"`
+	// MermaidURL is the Mermaid script the HTML site loads to draw
+	// diagrams. Empty keeps the site offline, showing diagram sources.
+	MermaidURL string `yaml:"mermaid_url"`
+	// TemplatesDir holds Markdown templates overriding the built-in
+	// readme.md.tmpl, api-docs.md.tmpl and endpoint.md.tmpl.
+	TemplatesDir string `yaml:"templates_dir"`
+	// Languages lists the doc languages (zh, en), each rendered into
+	// Dir/<lang>. Empty renders the generated zh doc into Dir itself.
+	Languages []string `yaml:"languages"`
 }
 
 type FilterConfig struct {
@@ -43,6 +59,18 @@ type ServerConfig struct {
 	Host            string `yaml:"host"`
 	Port            int    `yaml:"port"`
 	CORSExtensionID string `yaml:"cors_extension_id"`
+	// MaxBodyBytes caps each ingest request body, measured after gzip decoding.
+	MaxBodyBytes int64 `yaml:"max_body_bytes"`
+	// MaxUploadChunks and MaxUploadBytes cap one chunked upload: its chunk
+	// count and the decoded size of all its chunks.
+	MaxUploadChunks int   `yaml:"max_upload_chunks"`
+	MaxUploadBytes  int64 `yaml:"max_upload_bytes"`
+}
+
+type ProxyConfig struct {
+	Listen       string   `yaml:"listen"`
+	MaxBodyBytes int64    `yaml:"max_body_bytes"`
+	AllowHosts   []string `yaml:"allow_hosts"`
 }
 
 type LogConfig struct {
@@ -55,6 +83,7 @@ type Config struct {
 	Filter   FilterConfig   `yaml:"filter"`
 	Sanitize SanitizeConfig `yaml:"sanitize"`
 	Server   ServerConfig   `yaml:"server"`
+	Proxy    ProxyConfig    `yaml:"proxy"`
 	Log      LogConfig      `yaml:"log"`
 }
 
@@ -105,6 +134,12 @@ func (c *Config) SetDefaults() {
 	if len(c.Output.Formats) == 0 {
 		c.Output.Formats = []string{"markdown", "openapi"}
 	}
+	if c.Output.OpenAPIVersion == "" {
+		c.Output.OpenAPIVersion = "3.0"
+	}
+	if len(c.Output.Diagrams) == 0 {
+		c.Output.Diagrams = []string{"mermaid"}
+	}
 	if len(c.Filter.IgnoreExtensions) == 0 {
 		c.Filter.IgnoreExtensions = []string{".js", ".css", ".png", ".jpg", ".gif", ".svg", ".woff", ".woff2", ".ico", ".map"}
 	}
@@ -129,6 +164,21 @@ func (c *Config) SetDefaults() {
 	if c.Server.Port == 0 {
 		c.Server.Port = 3000
 	}
+	if c.Server.MaxBodyBytes == 0 {
+		c.Server.MaxBodyBytes = 32 << 20
+	}
+	if c.Server.MaxUploadChunks == 0 {
+		c.Server.MaxUploadChunks = 1000
+	}
+	if c.Server.MaxUploadBytes == 0 {
+		c.Server.MaxUploadBytes = 256 << 20
+	}
+	if c.Proxy.Listen == "" {
+		c.Proxy.Listen = "127.0.0.1:9000"
+	}
+	if c.Proxy.MaxBodyBytes == 0 {
+		c.Proxy.MaxBodyBytes = 1 << 20
+	}
 	if c.Log.Level == "" {
 		c.Log.Level = "info"
 	}
@@ -142,6 +192,31 @@ func (c *Config) Validate() error {
 	if err := ensureWritableDir(c.Output.Dir); err != nil {
 		return fmt.Errorf("output.dir not writable: %w", err)
 	}
+	if v := c.Output.OpenAPIVersion; v != "3.0" && v != "3.1" {
+		return fmt.Errorf("output.openapi_version must be 3.0 or 3.1, got %q", v)
+	}
+	for _, f := range c.Output.Formats {
+		switch f {
+		case "markdown", "openapi", "openapi-json", "postman", "html":
+		default:
+			return fmt.Errorf("output.formats: unknown format %q", f)
+		}
+	}
+	seen := map[string]bool{}
+	for _, l := range c.Output.Languages {
+		if l != "zh" && l != "en" {
+			return fmt.Errorf("output.languages: unsupported language %q", l)
+		}
+		if seen[l] {
+			return fmt.Errorf("output.languages: duplicate language %q", l)
+		}
+		seen[l] = true
+	}
+	for _, d := range c.Output.Diagrams {
+		if d != "mermaid" && d != "plantuml" {
+			return fmt.Errorf("output.diagrams: unknown diagram format %q", d)
+		}
+	}
 	return nil
 }
 
@@ -176,6 +251,7 @@ func applyEnvOverrides(c *Config) {
 	setString(&c.LLM.Model, "APIDOC_LLM_MODEL")
 	setInt(&c.LLM.MaxTokens, "APIDOC_LLM_MAX_TOKENS")
 	setFloat(&c.LLM.Temperature, "APIDOC_LLM_TEMPERATURE")
+	setString(&c.LLM.PromptDir, "APIDOC_LLM_PROMPT_DIR")
 	setString(&c.Output.Dir, "APIDOC_OUTPUT_DIR")
 	setString(&c.Server.Host, "APIDOC_SERVER_HOST")
 	setInt(&c.Server.Port, "APIDOC_SERVER_PORT")
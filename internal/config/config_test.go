@@ -48,6 +48,36 @@ func TestValidate(t *testing.T) {
 	if err := c.Validate(); err != nil {
 		t.Fatalf("validate failed: %v", err)
 	}
+	c.Output.OpenAPIVersion = "3.2"
+	if err := c.Validate(); err == nil {
+		t.Fatalf("expected openapi_version validation error")
+	}
+	c.Output.OpenAPIVersion = "3.1"
+	c.Output.Formats = []string{"openapi-json", "pdf"}
+	if err := c.Validate(); err == nil {
+		t.Fatalf("expected unknown format error")
+	}
+	c.Output.Formats = []string{"openapi-json", "postman"}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+	c.Output.Diagrams = []string{"mermaid", "graphviz"}
+	if err := c.Validate(); err == nil {
+		t.Fatalf("expected unknown diagram format error")
+	}
+	c.Output.Diagrams = []string{"mermaid", "plantuml"}
+	c.Output.Languages = []string{"zh", "fr"}
+	if err := c.Validate(); err == nil {
+		t.Fatalf("expected unsupported language error")
+	}
+	c.Output.Languages = []string{"en", "en"}
+	if err := c.Validate(); err == nil {
+		t.Fatalf("expected duplicate language error")
+	}
+	c.Output.Languages = []string{"zh", "en"}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
 	c.LLM.APIKey = ""
 	if err := c.ValidateGenerate(); err == nil {
 		t.Fatalf("expected generate validation error")
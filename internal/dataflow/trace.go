@@ -0,0 +1,247 @@
+// Package dataflow traces values that responses return and later requests
+// send back, such as IDs, tokens and cursors, to recover the dependencies
+// between calls from traffic alone.
+package dataflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// Where a consumed value appeared in the later request.
+const (
+	InPath   = "path"
+	InQuery  = "query"
+	InHeader = "header"
+	InCookie = "cookie"
+	InBody   = "body"
+)
+
+// Flow is one value passed from a response to a later request.
+type Flow struct {
+	FromSeq    int
+	FromMethod string
+	FromPath   string
+	// Field is the JSONPath of the value in the response body, e.g. $.data.id.
+	Field    string
+	ToSeq    int
+	ToMethod string
+	ToPath   string
+	In       string
+	// Name is the query, header or cookie name, or the JSONPath into the
+	// request body. For path values it is empty and Segment is set.
+	Name    string
+	Segment int
+	// Value is the value that was passed.
+	Value string
+}
+
+// String renders the flow as "POST /orders → $.id → GET /orders/ord_1 (path)".
+func (f Flow) String() string {
+	target := f.In
+	if f.Name != "" {
+		target += " " + f.Name
+	}
+	return fmt.Sprintf("#%d %s %s → %s → #%d %s %s (%s)", f.FromSeq, f.FromMethod, f.FromPath, f.Field, f.ToSeq, f.ToMethod, f.ToPath, target)
+}
+
+// maxValuesPerBody bounds the scalars indexed from one body.
+const maxValuesPerBody = 2000
+
+// ignoredHeaders carry transport or client details, never data from a
+// previous response.
+var ignoredHeaders = map[string]bool{
+	"host": true, "user-agent": true, "accept": true, "accept-encoding": true, "accept-language": true,
+	"content-type": true, "content-length": true, "connection": true, "cookie": true, "referer": true,
+	"origin": true, "cache-control": true, "pragma": true, "if-none-match": true, "if-modified-since": true,
+}
+
+type producer struct {
+	seq    int
+	method string
+	path   string
+	field  string
+}
+
+type value struct {
+	where, name string
+	segment     int
+	v           string
+}
+
+// Trace scans logs in seq order. Every distinctive scalar a response body
+// returns becomes a candidate; a later request that sends the same value in
+// its path, query, headers, cookies or body yields a Flow from the nearest
+// earlier response. Values the client had already sent are echoes, not
+// data flow, and are skipped.
+func Trace(logs []types.TrafficLog) []Flow {
+	ordered := append([]types.TrafficLog(nil), logs...)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Seq < ordered[j].Seq })
+
+	produced := map[string]producer{}
+	sent := map[string]bool{}
+	var flows []Flow
+	for _, l := range ordered {
+		method := strings.ToUpper(l.Method)
+		seen := map[string]bool{}
+		for _, rv := range requestValues(l) {
+			p, ok := produced[rv.v]
+			key := rv.where + "\x00" + rv.name + "\x00" + strconv.Itoa(rv.segment)
+			if ok && !seen[key] {
+				seen[key] = true
+				flows = append(flows, Flow{
+					FromSeq: p.seq, FromMethod: p.method, FromPath: p.path, Field: p.field,
+					ToSeq: l.Seq, ToMethod: method, ToPath: l.Path,
+					In: rv.where, Name: rv.name, Segment: rv.segment, Value: rv.v,
+				})
+			}
+			sent[rv.v] = true
+		}
+		for _, rv := range jsonValues(l.ResponseBody, InBody) {
+			if sent[rv.v] {
+				continue
+			}
+			produced[rv.v] = producer{seq: l.Seq, method: method, path: l.Path, field: rv.name}
+		}
+	}
+	return flows
+}
+
+// requestValues lists the distinctive values a request sent.
+func requestValues(l types.TrafficLog) []value {
+	var out []value
+	add := func(where, name string, segment int, v string) {
+		if Distinctive(v) {
+			out = append(out, value{where: where, name: name, segment: segment, v: v})
+		}
+	}
+	for i, seg := range strings.Split(strings.Trim(l.Path, "/"), "/") {
+		if s, err := url.PathUnescape(seg); err == nil {
+			seg = s
+		}
+		add(InPath, "", i, seg)
+	}
+	for _, name := range sortedKeys(l.QueryParams) {
+		for _, v := range l.QueryParams[name] {
+			add(InQuery, name, 0, v)
+		}
+	}
+	for _, name := range sortedKeys(l.RequestHeaders) {
+		if ignoredHeaders[strings.ToLower(name)] {
+			continue
+		}
+		for _, v := range l.RequestHeaders[name] {
+			// "Bearer <token>" and similar carry the value after the scheme.
+			if scheme, cred, ok := strings.Cut(v, " "); ok && !strings.ContainsAny(scheme, "=,;") {
+				v = strings.TrimSpace(cred)
+			}
+			add(InHeader, name, 0, v)
+		}
+	}
+	for _, c := range l.RequestCookies {
+		add(InCookie, c.Name, 0, c.Value)
+	}
+	for _, name := range sortedKeys(l.FormParams) {
+		for _, v := range l.FormParams[name] {
+			add(InBody, name, 0, v)
+		}
+	}
+	for _, v := range jsonValues(l.RequestBody, InBody) {
+		add(v.where, v.name, 0, v.v)
+	}
+	return out
+}
+
+// jsonValues flattens the distinctive scalars of a JSON body, named by
+// JSONPath.
+func jsonValues(body, where string) []value {
+	if strings.TrimSpace(body) == "" {
+		return nil
+	}
+	dec := json.NewDecoder(strings.NewReader(body))
+	dec.UseNumber()
+	var v interface{}
+	if dec.Decode(&v) != nil {
+		return nil
+	}
+	var out []value
+	var walk func(v interface{}, path string, depth int)
+	walk = func(v interface{}, path string, depth int) {
+		if depth > 8 || len(out) >= maxValuesPerBody {
+			return
+		}
+		switch t := v.(type) {
+		case map[string]interface{}:
+			for _, k := range sortedKeys(t) {
+				walk(t[k], path+jsonPathKey(k), depth+1)
+			}
+		case []interface{}:
+			for i, item := range t {
+				walk(item, fmt.Sprintf("%s[%d]", path, i), depth+1)
+			}
+		case string:
+			if Distinctive(t) {
+				out = append(out, value{where: where, name: path, v: t})
+			}
+		case json.Number:
+			if Distinctive(t.String()) {
+				out = append(out, value{where: where, name: path, v: t.String()})
+			}
+		}
+	}
+	walk(v, "$", 0)
+	return out
+}
+
+func jsonPathKey(k string) string {
+	for _, r := range k {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			quoted, _ := json.Marshal(k)
+			return "[" + strings.ReplaceAll(string(quoted), `"`, "'") + "]"
+		}
+	}
+	return "." + k
+}
+
+// Distinctive reports whether v is specific enough that seeing it twice is
+// unlikely to be chance: numbers of three or more digits, strings mixing
+// letters and digits, and longer letter strings with separators such as
+// slugs or emails. Plain words like "active" are not.
+func Distinctive(v string) bool {
+	if len(v) > 512 || strings.ContainsAny(v, " \t\n") {
+		return false
+	}
+	digits, letters := 0, 0
+	for _, r := range v {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+			letters++
+		}
+	}
+	switch {
+	case digits == len(v):
+		return len(v) >= 3
+	case digits > 0:
+		return len(v) >= 4
+	case letters == len(v):
+		return len(v) >= 16
+	}
+	return len(v) >= 8
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,68 @@
+package dataflow
+
+import (
+	"testing"
+
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+func TestTrace(t *testing.T) {
+	logs := []types.TrafficLog{
+		{Seq: 3, Method: "GET", Path: "/orders/ord_81", QueryParams: map[string][]string{"cursor": {"c-20240501"}},
+			RequestHeaders: map[string][]string{"Authorization": {"Bearer tk_9f8e7d6c5b4a"}}},
+		{Seq: 1, Method: "POST", Path: "/login", RequestBody: `{"user":"alice"}`,
+			ResponseBody: `{"token":"tk_9f8e7d6c5b4a","status":"active"}`},
+		{Seq: 2, Method: "POST", Path: "/orders", RequestBody: `{"sku":"SKU-1001","note":"ok"}`,
+			ResponseBody: `{"data":{"order_id":"ord_81","sku":"SKU-1001","next":"c-20240501","items":[{"x-id":12345}]}}`},
+		{Seq: 4, Method: "POST", Path: "/payments", RequestBody: `{"ref":{"item":12345},"state":"active"}`},
+	}
+	flows := Trace(logs)
+	want := []Flow{
+		{FromSeq: 2, FromMethod: "POST", FromPath: "/orders", Field: "$.data.order_id", ToSeq: 3, ToMethod: "GET", ToPath: "/orders/ord_81", In: InPath, Segment: 1, Value: "ord_81"},
+		{FromSeq: 2, FromMethod: "POST", FromPath: "/orders", Field: "$.data.next", ToSeq: 3, ToMethod: "GET", ToPath: "/orders/ord_81", In: InQuery, Name: "cursor", Value: "c-20240501"},
+		{FromSeq: 1, FromMethod: "POST", FromPath: "/login", Field: "$.token", ToSeq: 3, ToMethod: "GET", ToPath: "/orders/ord_81", In: InHeader, Name: "Authorization", Value: "tk_9f8e7d6c5b4a"},
+		{FromSeq: 2, FromMethod: "POST", FromPath: "/orders", Field: "$.data.items[0]['x-id']", ToSeq: 4, ToMethod: "POST", ToPath: "/payments", In: InBody, Name: "$.ref.item", Value: "12345"},
+	}
+	if len(flows) != len(want) {
+		t.Fatalf("got %d flows, want %d:\n%v", len(flows), len(want), flows)
+	}
+	for i := range want {
+		if flows[i] != want[i] {
+			t.Fatalf("flow %d = %+v, want %+v", i, flows[i], want[i])
+		}
+	}
+}
+
+func TestTraceSkipsEchoes(t *testing.T) {
+	logs := []types.TrafficLog{
+		{Seq: 1, Method: "POST", Path: "/items", RequestBody: `{"sku":"SKU-1001"}`, ResponseBody: `{"sku":"SKU-1001"}`},
+		{Seq: 2, Method: "GET", Path: "/items/SKU-1001"},
+	}
+	if flows := Trace(logs); len(flows) != 0 {
+		t.Fatalf("echoed values should not flow: %v", flows)
+	}
+}
+
+func TestDistinctive(t *testing.T) {
+	for v, want := range map[string]bool{
+		"12":                false,
+		"123":               true,
+		"a1b":               false,
+		"ord_81":            true,
+		"active":            false,
+		"abcdefghijklmnopq": true,
+		"a-b-c-d-e":         true,
+		"two words 123":     false,
+	} {
+		if got := Distinctive(v); got != want {
+			t.Errorf("Distinctive(%q) = %v, want %v", v, got, want)
+		}
+	}
+}
+
+func TestFlowString(t *testing.T) {
+	f := Flow{FromSeq: 1, FromMethod: "POST", FromPath: "/orders", Field: "$.id", ToSeq: 2, ToMethod: "GET", ToPath: "/orders/o_1", In: InPath}
+	if got, want := f.String(), "#1 POST /orders → $.id → #2 GET /orders/o_1 (path)"; got != want {
+		t.Fatalf("String() = %q, want %q", got, want)
+	}
+}
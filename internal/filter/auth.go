@@ -0,0 +1,151 @@
+package filter
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// apiKeyQueryParams are recognised as credentials in addition to the
+// configured sensitive body fields.
+var apiKeyQueryParams = map[string]struct{}{"apikey": {}, "api-key": {}, "api_key": {}}
+
+// sessionCookies are common framework session cookie names.
+var sessionCookies = map[string]struct{}{
+	"session": {}, "sessionid": {}, "session_id": {}, "sid": {}, "connect.sid": {},
+	"phpsessid": {}, "jsessionid": {}, "asp.net_sessionid": {}, "laravel_session": {}, "_session_id": {},
+}
+
+// DetectAuth reports the credentials a request carried. It must run before
+// Sanitize, which redacts the values the detection inspects. Headers listed
+// in cfg.Headers and query params listed in cfg.BodyFields count as API keys.
+func DetectAuth(l types.TrafficLog, cfg SanitizeConfig) []types.AuthScheme {
+	headerSet := toLowerSet(cfg.Headers)
+	fieldSet := toLowerSet(cfg.BodyFields)
+	seen := map[string]types.AuthScheme{}
+	add := func(a types.AuthScheme) { seen[a.ID()] = a }
+
+	for name, vs := range l.RequestHeaders {
+		lower := strings.ToLower(name)
+		if len(vs) == 0 || vs[0] == "" {
+			continue
+		}
+		switch {
+		case lower == "authorization" || lower == "proxy-authorization":
+			add(authorizationScheme(name, vs[0]))
+		case lower == "cookie" || lower == "set-cookie":
+			// Cookies are classified by name below.
+		case isAPIKeyHeader(lower):
+			add(types.AuthScheme{Type: types.AuthAPIKey, In: "header", Name: name})
+		default:
+			if _, ok := headerSet[lower]; ok {
+				add(types.AuthScheme{Type: types.AuthAPIKey, In: "header", Name: name})
+			}
+		}
+	}
+
+	for name, vs := range l.QueryParams {
+		lower := strings.ToLower(name)
+		if len(vs) == 0 || vs[0] == "" {
+			continue
+		}
+		_, known := apiKeyQueryParams[lower]
+		_, sensitive := fieldSet[lower]
+		if known || sensitive {
+			add(types.AuthScheme{Type: types.AuthAPIKey, In: "query", Name: name})
+		}
+	}
+
+	for _, c := range requestCookies(l) {
+		if a, ok := cookieScheme(c.Name); ok {
+			add(a)
+		}
+	}
+
+	out := make([]types.AuthScheme, 0, len(seen))
+	for _, a := range seen {
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID() < out[j].ID() })
+	return out
+}
+
+func authorizationScheme(header, value string) types.AuthScheme {
+	scheme, cred, _ := strings.Cut(strings.TrimSpace(value), " ")
+	switch strings.ToLower(scheme) {
+	case "bearer":
+		a := types.AuthScheme{Type: types.AuthBearer}
+		if isJWT(strings.TrimSpace(cred)) {
+			a.Format = "JWT"
+		}
+		return a
+	case "basic":
+		return types.AuthScheme{Type: types.AuthBasic}
+	}
+	// Custom schemes ("Token abc", a raw key) behave like an API key header.
+	return types.AuthScheme{Type: types.AuthAPIKey, In: "header", Name: header}
+}
+
+func isAPIKeyHeader(lower string) bool {
+	lower = strings.TrimPrefix(lower, "x-")
+	switch lower {
+	case "api-key", "apikey", "api_key", "auth-token", "access-token", "token":
+		return true
+	}
+	return false
+}
+
+// isJWT reports whether s is three base64url segments whose header decodes
+// to a JSON object with an "alg".
+func isJWT(s string) bool {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return false
+	}
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[0], "="))
+	if err != nil {
+		return false
+	}
+	var header map[string]any
+	if err := json.Unmarshal(data, &header); err != nil {
+		return false
+	}
+	_, ok := header["alg"]
+	return ok
+}
+
+func cookieScheme(name string) (types.AuthScheme, bool) {
+	lower := strings.ToLower(name)
+	if _, ok := sessionCookies[lower]; ok || strings.HasSuffix(lower, "_session") || strings.HasSuffix(lower, "sessionid") {
+		return types.AuthScheme{Type: types.AuthSessionCookie, In: "cookie", Name: name}, true
+	}
+	for _, marker := range []string{"token", "jwt", "auth", "api_key", "apikey"} {
+		if strings.Contains(lower, marker) && !strings.Contains(lower, "csrf") && !strings.Contains(lower, "xsrf") {
+			return types.AuthScheme{Type: types.AuthAPIKey, In: "cookie", Name: name}, true
+		}
+	}
+	return types.AuthScheme{}, false
+}
+
+// requestCookies merges recorded cookies with those only present in a
+// Cookie header.
+func requestCookies(l types.TrafficLog) []types.Cookie {
+	out := append([]types.Cookie(nil), l.RequestCookies...)
+	have := map[string]bool{}
+	for _, c := range out {
+		have[c.Name] = true
+	}
+	for _, v := range l.RequestHeaders.Values("Cookie") {
+		for _, c := range (&http.Request{Header: http.Header{"Cookie": {v}}}).Cookies() {
+			if !have[c.Name] {
+				have[c.Name] = true
+				out = append(out, types.Cookie{Name: c.Name, Value: c.Value})
+			}
+		}
+	}
+	return out
+}
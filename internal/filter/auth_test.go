@@ -0,0 +1,56 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/yourorg/apidoc/internal/config"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+func authIDs(schemes []types.AuthScheme) []string {
+	ids := make([]string, len(schemes))
+	for i, a := range schemes {
+		ids[i] = a.ID()
+	}
+	return ids
+}
+
+func TestDetectAuth(t *testing.T) {
+	cfg := config.Config{}
+	cfg.SetDefaults()
+	const jwt = "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.eyJzdWIiOiIxIn0.c2ln"
+
+	cases := []struct {
+		name string
+		log  types.TrafficLog
+		want []string
+	}{
+		{"jwt bearer", types.TrafficLog{RequestHeaders: types.Headers{"Authorization": {"Bearer " + jwt}}}, []string{"bearerJWT"}},
+		{"opaque bearer", types.TrafficLog{RequestHeaders: types.Headers{"authorization": {"Bearer abc123"}}}, []string{"bearerAuth"}},
+		{"basic", types.TrafficLog{RequestHeaders: types.Headers{"Authorization": {"Basic dTpw"}}}, []string{"basicAuth"}},
+		{"custom scheme", types.TrafficLog{RequestHeaders: types.Headers{"Authorization": {"Token abc"}}}, []string{"apiKey_header_Authorization"}},
+		{"api key header", types.TrafficLog{RequestHeaders: types.Headers{"X-Api-Key": {"k"}, "Accept": {"*/*"}}}, []string{"apiKey_header_X-Api-Key"}},
+		{"configured header", types.TrafficLog{RequestHeaders: types.Headers{"X-Auth-Token": {"k"}}}, []string{"apiKey_header_X-Auth-Token"}},
+		{"query key", types.TrafficLog{QueryParams: map[string][]string{"api_key": {"k"}, "page": {"1"}}}, []string{"apiKey_query_api_key"}},
+		{"session cookie header", types.TrafficLog{RequestHeaders: types.Headers{"Cookie": {"theme=dark; connect.sid=s%3Aabc"}}}, []string{"sessionCookie_connect.sid"}},
+		{"cookie token and csrf", types.TrafficLog{RequestCookies: []types.Cookie{{Name: "auth_token", Value: "x"}, {Name: "csrf_token", Value: "y"}}}, []string{"apiKey_cookie_auth_token"}},
+		{"combined", types.TrafficLog{
+			RequestHeaders: types.Headers{"Authorization": {"Bearer t"}},
+			RequestCookies: []types.Cookie{{Name: "PHPSESSID", Value: "x"}},
+		}, []string{"bearerAuth", "sessionCookie_PHPSESSID"}},
+		{"anonymous", types.TrafficLog{RequestHeaders: types.Headers{"Accept": {"*/*"}}}, []string{}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := authIDs(DetectAuth(tc.log, cfg.Sanitize))
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("got %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
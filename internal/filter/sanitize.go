@@ -21,6 +21,9 @@ func Sanitize(logs []types.TrafficLog, cfg SanitizeConfig) []types.TrafficLog {
 		out[i] = l
 		out[i].RequestHeaders = sanitizeHeaderMap(l.RequestHeaders, headerSet, replacement)
 		out[i].ResponseHeaders = sanitizeHeaderMap(l.ResponseHeaders, headerSet, replacement)
+		out[i].RequestCookies = sanitizeCookies(l.RequestCookies, headerSet, "cookie", replacement)
+		out[i].ResponseCookies = sanitizeCookies(l.ResponseCookies, headerSet, "set-cookie", replacement)
+		out[i].FormParams = sanitizeQueryParams(l.FormParams, fieldSet, replacement)
 		out[i].QueryParams = sanitizeQueryParams(l.QueryParams, fieldSet, replacement)
 		out[i].RequestBody = sanitizeBody(l.RequestBody, fieldSet, replacement)
 	}
@@ -39,17 +42,37 @@ func toLowerSet(items []string) map[string]struct{} {
 	return set
 }
 
-func sanitizeHeaderMap(in map[string]string, set map[string]struct{}, replacement string) map[string]string {
+func sanitizeHeaderMap(in types.Headers, set map[string]struct{}, replacement string) types.Headers {
 	if len(in) == 0 {
 		return in
 	}
-	out := make(map[string]string, len(in))
-	for k, v := range in {
+	out := make(types.Headers, len(in))
+	for k, vs := range in {
 		if _, ok := set[strings.ToLower(k)]; ok {
-			out[k] = replacement
+			repl := make([]string, len(vs))
+			for i := range repl {
+				repl[i] = replacement
+			}
+			out[k] = repl
 			continue
 		}
-		out[k] = v
+		out[k] = append([]string(nil), vs...)
+	}
+	return out
+}
+
+// sanitizeCookies keeps cookie names (useful for documenting cookie auth)
+// but redacts values when the carrying header is marked sensitive.
+func sanitizeCookies(in []types.Cookie, set map[string]struct{}, header, replacement string) []types.Cookie {
+	if len(in) == 0 {
+		return in
+	}
+	out := append([]types.Cookie(nil), in...)
+	if _, ok := set[header]; !ok {
+		return out
+	}
+	for i := range out {
+		out[i].Value = replacement
 	}
 	return out
 }
@@ -74,6 +97,12 @@ func sanitizeQueryParams(in map[string][]string, set map[string]struct{}, replac
 	return out
 }
 
+// SanitizeBody redacts the configured sensitive fields in a JSON body.
+// Sanitize leaves response bodies alone; callers that publish them use this.
+func SanitizeBody(body string, cfg SanitizeConfig) string {
+	return sanitizeBody(body, toLowerSet(cfg.BodyFields), cfg.Replacement)
+}
+
 func sanitizeBody(body string, set map[string]struct{}, replacement string) string {
 	if strings.TrimSpace(body) == "" {
 		return body
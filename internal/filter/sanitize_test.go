@@ -15,25 +15,32 @@ func TestSanitizeHeadersAndQuery(t *testing.T) {
 	}
 	logs := []types.TrafficLog{
 		{
-			RequestHeaders:  map[string]string{"authorization": "Bearer abc", "X-API-Key": "k", "Accept": "application/json"},
-			ResponseHeaders: map[string]string{"Set-Cookie": "secret=1", "Content-Type": "application/json"},
+			RequestHeaders:  types.Headers{"authorization": {"Bearer abc"}, "X-API-Key": {"k"}, "Accept": {"application/json"}},
+			ResponseHeaders: types.Headers{"Set-Cookie": {"secret=1", "other=2"}, "Content-Type": {"application/json"}},
+			ResponseCookies: []types.Cookie{{Name: "secret", Value: "1"}},
 			QueryParams:     map[string][]string{"token": {"abc", "def"}, "q": {"ok"}},
 		},
 	}
 
 	out := Sanitize(logs, cfg)
 	got := out[0]
-	if got.RequestHeaders["authorization"] != cfg.Replacement {
+	if got.RequestHeaders.Get("authorization") != cfg.Replacement {
 		t.Fatalf("expected authorization redacted")
 	}
-	if got.RequestHeaders["X-API-Key"] != cfg.Replacement {
+	if got.RequestHeaders.Get("X-API-Key") != cfg.Replacement {
 		t.Fatalf("expected x-api-key redacted")
 	}
-	if got.RequestHeaders["Accept"] != "application/json" {
+	if got.RequestHeaders.Get("Accept") != "application/json" {
 		t.Fatalf("expected accept unchanged")
 	}
-	if got.ResponseHeaders["Set-Cookie"] != cfg.Replacement {
-		t.Fatalf("expected set-cookie redacted")
+	if cookies := got.ResponseHeaders.Values("Set-Cookie"); len(cookies) != 2 || cookies[0] != cfg.Replacement || cookies[1] != cfg.Replacement {
+		t.Fatalf("expected every set-cookie redacted, got %v", cookies)
+	}
+	if got.ResponseCookies[0].Name != "secret" || got.ResponseCookies[0].Value != cfg.Replacement {
+		t.Fatalf("expected cookie value redacted and name kept, got %+v", got.ResponseCookies[0])
+	}
+	if logs[0].ResponseCookies[0].Value != "1" {
+		t.Fatalf("expected input cookies untouched")
 	}
 	if got.QueryParams["token"][0] != cfg.Replacement || got.QueryParams["token"][1] != cfg.Replacement {
 		t.Fatalf("expected token query params redacted")
@@ -0,0 +1,62 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/yourorg/apidoc/internal/filter"
+	"github.com/yourorg/apidoc/internal/pathmatch"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// AttachAuth records on each endpoint the auth schemes its requests carried.
+// logs must not be sanitized yet.
+func AttachAuth(doc *types.GeneratedDoc, logs []types.TrafficLog, cfg filter.SanitizeConfig) {
+	if doc == nil {
+		return
+	}
+	seen := make([]map[string]bool, len(doc.Endpoints))
+	for i := range doc.Endpoints {
+		doc.Endpoints[i].Auth = nil
+		seen[i] = map[string]bool{}
+	}
+	for _, l := range logs {
+		i, ok := endpointIndex(doc.Endpoints, l)
+		if !ok {
+			continue
+		}
+		schemes := filter.DetectAuth(l, cfg)
+		ids := make([]string, len(schemes))
+		for j, a := range schemes {
+			ids[j] = a.ID()
+		}
+		key := strings.Join(ids, "+")
+		if seen[i][key] {
+			continue
+		}
+		seen[i][key] = true
+		doc.Endpoints[i].Auth = append(doc.Endpoints[i].Auth, schemes)
+	}
+	// An endpoint only ever called anonymously needs no security section.
+	for i := range doc.Endpoints {
+		if a := doc.Endpoints[i].Auth; len(a) == 1 && len(a[0]) == 0 {
+			doc.Endpoints[i].Auth = nil
+		}
+	}
+}
+
+// endpointIndex matches a log to the endpoint whose path fits it most
+// specifically.
+func endpointIndex(endpoints []types.Endpoint, l types.TrafficLog) (int, bool) {
+	var templates []string
+	var indexes []int
+	for i, ep := range endpoints {
+		if strings.EqualFold(ep.Method, l.Method) {
+			templates = append(templates, ep.Path)
+			indexes = append(indexes, i)
+		}
+	}
+	if i := pathmatch.Best(templates, l.Path); i >= 0 {
+		return indexes[i], true
+	}
+	return -1, false
+}
@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/yourorg/apidoc/internal/config"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+func TestAttachAuthAndRender(t *testing.T) {
+	doc := &types.GeneratedDoc{Scenario: "auth", Endpoints: []types.Endpoint{
+		{Method: "GET", Path: "/users/{id}", Responses: []types.Response{{StatusCode: 200}}},
+		{Method: "GET", Path: "/users/me", Responses: []types.Response{{StatusCode: 200}}},
+		{Method: "GET", Path: "/health", Responses: []types.Response{{StatusCode: 200}}},
+	}}
+	logs := []types.TrafficLog{
+		{Method: "GET", Path: "/users/1", RequestHeaders: types.Headers{"Authorization": {"Bearer a"}}},
+		{Method: "GET", Path: "/users/2", RequestHeaders: types.Headers{"Authorization": {"Bearer b"}}},
+		{Method: "GET", Path: "/users/3", QueryParams: map[string][]string{"api_key": {"k"}}},
+		{Method: "GET", Path: "/users/me", RequestCookies: []types.Cookie{{Name: "sessionid", Value: "s"}}},
+		{Method: "GET", Path: "/users/me"},
+		{Method: "GET", Path: "/health"},
+	}
+	cfg := config.Config{}
+	cfg.SetDefaults()
+	AttachAuth(doc, logs, cfg.Sanitize)
+
+	if got := len(doc.Endpoints[0].Auth); got != 2 {
+		t.Fatalf("/users/{id} alternatives = %d, want 2: %+v", got, doc.Endpoints[0].Auth)
+	}
+	if me := doc.Endpoints[1].Auth; len(me) != 2 || len(me[1]) != 0 {
+		t.Fatalf("/users/me should allow anonymous calls: %+v", me)
+	}
+	if doc.Endpoints[2].Auth != nil {
+		t.Fatalf("/health should have no auth: %+v", doc.Endpoints[2].Auth)
+	}
+
+	outDir := t.TempDir()
+	if err := RenderOpenAPI(doc, outDir); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(outDir, "openapi.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec struct {
+		Components struct {
+			SecuritySchemes map[string]map[string]string `yaml:"securitySchemes"`
+		} `yaml:"components"`
+		Paths map[string]map[string]struct {
+			Security []map[string][]string `yaml:"security"`
+		} `yaml:"paths"`
+	}
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		t.Fatal(err)
+	}
+	schemes := spec.Components.SecuritySchemes
+	if schemes["bearerAuth"]["scheme"] != "bearer" || schemes["apiKey_query_api_key"]["in"] != "query" || schemes["sessionCookie_sessionid"]["in"] != "cookie" {
+		t.Fatalf("securitySchemes = %v", schemes)
+	}
+	if sec := spec.Paths["/users/me"]["get"].Security; len(sec) != 2 || len(sec[1]) != 0 {
+		t.Fatalf("/users/me security = %v", sec)
+	}
+	if sec := spec.Paths["/health"]["get"].Security; sec != nil {
+		t.Fatalf("/health security = %v", sec)
+	}
+
+	if err := RenderMarkdown(doc, outDir); err != nil {
+		t.Fatal(err)
+	}
+	md, err := os.ReadFile(filepath.Join(outDir, "api-docs.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(md), "### Authentication\n- Session cookie `sessionid`\n- or no authentication\n") {
+		t.Fatalf("api-docs.md:\n%s", md)
+	}
+}
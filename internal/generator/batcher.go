@@ -117,6 +117,26 @@ func estimateTokensForLogs(logs []types.TrafficLog) int {
 	return EstimateTokens(string(b))
 }
 
+// StaleBatchKeys returns the batch keys whose cached LLM output may no longer
+// match once logs are added to a session: every path prefix touched, plus
+// "mixed" since multi-prefix batches can absorb any of them.
+func StaleBatchKeys(logs []types.TrafficLog) []string {
+	if len(logs) == 0 {
+		return nil
+	}
+	keys := []string{"mixed"}
+	seen := map[string]struct{}{"mixed": {}}
+	for _, l := range logs {
+		k := pathPrefix(l.Path)
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
 func pathPrefix(p string) string {
 	parts := strings.Split(strings.Trim(p, "/"), "/")
 	if len(parts) == 0 || parts[0] == "" {
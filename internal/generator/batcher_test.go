@@ -38,6 +38,26 @@ func TestSplitBatchesByPrefix(t *testing.T) {
 	}
 }
 
+func TestStaleBatchKeys(t *testing.T) {
+	keys := StaleBatchKeys([]types.TrafficLog{
+		{Path: "/api/v1/users/1"},
+		{Path: "/api/v1/users/2"},
+		{Path: "/auth/login"},
+	})
+	want := []string{"mixed", "/api/v1/users", "/auth/login"}
+	if len(keys) != len(want) {
+		t.Fatalf("keys = %v, want %v", keys, want)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Fatalf("keys = %v, want %v", keys, want)
+		}
+	}
+	if StaleBatchKeys(nil) != nil {
+		t.Fatal("expected no keys for no logs")
+	}
+}
+
 func TestMergeDocs(t *testing.T) {
 	doc1 := &types.GeneratedDoc{Scenario: "s", CallChain: []types.ChainStep{{Seq: 1}}, Endpoints: []types.Endpoint{{Method: "GET", Path: "/a"}}}
 	doc2 := &types.GeneratedDoc{Scenario: "s", CallChain: []types.ChainStep{{Seq: 1}, {Seq: 2}}, Endpoints: []types.Endpoint{{Method: "GET", Path: "/a"}, {Method: "POST", Path: "/b"}}}
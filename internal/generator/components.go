@@ -0,0 +1,263 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yourorg/apidoc/internal/pathmatch"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// schemaRegistry hoists object structures that occur more than once across
+// a doc into components/schemas and hands out $refs for them.
+type schemaRegistry struct {
+	names   map[string]string // structural signature → component name
+	schemas map[string]interface{}
+}
+
+// objectSite is one place an object structure occurs, with the name it
+// would get if hoisted.
+type objectSite struct {
+	fields    []types.Param
+	suggested string // LLM-provided schema_name
+	derived   string
+	rank      int // how well derived describes the object; higher wins
+}
+
+// Derived name ranks. A body returned by /users/{id} is best called User;
+// a field name such as "owner" says more than "items" or a list endpoint.
+const (
+	rankBody = iota
+	rankGenericField
+	rankField
+	rankItemResource
+)
+
+var genericFieldNames = map[string]bool{
+	"item": true, "items": true, "data": true, "list": true, "result": true,
+	"results": true, "records": true, "rows": true, "entries": true, "content": true,
+}
+
+func newSchemaRegistry(doc *types.GeneratedDoc) *schemaRegistry {
+	var sites []objectSite
+	var walk func(params []types.Param)
+	walk = func(params []types.Param) {
+		for _, p := range params {
+			if len(p.Children) == 0 {
+				continue
+			}
+			typeName, _ := inferType(p.Type)
+			name := pascalCase(p.Name)
+			if typeName == "array" {
+				name = pascalCase(singular(p.Name))
+			}
+			rank := rankField
+			if genericFieldNames[strings.ToLower(p.Name)] {
+				rank = rankGenericField
+			}
+			sites = append(sites, objectSite{fields: p.Children, suggested: p.SchemaName, derived: name, rank: rank})
+			walk(p.Children)
+		}
+	}
+	for _, ep := range doc.Endpoints {
+		resource := resourceName(ep.Path)
+		if ep.RequestBody != nil && len(ep.RequestBody.Fields) > 0 {
+			sites = append(sites, objectSite{fields: ep.RequestBody.Fields, derived: pascalCase(resource.plural) + "Request", rank: rankBody})
+			walk(ep.RequestBody.Fields)
+		}
+		for _, resp := range ep.Responses {
+			if len(resp.Fields) == 0 {
+				continue
+			}
+			site := objectSite{fields: resp.Fields, derived: pascalCase(resource.plural) + "Response", rank: rankBody}
+			if resource.item {
+				site.derived, site.rank = pascalCase(singular(resource.plural)), rankItemResource
+			}
+			sites = append(sites, site)
+			walk(resp.Fields)
+		}
+	}
+
+	type group struct {
+		sig   string
+		first int
+		sites []objectSite
+	}
+	groups := map[string]*group{}
+	for i, s := range sites {
+		sig := signature(s.fields)
+		g, ok := groups[sig]
+		if !ok {
+			g = &group{sig: sig, first: i}
+			groups[sig] = g
+		}
+		g.sites = append(g.sites, s)
+	}
+	shared := make([]*group, 0, len(groups))
+	for _, g := range groups {
+		if len(g.sites) > 1 {
+			shared = append(shared, g)
+		}
+	}
+	// Name in document order so names are stable between runs. Groups with
+	// an LLM-suggested name claim it first.
+	sort.Slice(shared, func(i, j int) bool { return shared[i].first < shared[j].first })
+	sort.SliceStable(shared, func(i, j int) bool {
+		return suggestedName(shared[i].sites) != "" && suggestedName(shared[j].sites) == ""
+	})
+
+	r := &schemaRegistry{names: map[string]string{}, schemas: map[string]interface{}{}}
+	taken := map[string]bool{}
+	for _, g := range shared {
+		name := types.ComponentName(suggestedName(g.sites))
+		if name == "" {
+			best := -1
+			for _, s := range g.sites {
+				if n := types.ComponentName(s.derived); n != "" && s.rank > best {
+					name, best = n, s.rank
+				}
+			}
+		}
+		if name == "" {
+			name = "Object"
+		}
+		base := name
+		for n := 2; taken[name]; n++ {
+			name = fmt.Sprintf("%s%d", base, n)
+		}
+		taken[name] = true
+		r.names[g.sig] = name
+	}
+	for _, g := range shared {
+		r.schemas[r.names[g.sig]] = r.inlineObject(g.sites[0].fields)
+	}
+	return r
+}
+
+// object returns a $ref for hoisted structures and an inline schema otherwise.
+func (r *schemaRegistry) object(fields []types.Param) map[string]interface{} {
+	if name, ok := r.names[signature(fields)]; ok {
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	}
+	return r.inlineObject(fields)
+}
+
+func (r *schemaRegistry) inlineObject(fields []types.Param) map[string]interface{} {
+	props := map[string]interface{}{}
+	var required []string
+	for _, f := range fields {
+		props[f.Name] = r.param(f)
+		if f.Required {
+			required = append(required, f.Name)
+		}
+	}
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": props,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// param mirrors paramToSchema but refers to hoisted objects.
+func (r *schemaRegistry) param(p types.Param) map[string]interface{} {
+	if len(p.Children) == 0 {
+		return paramToSchema(p)
+	}
+	typeName, _ := inferType(p.Type)
+	if typeName == "array" {
+		schema := map[string]interface{}{"type": "array", "items": r.object(p.Children)}
+		if p.Description != "" {
+			schema["description"] = p.Description
+		}
+		return schema
+	}
+	obj := r.object(p.Children)
+	if _, isRef := obj["$ref"]; isRef && p.Description != "" {
+		// $ref siblings are ignored in OpenAPI 3.0; allOf keeps the description.
+		return map[string]interface{}{"allOf": []interface{}{obj}, "description": p.Description}
+	}
+	if p.Description != "" {
+		obj["description"] = p.Description
+	}
+	return obj
+}
+
+// signature identifies an object structure by field names, types,
+// requiredness and nested structure, ignoring order and descriptions.
+func signature(fields []types.Param) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		typeName, format := inferType(f.Type)
+		if len(f.Children) > 0 && typeName != "array" {
+			typeName = "object"
+		}
+		parts[i] = fmt.Sprintf("%s:%s:%s:%t{%s}", f.Name, typeName, format, f.Required, signature(f.Children))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func suggestedName(sites []objectSite) string {
+	for _, s := range sites {
+		if s.suggested != "" {
+			return s.suggested
+		}
+	}
+	return ""
+}
+
+type resource struct {
+	plural string
+	item   bool // path ends in a parameter, e.g. /users/{id}
+}
+
+// resourceName takes the last literal path segment.
+func resourceName(path string) resource {
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+	res := resource{}
+	for i := len(segs) - 1; i >= 0; i-- {
+		if pathmatch.IsTemplate("/" + segs[i]) {
+			if i == len(segs)-1 {
+				res.item = true
+			}
+			continue
+		}
+		res.plural = segs[i]
+		break
+	}
+	if res.plural == "" {
+		res.plural = "root"
+	}
+	return res
+}
+
+var wordSplit = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+func pascalCase(s string) string {
+	b := &strings.Builder{}
+	for _, w := range wordSplit.Split(s, -1) {
+		if w == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	return b.String()
+}
+
+func singular(s string) string {
+	lower := strings.ToLower(s)
+	switch {
+	case strings.HasSuffix(lower, "ies") && len(s) > 3:
+		return s[:len(s)-3] + "y"
+	case strings.HasSuffix(lower, "sses"), strings.HasSuffix(lower, "xes"), strings.HasSuffix(lower, "ches"), strings.HasSuffix(lower, "shes"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(lower, "s") && !strings.HasSuffix(lower, "ss") && len(s) > 1:
+		return s[:len(s)-1]
+	}
+	return s
+}
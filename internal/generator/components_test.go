@@ -0,0 +1,103 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+func userFields() []types.Param {
+	return []types.Param{
+		{Name: "id", Type: "integer", Required: true, Description: "用户ID"},
+		{Name: "name", Type: "string", Description: "姓名"},
+	}
+}
+
+func TestRenderOpenAPIHoistsSharedSchemas(t *testing.T) {
+	item := []types.Param{{Name: "sku", Type: "string", Required: true}, {Name: "qty", Type: "integer"}}
+	doc := &types.GeneratedDoc{Scenario: "shop", Endpoints: []types.Endpoint{
+		{Method: "GET", Path: "/users/{id}", Responses: []types.Response{{StatusCode: 200, ContentType: "application/json", Fields: userFields()}}},
+		{Method: "GET", Path: "/users", Responses: []types.Response{{StatusCode: 200, ContentType: "application/json", Fields: []types.Param{
+			{Name: "total", Type: "integer"},
+			{Name: "items", Type: "array", Children: userFields()},
+		}}}},
+		{Method: "GET", Path: "/orders/{id}", Responses: []types.Response{{StatusCode: 200, ContentType: "application/json", Fields: []types.Param{
+			{Name: "owner", Type: "object", Description: "下单人", Children: userFields()},
+			{Name: "lines", Type: "array", SchemaName: "OrderLine", Children: item},
+		}}}},
+		{Method: "POST", Path: "/carts", RequestBody: &types.BodySchema{ContentType: "application/json", Fields: []types.Param{
+			{Name: "lines", Type: "array", Children: item},
+		}}, Responses: []types.Response{{StatusCode: 201, ContentType: "application/json", Fields: []types.Param{{Name: "id", Type: "integer"}}}}},
+	}}
+	outDir := t.TempDir()
+	if err := RenderOpenAPI(doc, outDir); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(outDir, "openapi.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec map[string]interface{}
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		t.Fatal(err)
+	}
+	get := func(v interface{}, keys ...string) interface{} {
+		for _, k := range keys {
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				t.Fatalf("no %q in path %v", k, keys)
+			}
+			v = m[k]
+		}
+		return v
+	}
+
+	schemas := get(spec, "components", "schemas").(map[string]interface{})
+	if len(schemas) != 2 {
+		t.Fatalf("schemas = %v, want User and OrderLine", keys(schemas))
+	}
+	if _, ok := schemas["OrderLine"]; !ok {
+		t.Fatalf("LLM-suggested name not used: %v", keys(schemas))
+	}
+	if _, ok := schemas["User"]; !ok {
+		t.Fatalf("shared body should be named after its item resource: %v", keys(schemas))
+	}
+	if ref := get(spec, "paths", "/users/{id}", "get", "responses", "200", "content", "application/json", "schema", "$ref"); ref != "#/components/schemas/User" {
+		t.Fatalf("/users/{id} schema ref = %v", ref)
+	}
+	if ref := get(spec, "paths", "/users", "get", "responses", "200", "content", "application/json", "schema", "properties", "items", "items", "$ref"); ref != "#/components/schemas/User" {
+		t.Fatalf("array items ref = %v", ref)
+	}
+	owner := get(spec, "paths", "/orders/{id}", "get", "responses", "200", "content", "application/json", "schema", "properties", "owner").(map[string]interface{})
+	if owner["description"] != "下单人" || owner["allOf"] == nil {
+		t.Fatalf("owner should wrap the ref to keep its description: %v", owner)
+	}
+	lines := get(spec, "paths", "/carts", "post", "requestBody", "content", "application/json", "schema", "properties", "lines", "items", "$ref")
+	if lines != "#/components/schemas/OrderLine" {
+		t.Fatalf("cart lines ref = %v", lines)
+	}
+	if _, ok := schemas["CartsRequest"]; ok {
+		t.Fatalf("unique structures should stay inline")
+	}
+}
+
+func TestComponentNamesShareCleaning(t *testing.T) {
+	if got := types.ComponentName("Order Line/项目"); got != "OrderLine" {
+		t.Fatalf("ComponentName = %q", got)
+	}
+	if id := (types.AuthScheme{Type: types.AuthAPIKey, In: "header", Name: "X Token"}).ID(); id != "apiKey_header_XToken" {
+		t.Fatalf("AuthScheme.ID = %q", id)
+	}
+}
+
+func keys(m map[string]interface{}) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
@@ -0,0 +1,133 @@
+package generator
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yourorg/apidoc/internal/dataflow"
+	"github.com/yourorg/apidoc/internal/pathmatch"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// AttachDataFlow records the traced data flow between the doc's endpoints
+// and rewrites call chain dependencies to follow it: a step depends on the
+// latest earlier step whose response fed it. Steps without traced inputs
+// keep the LLM's guess. logs must not be sanitized yet, since tokens are
+// matched by value.
+func AttachDataFlow(doc *types.GeneratedDoc, logs []types.TrafficLog) {
+	if doc == nil {
+		return
+	}
+	doc.DataFlow = nil
+	seen := map[types.DataFlow]bool{}
+	for _, f := range dataflow.Trace(logs) {
+		from, ok := endpointIndex(doc.Endpoints, types.TrafficLog{Method: f.FromMethod, Path: f.FromPath})
+		if !ok {
+			continue
+		}
+		to, ok := endpointIndex(doc.Endpoints, types.TrafficLog{Method: f.ToMethod, Path: f.ToPath})
+		if !ok {
+			continue
+		}
+		param := f.Name
+		if f.In == dataflow.InPath {
+			if param, ok = pathParamAt(doc.Endpoints[to].Path, f.Segment); !ok {
+				continue
+			}
+		}
+		df := types.DataFlow{
+			FromMethod: doc.Endpoints[from].Method,
+			FromPath:   doc.Endpoints[from].Path,
+			Field:      f.Field,
+			ToMethod:   doc.Endpoints[to].Method,
+			ToPath:     doc.Endpoints[to].Path,
+			In:         f.In,
+			Param:      param,
+		}
+		if !seen[df] {
+			seen[df] = true
+			doc.DataFlow = append(doc.DataFlow, df)
+		}
+	}
+
+	feeds := map[[2]int]bool{} // from, to endpoint indexes
+	for _, l := range dataLinks(doc) {
+		feeds[[2]int{l.from, l.to}] = true
+	}
+	steps := make([]int, len(doc.CallChain))
+	for k := range steps {
+		steps[k] = k
+	}
+	sort.SliceStable(steps, func(a, b int) bool { return doc.CallChain[steps[a]].Seq < doc.CallChain[steps[b]].Seq })
+	for pos, k := range steps {
+		step := &doc.CallChain[k]
+		to, ok := endpointIndex(doc.Endpoints, types.TrafficLog{Method: step.Method, Path: step.Path})
+		if !ok {
+			continue
+		}
+		for back := pos - 1; back >= 0; back-- {
+			prev := doc.CallChain[steps[back]]
+			from, ok := endpointIndex(doc.Endpoints, types.TrafficLog{Method: prev.Method, Path: prev.Path})
+			if ok && feeds[[2]int{from, to}] {
+				seq := prev.Seq
+				step.DependsOn = &seq
+				break
+			}
+		}
+	}
+}
+
+// pathParamAt names the template parameter at a path segment index.
+func pathParamAt(template string, segment int) (string, bool) {
+	segs := strings.Split(strings.Trim(template, "/"), "/")
+	if segment < 0 || segment >= len(segs) || !pathmatch.IsTemplate("/"+segs[segment]) {
+		return "", false
+	}
+	return strings.Trim(segs[segment], "{}:"), true
+}
+
+// dataLink is a DataFlow resolved to endpoint indexes.
+type dataLink struct {
+	from, to int
+	field    string // JSONPath
+	in       string
+	param    string
+}
+
+// dataLinks resolves the doc's data flow against its endpoints.
+func dataLinks(doc *types.GeneratedDoc) []dataLink {
+	var links []dataLink
+	for _, f := range doc.DataFlow {
+		from, okFrom := endpointIndex(doc.Endpoints, types.TrafficLog{Method: f.FromMethod, Path: f.FromPath})
+		to, okTo := endpointIndex(doc.Endpoints, types.TrafficLog{Method: f.ToMethod, Path: f.ToPath})
+		if okFrom && okTo {
+			links = append(links, dataLink{from: from, to: to, field: f.Field, in: f.In, param: f.Param})
+		}
+	}
+	return links
+}
+
+// describe renders the request side, e.g. "path id" or "header Authorization".
+func (l dataLink) describe() string {
+	return l.in + " " + l.param
+}
+
+var jsonPathPart = regexp.MustCompile(`\.([^.\[]+)|\[(\d+)\]|\['((?:[^'\\]|\\.)*)'\]`)
+
+// jsonPathKeys splits a JSONPath such as $.items[0]['x-id'] into keys,
+// keeping array indexes as "[0]".
+func jsonPathKeys(path string) []string {
+	var keys []string
+	for _, m := range jsonPathPart.FindAllStringSubmatch(strings.TrimPrefix(path, "$"), -1) {
+		switch {
+		case m[1] != "":
+			keys = append(keys, m[1])
+		case m[2] != "":
+			keys = append(keys, "["+m[2]+"]")
+		default:
+			keys = append(keys, m[3])
+		}
+	}
+	return keys
+}
@@ -0,0 +1,63 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+func TestAttachDataFlow(t *testing.T) {
+	guess := 1
+	doc := &types.GeneratedDoc{
+		CallChain: []types.ChainStep{
+			{Seq: 1, Method: "POST", Path: "/login"},
+			{Seq: 2, Method: "POST", Path: "/orders", DependsOn: &guess},
+			{Seq: 3, Method: "GET", Path: "/orders/{id}", DependsOn: &guess},
+		},
+		Endpoints: []types.Endpoint{
+			{Method: "POST", Path: "/login"},
+			{Method: "POST", Path: "/orders"},
+			{Method: "GET", Path: "/orders/{id}"},
+		},
+	}
+	logs := []types.TrafficLog{
+		{Seq: 1, Method: "POST", Path: "/login", ResponseBody: `{"token":"tk_9f8e7d6c5b4a"}`},
+		{Seq: 2, Method: "POST", Path: "/orders", ResponseBody: `{"data":{"order_id":"ord_81"}}`,
+			RequestHeaders: map[string][]string{"Authorization": {"Bearer tk_9f8e7d6c5b4a"}}},
+		{Seq: 3, Method: "GET", Path: "/orders/ord_81"},
+		{Seq: 4, Method: "GET", Path: "/orders/ord_81"},
+	}
+	AttachDataFlow(doc, logs)
+
+	want := []types.DataFlow{
+		{FromMethod: "POST", FromPath: "/login", Field: "$.token", ToMethod: "POST", ToPath: "/orders", In: "header", Param: "Authorization"},
+		{FromMethod: "POST", FromPath: "/orders", Field: "$.data.order_id", ToMethod: "GET", ToPath: "/orders/{id}", In: "path", Param: "id"},
+	}
+	if len(doc.DataFlow) != len(want) {
+		t.Fatalf("got %d flows, want %d: %+v", len(doc.DataFlow), len(want), doc.DataFlow)
+	}
+	for i := range want {
+		if doc.DataFlow[i] != want[i] {
+			t.Fatalf("flow %d = %+v, want %+v", i, doc.DataFlow[i], want[i])
+		}
+	}
+	if doc.CallChain[1].DependsOn == nil || *doc.CallChain[1].DependsOn != 1 {
+		t.Fatalf("step 2 should depend on step 1: %+v", doc.CallChain[1].DependsOn)
+	}
+	if doc.CallChain[2].DependsOn == nil || *doc.CallChain[2].DependsOn != 2 {
+		t.Fatalf("step 3 should depend on step 2, not the LLM guess: %+v", doc.CallChain[2].DependsOn)
+	}
+}
+
+func TestJSONPathKeys(t *testing.T) {
+	got := jsonPathKeys("$.items[0]['x-id'].name")
+	want := []string{"items", "[0]", "x-id", "name"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// Diagram formats.
+const (
+	DiagramMermaid  = "mermaid"
+	DiagramPlantUML = "plantuml"
+)
+
+// chainDiagram is the call chain resolved against the doc's endpoints,
+// with data links attached to the steps that produce them.
+type chainDiagram struct {
+	server string
+	steps  []diagramStep
+	edges  []diagramEdge
+}
+
+type diagramStep struct {
+	seq    int
+	label  string // "1. POST /login"
+	status string // response arrow label
+	notes  []string
+}
+
+type diagramEdge struct {
+	from, to int // step seqs
+	label    string
+}
+
+func buildChainDiagram(doc *types.GeneratedDoc, server string) chainDiagram {
+	d := chainDiagram{server: server}
+	if d.server == "" {
+		d.server = "API"
+	}
+	steps := append([]types.ChainStep(nil), doc.CallChain...)
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].Seq < steps[j].Seq })
+
+	stepOf := map[int]int{} // endpoint index → first step seq
+	for _, step := range steps {
+		ds := diagramStep{seq: step.Seq, label: fmt.Sprintf("%d. %s %s", step.Seq, strings.ToUpper(step.Method), step.Path), status: "response"}
+		if i, ok := endpointIndex(doc.Endpoints, types.TrafficLog{Method: step.Method, Path: step.Path}); ok {
+			if _, seen := stepOf[i]; !seen {
+				stepOf[i] = step.Seq
+			}
+			if status, ok := successStatus(doc.Endpoints[i]); ok {
+				ds.status = fmt.Sprint(status)
+			}
+		}
+		d.steps = append(d.steps, ds)
+	}
+
+	linked := map[[2]int]bool{}
+	for _, link := range dataLinks(doc) {
+		from, okFrom := stepOf[link.from]
+		to, okTo := stepOf[link.to]
+		if !okFrom || !okTo {
+			continue
+		}
+		for k := range d.steps {
+			if d.steps[k].seq == from {
+				d.steps[k].notes = append(d.steps[k].notes, fmt.Sprintf("%s → step %d %s", link.field, to, link.describe()))
+			}
+		}
+		d.edges = append(d.edges, diagramEdge{from: from, to: to, label: link.field + " → " + link.describe()})
+		linked[[2]int{from, to}] = true
+	}
+	for _, step := range steps {
+		if step.DependsOn != nil && !linked[[2]int{*step.DependsOn, step.Seq}] {
+			d.edges = append(d.edges, diagramEdge{from: *step.DependsOn, to: step.Seq})
+		}
+	}
+	return d
+}
+
+// SequenceDiagram renders the call chain as a Mermaid or PlantUML sequence
+// diagram between the client and server, noting which response fields
+// later requests reuse. It returns "" when the doc has no call chain.
+func SequenceDiagram(doc *types.GeneratedDoc, format, server string) string {
+	if doc == nil || len(doc.CallChain) == 0 {
+		return ""
+	}
+	d := buildChainDiagram(doc, server)
+	b := &strings.Builder{}
+	switch format {
+	case DiagramPlantUML:
+		fmt.Fprintln(b, "@startuml")
+		fmt.Fprintln(b, "participant Client")
+		fmt.Fprintf(b, "participant \"%s\" as API\n", strings.ReplaceAll(d.server, `"`, "'"))
+		for _, s := range d.steps {
+			fmt.Fprintf(b, "Client -> API: %s\n", s.label)
+			fmt.Fprintf(b, "API --> Client: %s\n", s.status)
+			for _, n := range s.notes {
+				fmt.Fprintf(b, "note over Client, API: %s\n", n)
+			}
+		}
+		fmt.Fprintln(b, "@enduml")
+	default:
+		fmt.Fprintln(b, "sequenceDiagram")
+		fmt.Fprintln(b, "    participant C as Client")
+		fmt.Fprintf(b, "    participant S as %s\n", mermaidText(d.server))
+		for _, s := range d.steps {
+			fmt.Fprintf(b, "    C->>S: %s\n", mermaidText(s.label))
+			fmt.Fprintf(b, "    S-->>C: %s\n", mermaidText(s.status))
+			for _, n := range s.notes {
+				fmt.Fprintf(b, "    Note over C,S: %s\n", mermaidText(n))
+			}
+		}
+	}
+	return b.String()
+}
+
+// FlowDiagram renders the call chain as a Mermaid flowchart whose edges are
+// dependencies, labelled with the response field and request parameter
+// that carried data between steps.
+func FlowDiagram(doc *types.GeneratedDoc) string {
+	if doc == nil || len(doc.CallChain) == 0 {
+		return ""
+	}
+	d := buildChainDiagram(doc, "")
+	b := &strings.Builder{}
+	fmt.Fprintln(b, "flowchart TD")
+	for _, s := range d.steps {
+		fmt.Fprintf(b, "    s%d[\"%s\"]\n", s.seq, mermaidLabel(s.label))
+	}
+	for _, e := range d.edges {
+		if e.label == "" {
+			fmt.Fprintf(b, "    s%d --> s%d\n", e.from, e.to)
+			continue
+		}
+		fmt.Fprintf(b, "    s%d -->|\"%s\"| s%d\n", e.from, mermaidLabel(e.label), e.to)
+	}
+	return b.String()
+}
+
+// mermaidText escapes characters that end or comment a Mermaid statement.
+func mermaidText(s string) string {
+	return strings.NewReplacer(";", "#59;", "#", "#35;", "\n", " ").Replace(s)
+}
+
+// mermaidLabel escapes a quoted node or edge label.
+func mermaidLabel(s string) string {
+	return strings.ReplaceAll(mermaidText(s), `"`, "#quot;")
+}
+
+// firstServer is the most recorded server URL, or "".
+func firstServer(servers []string) string {
+	if len(servers) == 0 {
+		return ""
+	}
+	return servers[0]
+}
+
+// serverName is the host of the first server URL, for diagram participants.
+func serverName(servers []string) string {
+	if len(servers) == 0 {
+		return ""
+	}
+	s := servers[0]
+	if i := strings.Index(s, "://"); i >= 0 {
+		s = s[i+3:]
+	}
+	return s
+}
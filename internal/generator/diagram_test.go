@@ -0,0 +1,92 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+func chainDoc() *types.GeneratedDoc {
+	first := 1
+	return &types.GeneratedDoc{
+		Scenario: "checkout",
+		CallChain: []types.ChainStep{
+			{Seq: 1, Method: "POST", Path: "/orders", Description: "create"},
+			{Seq: 2, Method: "GET", Path: "/orders/{id}", DependsOn: &first},
+			{Seq: 3, Method: "GET", Path: "/health", DependsOn: &first},
+		},
+		Endpoints: []types.Endpoint{
+			{
+				Method: "POST", Path: "/orders",
+				Responses: []types.Response{{StatusCode: 201}},
+				Examples:  []types.RecordedExample{{StatusCode: 201, Path: "/orders", ResponseBody: `{"data":{"order_id":"ord_81"}}`}},
+			},
+			{
+				Method: "GET", Path: "/orders/{id}",
+				Responses: []types.Response{{StatusCode: 200}},
+				Examples:  []types.RecordedExample{{StatusCode: 200, Path: "/orders/ord_81"}},
+			},
+			{Method: "GET", Path: "/health", Responses: []types.Response{{StatusCode: 200}}},
+		},
+		DataFlow: []types.DataFlow{
+			{FromMethod: "POST", FromPath: "/orders", Field: "$.data.order_id", ToMethod: "GET", ToPath: "/orders/{id}", In: "path", Param: "id"},
+		},
+	}
+}
+
+func TestSequenceDiagrams(t *testing.T) {
+	doc := chainDoc()
+
+	mermaid := SequenceDiagram(doc, DiagramMermaid, "shop.example.com")
+	for _, want := range []string{
+		"sequenceDiagram\n",
+		"participant S as shop.example.com",
+		"C->>S: 1. POST /orders",
+		"S-->>C: 201",
+		"Note over C,S: $.data.order_id → step 2 path id",
+	} {
+		if !strings.Contains(mermaid, want) {
+			t.Fatalf("mermaid missing %q:\n%s", want, mermaid)
+		}
+	}
+
+	puml := SequenceDiagram(doc, DiagramPlantUML, "")
+	if !strings.HasPrefix(puml, "@startuml\n") || !strings.Contains(puml, `participant "API" as API`) ||
+		!strings.Contains(puml, "note over Client, API: $.data.order_id → step 2 path id") {
+		t.Fatalf("unexpected plantuml:\n%s", puml)
+	}
+
+	flow := FlowDiagram(doc)
+	if !strings.Contains(flow, `s1 -->|"$.data.order_id → path id"| s2`) || !strings.Contains(flow, "s1 --> s3") {
+		t.Fatalf("unexpected flowchart:\n%s", flow)
+	}
+	if strings.Contains(flow, "s1 --> s2\n") {
+		t.Fatalf("linked dependency should not be drawn twice:\n%s", flow)
+	}
+
+	if SequenceDiagram(&types.GeneratedDoc{}, DiagramMermaid, "") != "" {
+		t.Fatalf("expected no diagram without a call chain")
+	}
+}
+
+func TestWriteMarkdownDiagrams(t *testing.T) {
+	outDir := t.TempDir()
+	if err := WriteMarkdown(chainDoc(), outDir, MarkdownOptions{Diagrams: []string{DiagramMermaid, DiagramPlantUML}}); err != nil {
+		t.Fatal(err)
+	}
+	readme, err := os.ReadFile(filepath.Join(outDir, "README.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"```mermaid\nsequenceDiagram", "```mermaid\nflowchart TD", "```plantuml\n@startuml"} {
+		if !strings.Contains(string(readme), want) {
+			t.Fatalf("README missing %q:\n%s", want, readme)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "call-chain.puml")); err != nil {
+		t.Fatalf("expected call-chain.puml: %v", err)
+	}
+}
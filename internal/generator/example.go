@@ -0,0 +1,55 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// ExampleObject builds a placeholder JSON object from documented fields,
+// using the same type inference as the OpenAPI renderer.
+func ExampleObject(fields []types.Param) map[string]any {
+	out := make(map[string]any, len(fields))
+	for _, f := range fields {
+		out[f.Name] = exampleValue(f)
+	}
+	return out
+}
+
+func exampleValue(p types.Param) any {
+	typeName, format := inferType(p.Type)
+	switch typeName {
+	case "array":
+		if len(p.Children) > 0 {
+			return []any{ExampleObject(p.Children)}
+		}
+		if strings.Contains(strings.ToLower(p.Type), "integer") {
+			return []any{0}
+		}
+		return []any{"string"}
+	case "object":
+		return ExampleObject(p.Children)
+	case "integer":
+		return 0
+	case "number":
+		return 0.0
+	case "boolean":
+		return true
+	}
+	if len(p.Children) > 0 {
+		return ExampleObject(p.Children)
+	}
+	switch format {
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	}
+	return "string"
+}
+
+// FieldType maps a documented type such as "integer" or "array<object>" to
+// its JSON Schema type and format.
+func FieldType(t string) (string, string) {
+	return inferType(t)
+}
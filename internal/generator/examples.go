@@ -0,0 +1,156 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yourorg/apidoc/internal/filter"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+const (
+	// maxExamplesPerStatus bounds the recorded examples kept per endpoint and
+	// status code, so frequent 200s cannot crowd out the error responses.
+	maxExamplesPerStatus = 3
+	// maxExampleBodyBytes is the largest body embedded in an example; bigger
+	// ones would bloat every output format, so they are left out.
+	maxExampleBodyBytes = 16 << 10
+)
+
+// AttachExamples records on each endpoint the first call seen for every
+// status code and parameter combination. logs must not be sanitized yet;
+// request and response bodies are sanitized here before they are kept.
+func AttachExamples(doc *types.GeneratedDoc, logs []types.TrafficLog, cfg filter.SanitizeConfig) {
+	if doc == nil {
+		return
+	}
+	seen := make([]map[string]bool, len(doc.Endpoints))
+	perStatus := make([]map[int]int, len(doc.Endpoints))
+	for i := range doc.Endpoints {
+		doc.Endpoints[i].Examples = nil
+		seen[i] = map[string]bool{}
+		perStatus[i] = map[int]int{}
+	}
+	for _, l := range filter.Sanitize(logs, cfg) {
+		i, ok := endpointIndex(doc.Endpoints, l)
+		if !ok || perStatus[i][l.StatusCode] >= maxExamplesPerStatus {
+			continue
+		}
+		params := sentParams(l)
+		key := fmt.Sprintf("%d %s", l.StatusCode, strings.Join(params, ","))
+		if seen[i][key] {
+			continue
+		}
+		seen[i][key] = true
+		perStatus[i][l.StatusCode]++
+		name := strconv.Itoa(l.StatusCode)
+		if n := perStatus[i][l.StatusCode]; n > 1 {
+			name = fmt.Sprintf("%s_%d", name, n)
+		}
+		ex := types.RecordedExample{
+			Name:                name,
+			Seq:                 l.Seq,
+			StatusCode:          l.StatusCode,
+			Path:                l.Path,
+			Query:               l.QueryParams,
+			Params:              params,
+			ResponseContentType: l.ResponseContentType,
+		}
+		if l.RequestBodyEncoding == "" || l.RequestBodyEncoding == "plain" {
+			ex.RequestContentType = l.ContentType
+			if len(l.RequestBody) <= maxExampleBodyBytes {
+				ex.RequestBody = l.RequestBody
+			}
+		}
+		if len(l.ResponseBody) <= maxExampleBodyBytes && utf8.ValidString(l.ResponseBody) {
+			ex.ResponseBody = filter.SanitizeBody(l.ResponseBody, cfg)
+		}
+		doc.Endpoints[i].Examples = append(doc.Endpoints[i].Examples, ex)
+	}
+}
+
+// sentParams lists the query parameters and top-level body fields a call
+// carried, ignoring their values.
+func sentParams(l types.TrafficLog) []string {
+	set := map[string]bool{}
+	for k := range l.QueryParams {
+		set[k] = true
+	}
+	for k := range l.FormParams {
+		set[k] = true
+	}
+	var body map[string]json.RawMessage
+	if json.Unmarshal([]byte(l.RequestBody), &body) == nil {
+		for k := range body {
+			set[k] = true
+		}
+	}
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// mediaExamples builds an OpenAPI examples map from recorded bodies. body
+// selects the request or response side; examples without one are skipped.
+func mediaExamples(examples []types.RecordedExample, body func(types.RecordedExample) string) map[string]interface{} {
+	out := map[string]interface{}{}
+	for _, ex := range examples {
+		b := body(ex)
+		if strings.TrimSpace(b) == "" {
+			continue
+		}
+		summary := fmt.Sprintf("seq %d", ex.Seq)
+		if len(ex.Params) > 0 {
+			summary += ": " + strings.Join(ex.Params, ", ")
+		}
+		out[ex.Name] = map[string]interface{}{"summary": summary, "value": exampleData(b)}
+	}
+	return out
+}
+
+// requestExamples keeps one request example per parameter combination.
+func requestExamples(examples []types.RecordedExample) map[string]interface{} {
+	seen := map[string]bool{}
+	var unique []types.RecordedExample
+	for _, ex := range examples {
+		key := strings.Join(ex.Params, ",")
+		if ex.RequestBody == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, ex)
+	}
+	return mediaExamples(unique, func(ex types.RecordedExample) string { return ex.RequestBody })
+}
+
+func responseExamples(examples []types.RecordedExample, status int) map[string]interface{} {
+	var matching []types.RecordedExample
+	for _, ex := range examples {
+		if ex.StatusCode == status {
+			matching = append(matching, ex)
+		}
+	}
+	return mediaExamples(matching, func(ex types.RecordedExample) string { return ex.ResponseBody })
+}
+
+// fallbackExampleStatus picks the one response the LLM-written example
+// belongs to: the first 2xx, else the first response. It returns -1 when
+// there is no such example.
+func fallbackExampleStatus(ep types.Endpoint) int {
+	if ep.Example == nil || ep.Example.Response == "" || len(ep.Responses) == 0 {
+		return -1
+	}
+	for _, resp := range ep.Responses {
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return resp.StatusCode
+		}
+	}
+	return ep.Responses[0].StatusCode
+}
@@ -0,0 +1,146 @@
+package generator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/yourorg/apidoc/internal/config"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+func TestAttachExamplesAndRender(t *testing.T) {
+	doc := &types.GeneratedDoc{Scenario: "examples", Endpoints: []types.Endpoint{{
+		Method:      "POST",
+		Path:        "/login",
+		RequestBody: &types.BodySchema{ContentType: "application/json", Fields: []types.Param{{Name: "user", Type: "string"}}},
+		Responses: []types.Response{
+			{StatusCode: 200, ContentType: "application/json"},
+			{StatusCode: 401, ContentType: "application/json"},
+		},
+		Example: &types.Example{Response: `{"llm":true}`},
+	}}}
+	logs := []types.TrafficLog{
+		{Seq: 1, Method: "POST", Path: "/login", ContentType: "application/json", RequestBody: `{"user":"a","password":"p1"}`, StatusCode: 401, ResponseContentType: "application/json", ResponseBody: `{"error":"bad password"}`},
+		{Seq: 2, Method: "POST", Path: "/login", ContentType: "application/json", RequestBody: `{"user":"a","password":"p2"}`, StatusCode: 401, ResponseBody: `{"error":"again"}`},
+		{Seq: 3, Method: "POST", Path: "/login", ContentType: "application/json", RequestBody: `{"user":"a","password":"p3"}`, StatusCode: 200, ResponseBody: `{"token":"secret-token"}`},
+		{Seq: 4, Method: "POST", Path: "/login", QueryParams: map[string][]string{"remember": {"1"}}, ContentType: "application/json", RequestBody: `{"user":"a","password":"p4"}`, StatusCode: 200, ResponseBody: `{"token":"other"}`},
+	}
+	cfg := config.Config{}
+	cfg.SetDefaults()
+	AttachExamples(doc, logs, cfg.Sanitize)
+
+	examples := doc.Endpoints[0].Examples
+	if len(examples) != 3 {
+		t.Fatalf("examples = %d, want one per status and param combination: %+v", len(examples), examples)
+	}
+	if examples[1].Name != "200" || examples[2].Name != "200_2" {
+		t.Fatalf("unexpected names: %q %q", examples[1].Name, examples[2].Name)
+	}
+	for _, ex := range examples {
+		if strings.Contains(ex.RequestBody, "p1") || strings.Contains(ex.ResponseBody, "secret-token") {
+			t.Fatalf("example not sanitized: %+v", ex)
+		}
+	}
+
+	spec, err := BuildOpenAPI(doc, OpenAPIOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := spec["paths"].(map[string]interface{})["/login"].(map[string]interface{})["post"].(map[string]interface{})
+	media := func(code string) map[string]interface{} {
+		resp := op["responses"].(map[string]interface{})[code].(map[string]interface{})
+		return resp["content"].(map[string]interface{})["application/json"].(map[string]interface{})
+	}
+	unauthorized := media("401")["examples"].(map[string]interface{})
+	if len(unauthorized) != 1 {
+		t.Fatalf("401 examples = %v", unauthorized)
+	}
+	value := unauthorized["401"].(map[string]interface{})["value"].(map[string]interface{})
+	if value["error"] != "bad password" {
+		t.Fatalf("401 example should be the recorded body parsed as JSON, got %v", value)
+	}
+	if ok := media("200")["examples"].(map[string]interface{}); len(ok) != 2 {
+		t.Fatalf("200 examples = %v", ok)
+	}
+	if _, ok := media("200")["example"]; ok {
+		t.Fatalf("LLM example should not be used when traffic examples exist")
+	}
+	reqExamples := op["requestBody"].(map[string]interface{})["content"].(map[string]interface{})["application/json"].(map[string]interface{})["examples"].(map[string]interface{})
+	if len(reqExamples) != 2 {
+		t.Fatalf("request examples = %d, want one per param combination", len(reqExamples))
+	}
+}
+
+func TestExamplesCappedPerStatus(t *testing.T) {
+	doc := &types.GeneratedDoc{Endpoints: []types.Endpoint{{Method: "GET", Path: "/items"}}}
+	var logs []types.TrafficLog
+	for i, q := range []string{"a", "b", "c", "d", "e"} {
+		logs = append(logs, types.TrafficLog{Seq: i + 1, Method: "GET", Path: "/items", QueryParams: map[string][]string{q: {"1"}}, StatusCode: 200, ResponseBody: `{"ok":true}`})
+	}
+	big := `{"data":"` + strings.Repeat("x", maxExampleBodyBytes) + `"}`
+	logs = append(logs, types.TrafficLog{Seq: 6, Method: "GET", Path: "/items", StatusCode: 404, ResponseBody: big})
+	cfg := config.Config{}
+	cfg.SetDefaults()
+	AttachExamples(doc, logs, cfg.Sanitize)
+
+	statuses := map[int]int{}
+	for _, ex := range doc.Endpoints[0].Examples {
+		statuses[ex.StatusCode]++
+		if ex.StatusCode == 404 && ex.ResponseBody != "" {
+			t.Fatalf("oversized body should not be embedded: %d bytes", len(ex.ResponseBody))
+		}
+	}
+	if statuses[200] != maxExamplesPerStatus || statuses[404] != 1 {
+		t.Fatalf("examples per status = %v", statuses)
+	}
+}
+
+func TestLLMExampleOnlyOnSuccessResponse(t *testing.T) {
+	doc := &types.GeneratedDoc{Scenario: "fallback", Endpoints: []types.Endpoint{{
+		Method: "GET",
+		Path:   "/items",
+		Responses: []types.Response{
+			{StatusCode: 404, ContentType: "application/json"},
+			{StatusCode: 200, ContentType: "application/json"},
+		},
+		Example: &types.Example{Response: `{"items":[]}`},
+	}}}
+	spec, err := BuildOpenAPI(doc, OpenAPIOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	responses := spec["paths"].(map[string]interface{})["/items"].(map[string]interface{})["get"].(map[string]interface{})["responses"].(map[string]interface{})
+	for code, want := range map[string]bool{"200": true, "404": false} {
+		media := responses[code].(map[string]interface{})["content"].(map[string]interface{})["application/json"].(map[string]interface{})
+		if _, ok := media["example"]; ok != want {
+			t.Fatalf("%s example present = %v, want %v", code, ok, want)
+		}
+	}
+}
+
+func TestExampleBodiesKeepLiterals(t *testing.T) {
+	body := `{"id":9007199254740993,"price":1.50,"html":"<b>a & b</b>"}`
+	data, err := yaml.Marshal(map[string]interface{}{"value": exampleData(body)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"id: 9007199254740993", "price: 1.50", "html: <b>a & b</b>"} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("yaml missing %q:\n%s", want, data)
+		}
+	}
+	data, err = json.Marshal(exampleData(body))
+	if err != nil || !strings.Contains(string(data), `"id":9007199254740993`) {
+		t.Fatalf("json example = %s, %v", data, err)
+	}
+	pretty := prettyJSON(body)
+	if !strings.Contains(pretty, `"id": 9007199254740993`) || !strings.Contains(pretty, `"<b>a & b</b>"`) {
+		t.Fatalf("prettyJSON = %s", pretty)
+	}
+	if got := prettyJSON("not json"); got != "not json" {
+		t.Fatalf("prettyJSON(text) = %q", got)
+	}
+}
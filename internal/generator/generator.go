@@ -4,8 +4,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/yourorg/apidoc/internal/config"
+	"github.com/yourorg/apidoc/internal/dataflow"
 	"github.com/yourorg/apidoc/internal/filter"
 	"github.com/yourorg/apidoc/internal/store"
 	"github.com/yourorg/apidoc/pkg/types"
@@ -17,22 +24,36 @@ type LLMConfig = config.LLMConfig
 // ProgressFunc reports generation progress.
 type ProgressFunc func(stage string)
 
-// Generate orchestrates filtering, sanitization, batching, LLM calls, caching and rendering.
+// Generate orchestrates filtering, sanitization, batching, LLM calls, caching
+// and rendering, using default settings for everything but the LLM.
 func Generate(sess *types.Session, logs []types.TrafficLog, llmCfg LLMConfig, st store.Store, onProgress ProgressFunc, noCache bool, resume bool) (*types.GeneratedDoc, error) {
+	cfg := &config.Config{}
+	cfg.SetDefaults()
+	cfg.LLM = llmCfg
+	return GenerateWithConfig(sess, logs, cfg, st, onProgress, noCache, resume)
+}
+
+// GenerateWithConfig is Generate with the filter, sanitize and output
+// settings taken from cfg.
+func GenerateWithConfig(sess *types.Session, logs []types.TrafficLog, cfg *config.Config, st store.Store, onProgress ProgressFunc, noCache bool, resume bool) (*types.GeneratedDoc, error) {
 	if sess == nil {
 		return nil, errors.New("session is nil")
 	}
 	if st == nil {
 		return nil, errors.New("store is nil")
 	}
-
-	cfg := &config.Config{}
-	cfg.SetDefaults()
+	llmCfg := cfg.LLM
+	prompts, err := LoadPrompts(llmCfg.PromptDir)
+	if err != nil {
+		return nil, err
+	}
 
 	report(onProgress, "filtering logs")
 	filtered := filter.Apply(logs, cfg.Filter)
 	report(onProgress, "sanitizing logs")
 	sanitized := filter.Sanitize(filtered, cfg.Sanitize)
+	// Traced before sanitizing: redacted tokens would no longer match.
+	flows := dataflow.Trace(filtered)
 
 	if err := st.UpdateSessionStatus(sess.ID, "generating"); err != nil {
 		return nil, err
@@ -63,7 +84,8 @@ func Generate(sess *types.Session, logs []types.TrafficLog, llmCfg LLMConfig, st
 	hasFailure := false
 	for i, batch := range batches {
 		if resume {
-			if cache, ok := cacheByIndex(caches, i); ok && cache.Status == "ok" {
+			// Batches generated with other prompts are redone.
+			if cache, ok := cacheByIndex(caches, i); ok && cache.Status == "ok" && cache.BatchKey == batchKey(batch) && cache.PromptVersion == prompts.Version {
 				report(onProgress, fmt.Sprintf("batch %d/%d: using cache", i+1, len(batches)))
 				doc, err := parseCachedDoc(cache)
 				if err == nil {
@@ -74,12 +96,13 @@ func Generate(sess *types.Session, logs []types.TrafficLog, llmCfg LLMConfig, st
 		}
 
 		report(onProgress, fmt.Sprintf("batch %d/%d: calling LLM", i+1, len(batches)))
-		doc, raw, err := callLLM(sess, batch, llmCfg)
+		doc, raw, err := callLLM(sess, batch, flows, llmCfg, prompts)
 		cache := &types.LLMCache{
-			SessionID:  sess.ID,
-			BatchIndex: i,
-			BatchKey:   batchKey(batch),
-			Model:      llmCfg.Model,
+			SessionID:     sess.ID,
+			BatchIndex:    i,
+			BatchKey:      batchKey(batch),
+			Model:         llmCfg.Model,
+			PromptVersion: prompts.Version,
 		}
 		if err != nil {
 			cache.Status = "failed"
@@ -101,19 +124,46 @@ func Generate(sess *types.Session, logs []types.TrafficLog, llmCfg LLMConfig, st
 	}
 
 	merged := MergeDocs(allDocs)
+	merged.PromptVersion = prompts.Version
+	AttachAuth(merged, filtered, cfg.Sanitize)
+	AttachExamples(merged, filtered, cfg.Sanitize)
+	AttachDataFlow(merged, filtered)
 
-	report(onProgress, "rendering outputs")
-	for _, format := range cfg.Output.Formats {
-		switch format {
-		case "markdown":
-			if err := RenderMarkdown(merged, cfg.Output.Dir); err != nil {
-				return nil, err
-			}
-		case "openapi":
-			if err := RenderOpenAPI(merged, cfg.Output.Dir); err != nil {
+	servers := ServerURLs(filtered, sess.Host)
+	meta := GenerationMeta{
+		SessionID:     sess.ID,
+		Model:         llmCfg.Model,
+		PromptVersion: prompts.Version,
+		GeneratedAt:   time.Now().UTC(),
+	}
+	if len(cfg.Output.Languages) == 0 {
+		report(onProgress, "rendering outputs")
+		if err := RenderOutputs(merged, cfg.Output, servers); err != nil {
+			return nil, err
+		}
+		if err := writeMeta(cfg.Output.Dir, meta); err != nil {
+			return nil, err
+		}
+	}
+	// Each language is rendered into its own subdirectory of output.dir.
+	for _, lang := range cfg.Output.Languages {
+		doc := merged
+		if lang != SourceLanguage {
+			var err error
+			if doc, err = translateDoc(st, sess.ID, merged, lang, llmCfg, prompts, onProgress); err != nil {
 				return nil, err
 			}
 		}
+		report(onProgress, "rendering outputs: "+lang)
+		out := cfg.Output
+		out.Dir = filepath.Join(out.Dir, lang)
+		if err := RenderOutputs(doc, out, servers); err != nil {
+			return nil, err
+		}
+		meta.Language = lang
+		if err := writeMeta(out.Dir, meta); err != nil {
+			return nil, err
+		}
 	}
 
 	if hasFailure {
@@ -129,6 +179,144 @@ func Generate(sess *types.Session, logs []types.TrafficLog, llmCfg LLMConfig, st
 	return merged, nil
 }
 
+// RenderOutputs writes every configured output format for doc.
+func RenderOutputs(doc *types.GeneratedDoc, out config.OutputConfig, servers []string) error {
+	for _, format := range out.Formats {
+		var err error
+		switch format {
+		case "markdown":
+			err = WriteMarkdown(doc, out.Dir, MarkdownOptions{
+				Diagrams:     out.Diagrams,
+				Server:       serverName(servers),
+				BaseURL:      firstServer(servers),
+				TemplatesDir: out.TemplatesDir,
+			})
+		case "openapi", "openapi-json":
+			err = WriteOpenAPI(doc, out.Dir, OpenAPIOptions{
+				Version: out.OpenAPIVersion,
+				Servers: servers,
+				JSON:    format == "openapi-json",
+			})
+		case "postman":
+			err = RenderPostman(doc, out.Dir, servers)
+		case "html":
+			err = RenderHTML(doc, out.Dir, servers, HTMLOptions{MermaidURL: out.MermaidURL})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GenerationMeta is written to meta.json beside the rendered outputs.
+type GenerationMeta struct {
+	SessionID     string    `json:"session_id"`
+	Model         string    `json:"model"`
+	PromptVersion string    `json:"prompt_version"`
+	Language      string    `json:"language,omitempty"`
+	GeneratedAt   time.Time `json:"generated_at"`
+}
+
+func writeMeta(dir string, meta GenerationMeta) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "meta.json"), append(data, '\n'), 0o644)
+}
+
+// ServerURLs lists the scheme://host origins seen in logs, most frequent
+// first. Logs without a scheme default to https, or http for loopback
+// hosts; fallbackHost is used when no log has a host.
+func ServerURLs(logs []types.TrafficLog, fallbackHost string) []string {
+	counts := map[string]int{}
+	var order []string
+	for _, l := range logs {
+		if l.Host == "" {
+			continue
+		}
+		u := originOf(l.Scheme, l.Host)
+		if counts[u] == 0 {
+			order = append(order, u)
+		}
+		counts[u]++
+	}
+	if len(order) == 0 {
+		if fallbackHost == "" || fallbackHost == "unknown" {
+			return nil
+		}
+		return []string{originOf("", fallbackHost)}
+	}
+	sort.SliceStable(order, func(i, j int) bool { return counts[order[i]] > counts[order[j]] })
+	return order
+}
+
+func originOf(scheme, host string) string {
+	if scheme == "" {
+		scheme = "https"
+		name := host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			name = h
+		}
+		if name == "localhost" || strings.HasPrefix(name, "127.") || name == "::1" {
+			scheme = "http"
+		}
+	}
+	return scheme + "://" + host
+}
+
+// ErrNoDoc is returned by LoadDoc when a session has no generated batches.
+var ErrNoDoc = errors.New("doc not found")
+
+// LoadDoc rebuilds a session's merged doc from its successful batch caches.
+// Auth, examples and data flow are attached from the session's traffic with
+// cfg's filter and sanitize rules, as generate does.
+func LoadDoc(st store.Store, sess *types.Session, cfg *config.Config) (*types.GeneratedDoc, error) {
+	caches, err := st.GetBatchCaches(sess.ID)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]*types.GeneratedDoc, 0, len(caches))
+	versions := map[string]bool{}
+	for _, cache := range caches {
+		if cache.Status != "ok" {
+			continue
+		}
+		doc, err := parseCachedDoc(cache)
+		if err != nil {
+			continue
+		}
+		if doc.Scenario == "" {
+			doc.Scenario = sess.Scenario
+		}
+		docs = append(docs, doc)
+		versions[cache.PromptVersion] = true
+	}
+	if len(docs) == 0 {
+		return nil, ErrNoDoc
+	}
+	merged := MergeDocs(docs)
+	// Only a single prompt version describes the merged doc.
+	if len(versions) == 1 {
+		for v := range versions {
+			merged.PromptVersion = v
+		}
+	}
+	logs, err := st.GetLogs(sess.ID)
+	if err != nil {
+		return nil, err
+	}
+	filtered := filter.Apply(logs, cfg.Filter)
+	AttachAuth(merged, filtered, cfg.Sanitize)
+	AttachExamples(merged, filtered, cfg.Sanitize)
+	AttachDataFlow(merged, filtered)
+	return merged, nil
+}
+
 func report(fn ProgressFunc, msg string) {
 	if fn != nil {
 		fn(msg)
@@ -155,7 +343,7 @@ func parseCachedDoc(cache types.LLMCache) (*types.GeneratedDoc, error) {
 	return &doc, nil
 }
 
-func callLLM(sess *types.Session, batch []types.TrafficLog, cfg LLMConfig) (*types.GeneratedDoc, string, error) {
+func callLLM(sess *types.Session, batch []types.TrafficLog, flows []dataflow.Flow, cfg LLMConfig, prompts *Prompts) (*types.GeneratedDoc, string, error) {
 	client := &Client{
 		BaseURL:     cfg.BaseURL,
 		APIKey:      cfg.APIKey,
@@ -163,8 +351,8 @@ func callLLM(sess *types.Session, batch []types.TrafficLog, cfg LLMConfig) (*typ
 		MaxTokens:   cfg.MaxTokens,
 		Temperature: cfg.Temperature,
 	}
-	system := BuildSystemPrompt()
-	user := BuildUserPrompt(sess.Scenario, batch)
+	system := prompts.System()
+	user := prompts.User(sess.Scenario, batch, flows)
 	content, err := client.Chat(system, user)
 	if err != nil {
 		return nil, "", err
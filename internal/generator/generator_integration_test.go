@@ -4,10 +4,13 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"path/filepath"
+	"strings"
 	"sync/atomic"
 	"testing"
 
+	"github.com/yourorg/apidoc/internal/config"
 	"github.com/yourorg/apidoc/internal/har"
 	"github.com/yourorg/apidoc/internal/store"
 	"github.com/yourorg/apidoc/pkg/types"
@@ -106,7 +109,7 @@ func TestGenerateResumeFailedBatch(t *testing.T) {
 	logs := []types.TrafficLog{{Method: "GET", Path: "/v1/users"}, {Method: "POST", Path: "/v1/login"}}
 
 	okDoc := `{"scenario":"sample","call_chain":[],"endpoints":[{"method":"GET","path":"/v1/users","summary":"list","description":"","responses":[{"status_code":200,"description":"ok"}]}]}`
-	if err := s.SaveBatchCache(&types.LLMCache{SessionID: sess.ID, BatchIndex: 0, BatchKey: "/v1/users", Status: "ok", RawOutput: okDoc, Model: "gpt-4o"}); err != nil {
+	if err := s.SaveBatchCache(&types.LLMCache{SessionID: sess.ID, BatchIndex: 0, BatchKey: "/v1/users", Status: "ok", RawOutput: okDoc, Model: "gpt-4o", PromptVersion: defaultPrompts.Version}); err != nil {
 		t.Fatalf("save ok cache: %v", err)
 	}
 	if err := s.SaveBatchCache(&types.LLMCache{SessionID: sess.ID, BatchIndex: 1, BatchKey: "/v1/login", Status: "failed", ErrorMsg: "boom", Model: "gpt-4o"}); err != nil {
@@ -130,3 +133,108 @@ func TestGenerateResumeFailedBatch(t *testing.T) {
 		t.Fatalf("expected 1 llm call for failed batch, got %d", hit)
 	}
 }
+
+func TestGenerateRedoesBatchesWhenPromptChanges(t *testing.T) {
+	s, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "apidoc.db"))
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	defer s.Close()
+	sess, err := s.CreateSession("har", "sample", "api.example.com")
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	logs := []types.TrafficLog{{Method: "GET", Path: "/v1/users"}}
+
+	var hit int32
+	var system string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hit, 1)
+		var req struct {
+			Messages []struct{ Content string } `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		system = req.Messages[0].Content
+		content := `{"scenario":"sample","endpoints":[{"method":"GET","path":"/v1/users","summary":"list","responses":[{"status_code":200,"description":"ok"}]}]}`
+		resp := map[string]interface{}{"choices": []map[string]interface{}{{"message": map[string]string{"content": content}}}}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{}
+	cfg.SetDefaults()
+	cfg.LLM = LLMConfig{BaseURL: srv.URL, Model: "gpt-4o", MaxTokens: 4096}
+	cfg.Output.Dir = t.TempDir()
+	doc, err := GenerateWithConfig(sess, logs, cfg, s, nil, false, true)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if doc.PromptVersion != defaultPrompts.Version {
+		t.Fatalf("doc prompt_version = %q", doc.PromptVersion)
+	}
+	var meta GenerationMeta
+	if data, err := os.ReadFile(filepath.Join(cfg.Output.Dir, "meta.json")); err != nil || json.Unmarshal(data, &meta) != nil || meta.PromptVersion != doc.PromptVersion || meta.SessionID != sess.ID {
+		t.Fatalf("meta.json should record the prompt version: %+v, %v", meta, err)
+	}
+
+	cfg.LLM.PromptDir = t.TempDir()
+	if err := os.WriteFile(filepath.Join(cfg.LLM.PromptDir, "system.txt"), []byte("custom system prompt"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	doc, err = GenerateWithConfig(sess, logs, cfg, s, nil, false, true)
+	if err != nil {
+		t.Fatalf("generate with custom prompts: %v", err)
+	}
+	if atomic.LoadInt32(&hit) != 2 || system != "custom system prompt" {
+		t.Fatalf("changed prompts should redo the cached batch: %d calls, system %q", hit, system)
+	}
+	caches, _ := s.GetBatchCaches(sess.ID)
+	if len(caches) != 1 || caches[0].PromptVersion != doc.PromptVersion || !strings.HasPrefix(doc.PromptVersion, "custom-") {
+		t.Fatalf("cache should record the new prompt version: %+v", caches)
+	}
+	if loaded, err := LoadDoc(s, sess, cfg); err != nil || loaded.PromptVersion != doc.PromptVersion {
+		t.Fatalf("LoadDoc prompt_version = %+v, %v", loaded, err)
+	}
+
+	if _, err := GenerateWithConfig(sess, logs, cfg, s, nil, false, true); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if atomic.LoadInt32(&hit) != 2 {
+		t.Fatalf("unchanged prompts should reuse the cache, got %d calls", hit)
+	}
+}
+
+func TestLoadDocUsesConfiguredSanitize(t *testing.T) {
+	s, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "apidoc.db"))
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	defer s.Close()
+	sess, err := s.CreateSession("har", "sample", "api.example.com")
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	logs := []types.TrafficLog{{Seq: 1, Method: "GET", Host: "api.example.com", Path: "/v1/users/7", StatusCode: 200,
+		ResponseContentType: "application/json", ResponseBody: `{"id":7,"ssn":"123-45-6789"}`}}
+	if err := s.SaveLogs(sess.ID, logs); err != nil {
+		t.Fatal(err)
+	}
+	raw := `{"scenario":"sample","endpoints":[{"method":"GET","path":"/v1/users/{id}","summary":"get","responses":[{"status_code":200,"description":"ok"}]}]}`
+	if err := s.SaveBatchCache(&types.LLMCache{SessionID: sess.ID, BatchIndex: 0, BatchKey: "/v1/users", Status: "ok", RawOutput: raw}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{}
+	cfg.SetDefaults()
+	cfg.Sanitize.BodyFields = append(cfg.Sanitize.BodyFields, "ssn")
+	doc, err := LoadDoc(s, sess, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Endpoints[0].Examples) != 1 {
+		t.Fatalf("expected a recorded example: %+v", doc.Endpoints[0])
+	}
+	if body := doc.Endpoints[0].Examples[0].ResponseBody; strings.Contains(body, "6789") || !strings.Contains(body, cfg.Sanitize.Replacement) {
+		t.Fatalf("configured body field not redacted: %s", body)
+	}
+}
@@ -0,0 +1,304 @@
+package generator
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+//go:embed templates/html
+var htmlFS embed.FS
+
+var htmlTemplates = template.Must(template.New("site").Funcs(template.FuncMap{
+	"pageEndpoints": func(p htmlPage, eps []*htmlEndpoint) htmlEndpointList {
+		return htmlEndpointList{Root: p.Root, Endpoints: eps}
+	},
+	"statusClass": func(code int) string { return fmt.Sprintf("%dxx", code/100) },
+}).ParseFS(htmlFS, "templates/html/*.tmpl"))
+
+// htmlSite is the data shared by every page of the static site.
+type htmlSite struct {
+	Scenario   string
+	Chain      []htmlChainStep
+	Sequence   string // Mermaid sources
+	Flow       string
+	Tags       []*htmlTag
+	Endpoints  []*htmlEndpoint
+	MermaidURL string // script diagrams.js loads, empty for none
+}
+
+type htmlChainStep struct {
+	Method      string
+	MethodClass string
+	Path        string
+	Description string
+	DependsOn   int
+	Slug        string // endpoint page, empty when the step matches none
+}
+
+type htmlTag struct {
+	Name      string
+	Slug      string
+	Endpoints []*htmlEndpoint
+}
+
+type htmlEndpoint struct {
+	types.Endpoint
+	MethodClass string
+	Slug        string
+	TagName     string
+	TagSlug     string
+	Auth        []string // one line per alternative
+	Examples    []htmlExample
+}
+
+type htmlExample struct {
+	Title    string
+	Request  template.HTML
+	Response template.HTML
+}
+
+// htmlPage is the data one page renders; Root leads back to the site root.
+type htmlPage struct {
+	Kind     string // index, tag or endpoint
+	Title    string
+	Root     string
+	Site     *htmlSite
+	Tag      *htmlTag
+	Endpoint *htmlEndpoint
+}
+
+type htmlEndpointList struct {
+	Root      string
+	Endpoints []*htmlEndpoint
+}
+
+type searchEntry struct {
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Summary string `json:"summary"`
+	URL     string `json:"url"`
+	Text    string `json:"text"`
+}
+
+// HTMLOptions controls RenderHTML.
+type HTMLOptions struct {
+	// MermaidURL is the Mermaid library the pages load to draw diagrams.
+	MermaidURL string
+}
+
+// RenderHTML renders doc as a self-contained static site under
+// outputDir/site: an index, one page per tag and per endpoint, and a
+// client-side search index. Pages link relatively, so the site works from
+// any static host or straight from disk.
+//
+// The site loads nothing from other origins by default: Mermaid is too
+// large to ship as an asset, so call chain diagrams stay as Mermaid source
+// unless opts.MermaidURL opts in to loading the library from there.
+func RenderHTML(doc *types.GeneratedDoc, outputDir string, servers []string, opts HTMLOptions) error {
+	if doc == nil {
+		return fmt.Errorf("doc is nil")
+	}
+	site := buildHTMLSite(doc)
+	site.Sequence = SequenceDiagram(doc, DiagramMermaid, serverName(servers))
+	site.Flow = FlowDiagram(doc)
+	site.MermaidURL = opts.MermaidURL
+	root := filepath.Join(outputDir, "site")
+	for _, dir := range []string{"assets", "tags", "endpoints"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			return err
+		}
+	}
+
+	write := func(rel string, page htmlPage) error {
+		buf := &bytes.Buffer{}
+		if err := htmlTemplates.ExecuteTemplate(buf, "layout", page); err != nil {
+			return fmt.Errorf("render %s: %w", rel, err)
+		}
+		return os.WriteFile(filepath.Join(root, rel), buf.Bytes(), 0o644)
+	}
+	if err := write("index.html", htmlPage{Kind: "index", Title: "Overview", Site: site}); err != nil {
+		return err
+	}
+	for _, tag := range site.Tags {
+		if err := write(filepath.Join("tags", tag.Slug+".html"), htmlPage{Kind: "tag", Title: tag.Name, Root: "../", Site: site, Tag: tag}); err != nil {
+			return err
+		}
+	}
+	for _, ep := range site.Endpoints {
+		page := htmlPage{Kind: "endpoint", Title: ep.Method + " " + ep.Path, Root: "../", Site: site, Endpoint: ep}
+		for _, tag := range site.Tags {
+			if tag.Slug == ep.TagSlug {
+				page.Tag = tag
+			}
+		}
+		if err := write(filepath.Join("endpoints", ep.Slug+".html"), page); err != nil {
+			return err
+		}
+	}
+
+	for _, asset := range []string{"style.css", "search.js", "diagrams.js"} {
+		data, err := htmlFS.ReadFile("templates/html/" + asset)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(root, "assets", asset), data, 0o644); err != nil {
+			return err
+		}
+	}
+	// A script rather than JSON: fetch() is blocked for file:// pages.
+	index, err := json.Marshal(searchIndex(site))
+	if err != nil {
+		return err
+	}
+	script := append([]byte("window.APIDOC_SEARCH_INDEX = "), index...)
+	script = append(script, ";\n"...)
+	return os.WriteFile(filepath.Join(root, "assets", "search-index.js"), script, 0o644)
+}
+
+func buildHTMLSite(doc *types.GeneratedDoc) *htmlSite {
+	site := &htmlSite{Scenario: doc.Scenario}
+	tags := map[string]*htmlTag{}
+	slugs := map[string]bool{}
+	tagSlugs := map[string]bool{}
+	for _, ep := range doc.Endpoints {
+		tagName := "default"
+		if len(ep.Tags) > 0 {
+			tagName = ep.Tags[0]
+		}
+		tag, ok := tags[tagName]
+		if !ok {
+			tag = &htmlTag{Name: tagName, Slug: uniqueSlug(htmlSlug(tagName), tagSlugs)}
+			tags[tagName] = tag
+			site.Tags = append(site.Tags, tag)
+		}
+		he := &htmlEndpoint{
+			Endpoint:    ep,
+			MethodClass: methodClass(ep.Method),
+			Slug:        uniqueSlug(htmlSlug(ep.Method+" "+ep.Path), slugs),
+			TagName:     tag.Name,
+			TagSlug:     tag.Slug,
+			Examples:    htmlExamples(ep),
+		}
+		for _, alt := range ep.Auth {
+			he.Auth = append(he.Auth, strings.TrimSpace(strings.TrimPrefix(renderAuth([][]types.AuthScheme{alt}), "- ")))
+		}
+		tag.Endpoints = append(tag.Endpoints, he)
+		site.Endpoints = append(site.Endpoints, he)
+	}
+	sort.SliceStable(site.Tags, func(i, j int) bool { return site.Tags[i].Name < site.Tags[j].Name })
+
+	for _, step := range doc.CallChain {
+		cs := htmlChainStep{Method: step.Method, MethodClass: methodClass(step.Method), Path: step.Path, Description: step.Description}
+		if step.DependsOn != nil {
+			cs.DependsOn = *step.DependsOn
+		}
+		if i, ok := endpointIndex(doc.Endpoints, types.TrafficLog{Method: step.Method, Path: step.Path}); ok {
+			cs.Slug = site.Endpoints[i].Slug
+		}
+		site.Chain = append(site.Chain, cs)
+	}
+	return site
+}
+
+// htmlExamples prefers recorded traffic and falls back to the LLM example.
+func htmlExamples(ep types.Endpoint) []htmlExample {
+	var out []htmlExample
+	for _, ex := range ep.Examples {
+		title := fmt.Sprintf("%d · seq %d", ex.StatusCode, ex.Seq)
+		if len(ex.Params) > 0 {
+			title += " · " + strings.Join(ex.Params, ", ")
+		}
+		out = append(out, htmlExample{Title: title, Request: highlight(ex.RequestBody), Response: highlight(ex.ResponseBody)})
+	}
+	if len(out) == 0 && ep.Example != nil && (ep.Example.Request != "" || ep.Example.Response != "") {
+		out = append(out, htmlExample{Title: "Example", Request: highlight(ep.Example.Request), Response: highlight(ep.Example.Response)})
+	}
+	return out
+}
+
+func searchIndex(site *htmlSite) []searchEntry {
+	out := make([]searchEntry, 0, len(site.Endpoints))
+	for _, ep := range site.Endpoints {
+		text := strings.Join(append([]string{ep.Method, ep.Path, ep.Summary, ep.Description}, ep.Tags...), " ")
+		out = append(out, searchEntry{
+			Method:  ep.Method,
+			Path:    ep.Path,
+			Summary: ep.Summary,
+			URL:     "endpoints/" + ep.Slug + ".html",
+			Text:    strings.ToLower(text),
+		})
+	}
+	return out
+}
+
+func methodClass(method string) string {
+	return "method-" + strings.ToLower(method)
+}
+
+var htmlSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+func htmlSlug(s string) string {
+	s = strings.Trim(htmlSlugChars.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if s == "" {
+		return "page"
+	}
+	return s
+}
+
+func uniqueSlug(s string, taken map[string]bool) string {
+	base := s
+	for n := 2; taken[s]; n++ {
+		s = fmt.Sprintf("%s-%d", base, n)
+	}
+	taken[s] = true
+	return s
+}
+
+// jsonToken matches the lexical pieces of pretty-printed JSON; a string
+// followed by a colon is a key.
+var jsonToken = regexp.MustCompile(`("(?:[^"\\]|\\.)*")(\s*:)?|-?\d+(?:\.\d+)?(?:[eE][+-]?\d+)?|\btrue\b|\bfalse\b|\bnull\b`)
+
+// highlight pretty-prints a JSON body and wraps its tokens in spans for the
+// site stylesheet. Other text is escaped as is.
+func highlight(body string) template.HTML {
+	if strings.TrimSpace(body) == "" {
+		return ""
+	}
+	pretty := prettyJSON(body)
+	if !json.Valid([]byte(pretty)) {
+		return template.HTML(template.HTMLEscapeString(body))
+	}
+	b := &strings.Builder{}
+	last := 0
+	for _, m := range jsonToken.FindAllStringSubmatchIndex(pretty, -1) {
+		b.WriteString(template.HTMLEscapeString(pretty[last:m[0]]))
+		tok := pretty[m[0]:m[1]]
+		class := "tok-literal"
+		switch {
+		case m[2] >= 0 && m[4] >= 0:
+			class = "tok-key"
+			tok = pretty[m[2]:m[3]]
+		case m[2] >= 0:
+			class = "tok-string"
+		case tok != "true" && tok != "false" && tok != "null":
+			class = "tok-number"
+		}
+		fmt.Fprintf(b, `<span class="%s">%s</span>`, class, template.HTMLEscapeString(tok))
+		if class == "tok-key" {
+			b.WriteString(template.HTMLEscapeString(pretty[m[3]:m[1]]))
+		}
+		last = m[1]
+	}
+	b.WriteString(template.HTMLEscapeString(pretty[last:]))
+	return template.HTML(b.String())
+}
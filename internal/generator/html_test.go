@@ -0,0 +1,88 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+func TestRenderHTML(t *testing.T) {
+	first := 1
+	doc := &types.GeneratedDoc{
+		Scenario: "shop",
+		CallChain: []types.ChainStep{
+			{Seq: 1, Method: "POST", Path: "/login"},
+			{Seq: 2, Method: "GET", Path: "/users/{id}", DependsOn: &first},
+		},
+		Endpoints: []types.Endpoint{
+			{
+				Method: "GET", Path: "/users/{id}", Summary: "Get user", Tags: []string{"Users"},
+				PathParams: []types.Param{{Name: "id", Type: "integer", Required: true}},
+				Responses: []types.Response{{StatusCode: 200, ContentType: "application/json", Fields: []types.Param{
+					{Name: "profile", Type: "object", Children: []types.Param{{Name: "bio", Type: "string", Description: "<b>about</b>"}}},
+				}}},
+				Examples: []types.RecordedExample{{Name: "200", Seq: 2, StatusCode: 200, ResponseBody: `{"profile":{"bio":"hi"},"age":3}`}},
+			},
+			{Method: "POST", Path: "/login", Summary: "Log in", Responses: []types.Response{{StatusCode: 200}}},
+		},
+	}
+	outDir := t.TempDir()
+	if err := RenderHTML(doc, outDir, nil, HTMLOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	site := filepath.Join(outDir, "site")
+	read := func(rel string) string {
+		data, err := os.ReadFile(filepath.Join(site, rel))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	index := read("index.html")
+	if !strings.Contains(index, `href="endpoints/get-users-id.html"`) || !strings.Contains(index, `href="tags/users.html"`) {
+		t.Fatalf("index missing links:\n%s", index)
+	}
+	if !strings.Contains(index, "depends on step 1") {
+		t.Fatalf("index missing call chain dependency")
+	}
+	if tag := read("tags/default.html"); !strings.Contains(tag, "/login") {
+		t.Fatalf("untagged endpoints should land in the default tag")
+	}
+
+	page := read("endpoints/get-users-id.html")
+	for _, want := range []string{
+		`href="../assets/style.css"`,
+		`<details class="nested">`,
+		`&lt;b&gt;about&lt;/b&gt;`,
+		`<span class="tok-key">&#34;profile&#34;</span>`,
+		`<span class="tok-number">3</span>`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("endpoint page missing %q:\n%s", want, page)
+		}
+	}
+
+	if idx := read("assets/search-index.js"); !strings.Contains(idx, `"url":"endpoints/post-login.html"`) {
+		t.Fatalf("search index missing entry: %s", idx)
+	}
+	for _, asset := range []string{"assets/style.css", "assets/search.js"} {
+		if read(asset) == "" {
+			t.Fatalf("%s is empty", asset)
+		}
+	}
+	if strings.Contains(index, "https://") || strings.Contains(read("assets/diagrams.js"), "https://") {
+		t.Fatalf("the site should not load external scripts by default")
+	}
+
+	const mermaid = "https://cdn.example.com/mermaid.min.js"
+	if err := RenderHTML(doc, outDir, nil, HTMLOptions{MermaidURL: mermaid}); err != nil {
+		t.Fatal(err)
+	}
+	if index := read("index.html"); !strings.Contains(index, `data-mermaid="`+mermaid+`"`) {
+		t.Fatalf("index should opt in to the Mermaid script:\n%s", index)
+	}
+}
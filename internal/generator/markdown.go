@@ -0,0 +1,223 @@
+package generator
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+//go:embed templates/markdown
+var markdownFS embed.FS
+
+// markdownFuncs are the helpers available to Markdown templates, built-in
+// or from output.templates_dir.
+var markdownFuncs = template.FuncMap{
+	"join":       strings.Join,
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+	"authList":   renderAuth,
+	"paramList":  paramList,
+	"paramTable": paramTable,
+	"json":       prettyJSON,
+	"codeBlock":  codeBlock,
+	"curl":       curlCommand,
+}
+
+// markdownReadme is the data readme.md.tmpl renders.
+type markdownReadme struct {
+	*types.GeneratedDoc
+	Diagrams []markdownDiagram
+	BaseURL  string
+}
+
+type markdownDiagram struct {
+	Format   string // mermaid or plantuml
+	Sequence string
+	Flow     string // Mermaid dependency flowchart, mermaid only
+}
+
+// markdownEndpoint is the data endpoint.md.tmpl renders.
+type markdownEndpoint struct {
+	types.Endpoint
+	BaseURL string
+}
+
+// markdownAPIDocs is the data api-docs.md.tmpl renders.
+type markdownAPIDocs struct {
+	*types.GeneratedDoc
+	Endpoints []markdownEndpoint
+	BaseURL   string
+}
+
+// markdownTemplates parses the built-in templates, then any *.tmpl files in
+// dir, which replace built-ins of the same name or add partials.
+func markdownTemplates(dir string) (*template.Template, error) {
+	t, err := template.New("markdown").Funcs(markdownFuncs).ParseFS(markdownFS, "templates/markdown/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		return t, nil
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("output.templates_dir %q is not a directory", dir)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil || len(files) == 0 {
+		return t, err
+	}
+	if t, err = t.ParseFiles(files...); err != nil {
+		return nil, fmt.Errorf("parse templates in %s: %w", dir, err)
+	}
+	return t, nil
+}
+
+func executeTemplate(t *template.Template, name string, data any) (string, error) {
+	b := &strings.Builder{}
+	if err := t.ExecuteTemplate(b, name, data); err != nil {
+		return "", fmt.Errorf("render %s: %w", name, err)
+	}
+	return b.String(), nil
+}
+
+// paramList renders params as a nested bullet list, optionally indented.
+func paramList(params []types.Param, indent ...string) string {
+	return renderParams(params, strings.Join(indent, ""))
+}
+
+// paramTable renders params as a Markdown table; nested fields are named by
+// dot path and array elements by "[]", e.g. items[].sku.
+func paramTable(params []types.Param) string {
+	if len(params) == 0 {
+		return ""
+	}
+	b := &strings.Builder{}
+	b.WriteString("| Name | Type | Required | Description |\n| --- | --- | --- | --- |\n")
+	var walk func(params []types.Param, prefix string)
+	walk = func(params []types.Param, prefix string) {
+		for _, p := range params {
+			name := prefix + p.Name
+			required := "no"
+			if p.Required {
+				required = "yes"
+			}
+			fmt.Fprintf(b, "| `%s` | %s | %s | %s |\n", name, tableCell(p.Type), required, tableCell(p.Description))
+			if len(p.Children) > 0 {
+				if typeName, _ := inferType(p.Type); typeName == "array" {
+					name += "[]"
+				}
+				walk(p.Children, name+".")
+			}
+		}
+	}
+	walk(params, "")
+	return b.String()
+}
+
+// tableCell keeps text on one row of a Markdown table.
+func tableCell(s string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(s), " "), "|", `\|`)
+}
+
+// codeBlock fences body, pretty-printed and tagged as JSON when it parses.
+func codeBlock(body string) string {
+	lang, text := "", strings.TrimRight(body, "\n")
+	if pretty := prettyJSON(body); json.Valid([]byte(pretty)) {
+		lang, text = "json", pretty
+	}
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + text + "\n" + fence
+}
+
+// curlCommand builds a curl call to ep, preferring the first recorded
+// example's path, query and body. Credentials are placeholders.
+func curlCommand(baseURL string, ep types.Endpoint) string {
+	if baseURL == "" {
+		baseURL = "https://api.example.com"
+	}
+	path, query := ep.Path, url.Values{}
+	body, contentType := "", ""
+	if ep.RequestBody != nil {
+		contentType = ep.RequestBody.ContentType
+	}
+	if len(ep.Examples) > 0 {
+		ex := ep.Examples[0]
+		path, body = ex.Path, ex.RequestBody
+		for k, vs := range ex.Query {
+			query[k] = vs
+		}
+		if ex.RequestContentType != "" {
+			contentType = ex.RequestContentType
+		}
+	} else if ep.Example != nil && ep.Example.Request != "" {
+		body = ep.Example.Request
+	} else if ep.RequestBody != nil && len(ep.RequestBody.Fields) > 0 && strings.Contains(contentType, "json") {
+		data, _ := json.Marshal(ExampleObject(ep.RequestBody.Fields))
+		body = string(data)
+	}
+
+	head := "curl"
+	if method := strings.ToUpper(ep.Method); method != "GET" || body != "" {
+		head += " -X " + method
+	}
+	var args []string
+	placeholders := map[string]bool{} // query keys whose value stays unescaped
+	if len(ep.Auth) > 0 {
+		for _, a := range ep.Auth[0] {
+			switch {
+			case a.Type == types.AuthBearer:
+				args = append(args, "-H "+shellQuote("Authorization: Bearer <token>"))
+			case a.Type == types.AuthBasic:
+				args = append(args, "-u "+shellQuote("<username>:<password>"))
+			case a.Type == types.AuthSessionCookie || a.In == "cookie":
+				args = append(args, "-b "+shellQuote(a.Name+"=<"+a.Name+">"))
+			case a.In == "query":
+				placeholders[a.Name] = true
+				query.Set(a.Name, "<"+a.Name+">")
+			default:
+				args = append(args, "-H "+shellQuote(a.Name+": <"+a.Name+">"))
+			}
+		}
+	}
+	target := strings.TrimRight(baseURL, "/") + path
+	if len(query) > 0 {
+		keys := make([]string, 0, len(query))
+		for k := range query {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var pairs []string
+		for _, k := range keys {
+			for _, v := range query[k] {
+				if !placeholders[k] {
+					v = url.QueryEscape(v)
+				}
+				pairs = append(pairs, url.QueryEscape(k)+"="+v)
+			}
+		}
+		target += "?" + strings.Join(pairs, "&")
+	}
+	head += " " + shellQuote(target)
+	if body != "" {
+		if contentType != "" {
+			args = append(args, "-H "+shellQuote("Content-Type: "+contentType))
+		}
+		args = append(args, "-d "+shellQuote(body))
+	}
+	return strings.Join(append([]string{head}, args...), " \\\n  ")
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
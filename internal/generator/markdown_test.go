@@ -0,0 +1,137 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+func TestWriteMarkdownTemplateOverride(t *testing.T) {
+	tmplDir := t.TempDir()
+	endpoint := `
+### {{.Summary}}
+{{paramTable .QueryParams}}
+` + "```bash\n{{curl .BaseURL .Endpoint}}\n```" + `
+{{range .Examples}}{{json .ResponseBody}}{{end}}
+`
+	if err := os.WriteFile(filepath.Join(tmplDir, "endpoint.md.tmpl"), []byte(endpoint), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	doc := &types.GeneratedDoc{
+		Scenario: "orders",
+		Endpoints: []types.Endpoint{{
+			Method: "GET", Path: "/orders", Summary: "查询订单",
+			QueryParams: []types.Param{{Name: "page", Type: "integer", Description: "页码"}},
+			Examples:    []types.RecordedExample{{Path: "/orders", Query: map[string][]string{"page": {"2"}}, ResponseBody: `{"total":1}`}},
+		}},
+	}
+	outDir := t.TempDir()
+	if err := WriteMarkdown(doc, outDir, MarkdownOptions{BaseURL: "https://shop.example.com", TemplatesDir: tmplDir}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(outDir, "api-docs.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := string(data)
+	for _, want := range []string{
+		"# API Docs\n\n### 查询订单\n",
+		"| `page` | integer | no | 页码 |",
+		"curl 'https://shop.example.com/orders?page=2'",
+		"{\n  \"total\": 1\n}",
+	} {
+		if !strings.Contains(md, want) {
+			t.Fatalf("api-docs.md missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "**Summary:**") {
+		t.Fatalf("built-in endpoint template should be replaced:\n%s", md)
+	}
+
+	if err := WriteMarkdown(doc, outDir, MarkdownOptions{TemplatesDir: filepath.Join(tmplDir, "missing")}); err == nil {
+		t.Fatal("expected error for a missing templates dir")
+	}
+}
+
+func TestParamTable(t *testing.T) {
+	got := paramTable([]types.Param{
+		{Name: "items", Type: "array", Required: true, Children: []types.Param{
+			{Name: "sku", Type: "string", Required: true, Description: "a | b"},
+		}},
+		{Name: "owner", Type: "object", Children: []types.Param{{Name: "id", Type: "integer"}}},
+	})
+	for _, want := range []string{
+		"| `items` | array | yes |  |",
+		"| `items[].sku` | string | yes | a \\| b |",
+		"| `owner.id` | integer | no |  |",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("table missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestCurlCommand(t *testing.T) {
+	ep := types.Endpoint{
+		Method: "POST", Path: "/orders",
+		RequestBody: &types.BodySchema{ContentType: "application/json", Fields: []types.Param{{Name: "sku", Type: "string"}}},
+		Auth:        [][]types.AuthScheme{{{Type: types.AuthBearer}, {Type: types.AuthAPIKey, In: "query", Name: "key"}}},
+	}
+	got := curlCommand("https://shop.example.com/", ep)
+	want := "curl -X POST 'https://shop.example.com/orders?key=<key>' \\\n" +
+		"  -H 'Authorization: Bearer <token>' \\\n" +
+		"  -H 'Content-Type: application/json' \\\n" +
+		"  -d '{\"sku\":\"string\"}'"
+	if got != want {
+		t.Fatalf("curl =\n%s\nwant\n%s", got, want)
+	}
+
+	ep.Examples = []types.RecordedExample{{Path: "/orders", RequestContentType: "application/json", RequestBody: `{"note":"it's"}`}}
+	if got := curlCommand("", ep); !strings.Contains(got, `-d '{"note":"it'\''s"}'`) {
+		t.Fatalf("recorded body should be shell-quoted:\n%s", got)
+	}
+}
+
+func TestWriteMarkdownEndpointDetails(t *testing.T) {
+	doc := &types.GeneratedDoc{
+		Scenario: "orders",
+		Endpoints: []types.Endpoint{
+			{
+				Method: "POST", Path: "/orders",
+				RequestBody: &types.BodySchema{ContentType: "application/json", Fields: []types.Param{
+					{Name: "items", Type: "array", Required: true, Children: []types.Param{{Name: "id", Type: "string", Required: true}}},
+				}},
+				Responses: []types.Response{{StatusCode: 201, ContentType: "application/json", Description: "created"}},
+				Examples: []types.RecordedExample{{
+					Name: "201", StatusCode: 201, Path: "/orders",
+					RequestContentType: "application/json", RequestBody: `{"items":[{"id":"a1"}]}`,
+					ResponseBody: `{"id":"o1"}`,
+				}},
+			},
+			{Method: "GET", Path: "/health", Example: &types.Example{Response: "ok"}},
+		},
+	}
+	outDir := t.TempDir()
+	if err := WriteMarkdown(doc, outDir, MarkdownOptions{BaseURL: "https://shop.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(outDir, "api-docs.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := string(data)
+	for _, want := range []string{
+		"### Request Body (application/json)\n\n| Name | Type | Required | Description |",
+		"| `items[].id` | string | yes |  |",
+		"```bash\ncurl -X POST 'https://shop.example.com/orders' \\\n  -H 'Content-Type: application/json' \\\n  -d '{\"items\":[{\"id\":\"a1\"}]}'\n```",
+		"Response:\n\n```json\n{\n  \"id\": \"o1\"\n}\n```",
+		"```bash\ncurl 'https://shop.example.com/health'\n```\n\nResponse:\n\n```\nok\n```",
+	} {
+		if !strings.Contains(md, want) {
+			t.Fatalf("api-docs.md missing %q:\n%s", want, md)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package generator
+
+import (
+	"encoding/json"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// upgradeTo31 rewrites a 3.0 document built by BuildOpenAPI into its 3.1
+// form, which aligns schemas with JSON Schema 2020-12:
+//   - nullable: true becomes a type list such as [string, "null"]
+//   - media type examples move into the schema as an examples array
+//   - allOf wrappers that only carried a description become plain $refs
+func upgradeTo31(spec map[string]interface{}) {
+	spec["jsonSchemaDialect"] = "https://spec.openapis.org/oas/3.1/dialect/base"
+	upgradeNode(spec, false)
+}
+
+// upgradeNode rewrites v in place. named is set for maps keyed by user
+// names (properties, components), whose keys are not keywords.
+func upgradeNode(v interface{}, named bool) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			if !named && (k == "example" || k == "examples") {
+				continue // recorded data, not schema keywords
+			}
+			upgradeNode(child, !named && (k == "properties" || k == "schemas"))
+		}
+		if named {
+			return
+		}
+		if nullable, _ := t["nullable"].(bool); nullable {
+			if typ, ok := t["type"].(string); ok {
+				t["type"] = []interface{}{typ, "null"}
+			}
+			delete(t, "nullable")
+		}
+		if all, ok := t["allOf"].([]interface{}); ok && len(all) == 1 && len(t) == 2 {
+			if ref, ok := all[0].(map[string]interface{}); ok && ref["$ref"] != nil && t["description"] != nil {
+				delete(t, "allOf")
+				t["$ref"] = ref["$ref"]
+			}
+		}
+		if example, ok := t["example"]; ok {
+			if schema, ok := t["schema"].(map[string]interface{}); ok {
+				// Copy so a shared $ref map is not mutated for other users.
+				moved := make(map[string]interface{}, len(schema)+1)
+				for k, sv := range schema {
+					moved[k] = sv
+				}
+				moved["examples"] = []interface{}{example}
+				t["schema"] = moved
+				delete(t, "example")
+			}
+		}
+	case []interface{}:
+		for _, child := range t {
+			upgradeNode(child, false)
+		}
+	case []map[string]interface{}:
+		for _, child := range t {
+			upgradeNode(child, false)
+		}
+	}
+}
+
+// exampleData decodes a JSON example so it renders as structured data,
+// keeping other text as a string. Numbers keep their literal text, so IDs
+// beyond float64 precision are not rounded.
+func exampleData(s string) interface{} {
+	if !json.Valid([]byte(s)) {
+		return s
+	}
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v interface{}
+	if dec.Decode(&v) != nil {
+		return s
+	}
+	return exampleNumbers(v)
+}
+
+// exampleNumbers replaces the json.Numbers in v, which YAML would quote as
+// strings, with exampleNumbers.
+func exampleNumbers(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			t[k] = exampleNumbers(child)
+		}
+	case []interface{}:
+		for i, child := range t {
+			t[i] = exampleNumbers(child)
+		}
+	case json.Number:
+		return exampleNumber(t)
+	}
+	return v
+}
+
+// exampleNumber is a JSON number literal emitted verbatim as JSON or YAML.
+type exampleNumber string
+
+func (n exampleNumber) MarshalJSON() ([]byte, error) { return []byte(n), nil }
+
+func (n exampleNumber) MarshalYAML() (interface{}, error) {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: string(n)}, nil
+}
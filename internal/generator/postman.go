@@ -0,0 +1,553 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yourorg/apidoc/internal/dataflow"
+	"github.com/yourorg/apidoc/internal/pathmatch"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+const postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// postmanItem is a request or, when Items is set, a folder.
+type postmanItem struct {
+	Name     string            `json:"name"`
+	Items    []postmanItem     `json:"item,omitempty"`
+	Request  *postmanRequest   `json:"request,omitempty"`
+	Response []postmanResponse `json:"response,omitempty"`
+	Event    []postmanEvent    `json:"event,omitempty"`
+}
+
+type postmanRequest struct {
+	Method      string       `json:"method"`
+	Header      []postmanKV  `json:"header"`
+	URL         postmanURL   `json:"url"`
+	Body        *postmanBody `json:"body,omitempty"`
+	Auth        *postmanAuth `json:"auth,omitempty"`
+	Description string       `json:"description,omitempty"`
+}
+
+type postmanURL struct {
+	Raw      string      `json:"raw"`
+	Host     []string    `json:"host"`
+	Path     []string    `json:"path"`
+	Query    []postmanKV `json:"query,omitempty"`
+	Variable []postmanKV `json:"variable,omitempty"`
+}
+
+type postmanKV struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type postmanBody struct {
+	Mode    string                 `json:"mode"`
+	Raw     string                 `json:"raw"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+type postmanAuth struct {
+	Type   string      `json:"type"`
+	Bearer []postmanKV `json:"bearer,omitempty"`
+	Basic  []postmanKV `json:"basic,omitempty"`
+	APIKey []postmanKV `json:"apikey,omitempty"`
+}
+
+type postmanResponse struct {
+	Name            string          `json:"name"`
+	OriginalRequest *postmanRequest `json:"originalRequest,omitempty"`
+	Status          string          `json:"status,omitempty"`
+	Code            int             `json:"code"`
+	PreviewLanguage string          `json:"_postman_previewlanguage,omitempty"`
+	Header          []postmanKV     `json:"header"`
+	Body            string          `json:"body"`
+}
+
+type postmanEvent struct {
+	Listen string        `json:"listen"`
+	Script postmanScript `json:"script"`
+}
+
+type postmanScript struct {
+	Type string   `json:"type"`
+	Exec []string `json:"exec"`
+}
+
+// RenderPostman renders doc as a Postman Collection v2.1 to
+// outputDir/postman_collection.json. Requests are grouped in one folder per
+// tag and ordered by the call chain; IDs that later requests reuse are
+// captured into collection variables by test scripts.
+func RenderPostman(doc *types.GeneratedDoc, outputDir string, servers []string) error {
+	if doc == nil {
+		return fmt.Errorf("doc is nil")
+	}
+	collection := BuildPostman(doc, servers)
+	data, err := json.MarshalIndent(collection, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outputDir, "postman_collection.json"), append(data, '\n'), 0o644)
+}
+
+// BuildPostman builds the collection for doc as a generic map.
+func BuildPostman(doc *types.GeneratedDoc, servers []string) map[string]interface{} {
+	baseURL := "http://localhost"
+	if len(servers) > 0 {
+		baseURL = servers[0]
+	}
+	variables := []postmanKV{{Key: "baseUrl", Value: baseURL, Type: "string"}}
+	varSeen := map[string]bool{"baseUrl": true}
+	addVar := func(key, desc string) {
+		if !varSeen[key] {
+			varSeen[key] = true
+			variables = append(variables, postmanKV{Key: key, Value: "", Type: "string", Description: desc})
+		}
+	}
+
+	order := runOrder(doc, chainOrder(doc))
+	captures := chainCaptures(doc, order)
+
+	folders := map[string]*postmanItem{}
+	var folderOrder []string
+	var loose []postmanItem
+	for _, i := range order {
+		ep := doc.Endpoints[i]
+		item := postmanRequestItem(ep, captures[i], addVar)
+		if len(ep.Tags) == 0 {
+			loose = append(loose, item)
+			continue
+		}
+		f, ok := folders[ep.Tags[0]]
+		if !ok {
+			f = &postmanItem{Name: ep.Tags[0]}
+			folders[ep.Tags[0]] = f
+			folderOrder = append(folderOrder, ep.Tags[0])
+		}
+		f.Items = append(f.Items, item)
+	}
+	items := make([]postmanItem, 0, len(folderOrder)+len(loose))
+	for _, name := range folderOrder {
+		items = append(items, *folders[name])
+	}
+	items = append(items, loose...)
+
+	return map[string]interface{}{
+		"info": map[string]interface{}{
+			"name":   doc.Scenario,
+			"schema": postmanSchema,
+		},
+		"item":     items,
+		"variable": variables,
+	}
+}
+
+// chainOrder lists endpoint indexes in call chain order, followed by
+// endpoints the chain does not mention in document order.
+func chainOrder(doc *types.GeneratedDoc) []int {
+	steps := append([]types.ChainStep(nil), doc.CallChain...)
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].Seq < steps[j].Seq })
+	placed := make([]bool, len(doc.Endpoints))
+	order := make([]int, 0, len(doc.Endpoints))
+	for _, step := range steps {
+		i, ok := endpointIndex(doc.Endpoints, types.TrafficLog{Method: step.Method, Path: step.Path})
+		if ok && !placed[i] {
+			placed[i] = true
+			order = append(order, i)
+		}
+	}
+	for i := range doc.Endpoints {
+		if !placed[i] {
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+// runOrder regroups order the way the collection is emitted and run: tag
+// folders in order of first appearance, then untagged requests.
+func runOrder(doc *types.GeneratedDoc, order []int) []int {
+	groups := map[string][]int{}
+	var tags []string
+	var loose []int
+	for _, i := range order {
+		ep := doc.Endpoints[i]
+		if len(ep.Tags) == 0 {
+			loose = append(loose, i)
+			continue
+		}
+		if _, ok := groups[ep.Tags[0]]; !ok {
+			tags = append(tags, ep.Tags[0])
+		}
+		groups[ep.Tags[0]] = append(groups[ep.Tags[0]], i)
+	}
+	out := make([]int, 0, len(order))
+	for _, tag := range tags {
+		out = append(out, groups[tag]...)
+	}
+	return append(out, loose...)
+}
+
+// capture is a value one request reuses from an earlier response.
+type capture struct {
+	variable string
+	source   string // JS expression on the parsed response body
+}
+
+// chainPlan says which variables a request sets and which of its path and
+// query params read them.
+type chainPlan struct {
+	sets []capture
+	uses map[string]string // param name → variable
+}
+
+// chainCaptures turns the doc's data flow into collection variables: the
+// source request's test script sets them and the target request reads them
+// in its URL or, for bearer tokens, its auth. order is the run order from
+// runOrder; flows against it cannot be replayed and are dropped.
+func chainCaptures(doc *types.GeneratedDoc, order []int) map[int]*chainPlan {
+	plans := map[int]*chainPlan{}
+	plan := func(i int) *chainPlan {
+		if plans[i] == nil {
+			plans[i] = &chainPlan{uses: map[string]string{}}
+		}
+		return plans[i]
+	}
+	pos := make(map[int]int, len(order))
+	for p, i := range order {
+		pos[i] = p
+	}
+	for _, link := range dataLinks(doc) {
+		if pos[link.from] >= pos[link.to] {
+			continue
+		}
+		var variable string
+		switch {
+		case link.in == dataflow.InPath || link.in == dataflow.InQuery:
+			variable = chainVariable(doc.Endpoints[link.to].Path, link.param)
+		case link.in == dataflow.InHeader && strings.EqualFold(link.param, "Authorization"):
+			for _, a := range firstAuth(doc.Endpoints[link.to].Auth) {
+				if a.Type == types.AuthBearer {
+					variable = a.ID()
+				}
+			}
+		}
+		if variable == "" {
+			continue
+		}
+		p := plan(link.from)
+		known := false
+		for _, c := range p.sets {
+			if c.variable == variable {
+				known = true
+			}
+		}
+		if !known {
+			p.sets = append(p.sets, capture{variable: variable, source: jsExpr(jsonPathKeys(link.field))})
+		}
+		if link.in != dataflow.InHeader {
+			plan(link.to).uses[link.param] = variable
+		}
+	}
+	return plans
+}
+
+var jsIdent = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// jsExpr reads a response field from the parsed body variable json.
+func jsExpr(field []string) string {
+	b := &strings.Builder{}
+	b.WriteString("json")
+	for _, k := range field {
+		if strings.HasPrefix(k, "[") {
+			b.WriteString(k)
+			continue
+		}
+		b.WriteString(jsAccessor(k))
+	}
+	return b.String()
+}
+
+func jsAccessor(key string) string {
+	if jsIdent.MatchString(key) {
+		return "." + key
+	}
+	quoted, _ := json.Marshal(key)
+	return "[" + string(quoted) + "]"
+}
+
+// chainVariable names the collection variable for a param, qualifying bare
+// "id" with the resource: /users/{id} → userId.
+func chainVariable(path, param string) string {
+	if strings.EqualFold(param, "id") {
+		res := resourceName(path)
+		name := pascalCase(singular(res.plural)) + "Id"
+		return strings.ToLower(name[:1]) + name[1:]
+	}
+	return param
+}
+
+func postmanRequestItem(ep types.Endpoint, plan *chainPlan, addVar func(key, desc string)) postmanItem {
+	uses := map[string]string{}
+	if plan != nil {
+		uses = plan.uses
+	}
+	req := &postmanRequest{
+		Method:      strings.ToUpper(ep.Method),
+		Header:      []postmanKV{},
+		URL:         postmanURLFor(ep, uses, addVar),
+		Auth:        postmanAuthFor(ep.Auth, addVar),
+		Description: ep.Description,
+	}
+	if ep.RequestBody != nil {
+		req.Header = append(req.Header, postmanKV{Key: "Content-Type", Value: ep.RequestBody.ContentType})
+		req.Body = &postmanBody{Mode: "raw", Raw: requestBodyExample(ep)}
+		if strings.Contains(ep.RequestBody.ContentType, "json") {
+			req.Body.Options = map[string]interface{}{"raw": map[string]interface{}{"language": "json"}}
+		}
+	}
+	for _, a := range firstAuth(ep.Auth) {
+		if a.Type == types.AuthSessionCookie {
+			req.Header = append(req.Header, postmanKV{Key: "Cookie", Value: a.Name + "={{" + a.ID() + "}}"})
+		}
+	}
+
+	name := ep.Summary
+	if name == "" {
+		name = strings.ToUpper(ep.Method) + " " + ep.Path
+	}
+	item := postmanItem{Name: name, Request: req, Response: postmanResponses(ep, req)}
+
+	exec := []string{}
+	if status, ok := successStatus(ep); ok {
+		exec = append(exec, fmt.Sprintf("pm.test(\"status is %d\", function () {", status),
+			fmt.Sprintf("    pm.response.to.have.status(%d);", status), "});")
+	}
+	if plan != nil && len(plan.sets) > 0 {
+		exec = append(exec, "var json = pm.response.json();")
+		for _, c := range plan.sets {
+			exec = append(exec, fmt.Sprintf("pm.collectionVariables.set(%q, %s);", c.variable, c.source))
+		}
+	}
+	if len(exec) > 0 {
+		item.Event = []postmanEvent{{Listen: "test", Script: postmanScript{Type: "text/javascript", Exec: exec}}}
+	}
+	return item
+}
+
+func postmanURLFor(ep types.Endpoint, uses map[string]string, addVar func(key, desc string)) postmanURL {
+	u := postmanURL{Host: []string{"{{baseUrl}}"}, Path: []string{}}
+	recorded := recordedParamValues(ep)
+	for _, seg := range strings.Split(strings.Trim(ep.Path, "/"), "/") {
+		if seg == "" {
+			continue
+		}
+		if pathmatch.IsTemplate("/" + seg) {
+			name := strings.Trim(seg, "{}:")
+			u.Path = append(u.Path, ":"+name)
+			value := recorded[name]
+			if v, ok := uses[name]; ok {
+				addVar(v, "captured from an earlier response")
+				value = "{{" + v + "}}"
+			}
+			u.Variable = append(u.Variable, postmanKV{Key: name, Value: value, Description: paramDescription(ep.PathParams, name)})
+			continue
+		}
+		u.Path = append(u.Path, seg)
+	}
+	for _, p := range ep.QueryParams {
+		value := recorded[p.Name]
+		if v, ok := uses[p.Name]; ok {
+			addVar(v, "captured from an earlier response")
+			value = "{{" + v + "}}"
+		}
+		u.Query = append(u.Query, postmanKV{Key: p.Name, Value: value, Description: p.Description})
+	}
+	u.Raw = "{{baseUrl}}/" + strings.Join(u.Path, "/")
+	if len(u.Query) > 0 {
+		parts := make([]string, len(u.Query))
+		for i, q := range u.Query {
+			parts[i] = q.Key + "=" + q.Value
+		}
+		u.Raw += "?" + strings.Join(parts, "&")
+	}
+	return u
+}
+
+func paramDescription(params []types.Param, name string) string {
+	for _, p := range params {
+		if p.Name == name {
+			return p.Description
+		}
+	}
+	return ""
+}
+
+// firstAuth returns the first alternative that carries credentials.
+func firstAuth(alternatives [][]types.AuthScheme) []types.AuthScheme {
+	for _, req := range alternatives {
+		if len(req) > 0 {
+			return req
+		}
+	}
+	return nil
+}
+
+// postmanAuthFor maps the first bearer, basic or API key scheme to Postman
+// auth with its secret in a collection variable named after the scheme.
+// Session cookies are sent as a Cookie header instead.
+func postmanAuthFor(alternatives [][]types.AuthScheme, addVar func(key, desc string)) *postmanAuth {
+	if len(alternatives) == 0 {
+		return nil
+	}
+	for _, a := range firstAuth(alternatives) {
+		id := a.ID()
+		switch a.Type {
+		case types.AuthBearer:
+			addVar(id, describeAuth(a))
+			return &postmanAuth{Type: "bearer", Bearer: []postmanKV{{Key: "token", Value: "{{" + id + "}}", Type: "string"}}}
+		case types.AuthBasic:
+			addVar(id+"Username", "HTTP Basic username")
+			addVar(id+"Password", "HTTP Basic password")
+			return &postmanAuth{Type: "basic", Basic: []postmanKV{
+				{Key: "username", Value: "{{" + id + "Username}}", Type: "string"},
+				{Key: "password", Value: "{{" + id + "Password}}", Type: "string"},
+			}}
+		case types.AuthAPIKey:
+			addVar(id, describeAuth(a))
+			if a.In == "cookie" {
+				continue
+			}
+			return &postmanAuth{Type: "apikey", APIKey: []postmanKV{
+				{Key: "key", Value: a.Name, Type: "string"},
+				{Key: "value", Value: "{{" + id + "}}", Type: "string"},
+				{Key: "in", Value: a.In, Type: "string"},
+			}}
+		case types.AuthSessionCookie:
+			addVar(id, describeAuth(a))
+		}
+	}
+	return &postmanAuth{Type: "noauth"}
+}
+
+// requestBodyExample prefers a recorded body, then the LLM example, then a
+// placeholder built from the documented fields.
+func requestBodyExample(ep types.Endpoint) string {
+	for _, ex := range ep.Examples {
+		if ex.RequestBody != "" {
+			return prettyJSON(ex.RequestBody)
+		}
+	}
+	if ep.Example != nil && ep.Example.Request != "" {
+		return prettyJSON(ep.Example.Request)
+	}
+	data, _ := json.MarshalIndent(ExampleObject(ep.RequestBody.Fields), "", "  ")
+	return string(data)
+}
+
+func postmanResponses(ep types.Endpoint, req *postmanRequest) []postmanResponse {
+	var out []postmanResponse
+	for _, ex := range ep.Examples {
+		if ex.ResponseBody == "" {
+			continue
+		}
+		out = append(out, postmanResponse{
+			Name:            fmt.Sprintf("%d (seq %d)", ex.StatusCode, ex.Seq),
+			OriginalRequest: req,
+			Code:            ex.StatusCode,
+			Status:          http.StatusText(ex.StatusCode),
+			PreviewLanguage: previewLanguage(ex.ResponseContentType, ex.ResponseBody),
+			Header:          contentTypeHeader(ex.ResponseContentType),
+			Body:            prettyJSON(ex.ResponseBody),
+		})
+	}
+	if len(out) == 0 && ep.Example != nil && ep.Example.Response != "" {
+		if status, ok := successStatus(ep); ok {
+			out = append(out, postmanResponse{
+				Name:            fmt.Sprintf("%d", status),
+				OriginalRequest: req,
+				Code:            status,
+				Status:          http.StatusText(status),
+				PreviewLanguage: previewLanguage("", ep.Example.Response),
+				Header:          []postmanKV{},
+				Body:            prettyJSON(ep.Example.Response),
+			})
+		}
+	}
+	return out
+}
+
+// successStatus is the first documented 2xx status.
+func successStatus(ep types.Endpoint) (int, bool) {
+	for _, resp := range ep.Responses {
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return resp.StatusCode, true
+		}
+	}
+	return 0, false
+}
+
+func contentTypeHeader(ct string) []postmanKV {
+	if ct == "" {
+		return []postmanKV{}
+	}
+	return []postmanKV{{Key: "Content-Type", Value: ct}}
+}
+
+func previewLanguage(ct, body string) string {
+	if strings.Contains(ct, "json") || json.Valid([]byte(body)) {
+		return "json"
+	}
+	if strings.Contains(ct, "html") {
+		return "html"
+	}
+	if strings.Contains(ct, "xml") {
+		return "xml"
+	}
+	return "text"
+}
+
+// prettyJSON indents a JSON body as recorded, keeping its key order, number
+// literals and characters such as < and & unescaped. Other text is returned
+// unchanged.
+func prettyJSON(s string) string {
+	var buf bytes.Buffer
+	if json.Indent(&buf, bytes.TrimSpace([]byte(s)), "", "  ") != nil {
+		return s
+	}
+	return buf.String()
+}
+
+// recordedParamValues takes path and query values from the first recorded
+// call, so requests work before any variable is captured.
+func recordedParamValues(ep types.Endpoint) map[string]string {
+	out := map[string]string{}
+	if len(ep.Examples) == 0 {
+		return out
+	}
+	ex := ep.Examples[0]
+	if vals, ok := pathmatch.Match(ep.Path, ex.Path); ok {
+		for k, v := range vals {
+			out[k] = v
+		}
+	}
+	for k, vs := range ex.Query {
+		if len(vs) > 0 {
+			out[k] = vs[0]
+		}
+	}
+	return out
+}
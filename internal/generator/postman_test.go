@@ -0,0 +1,162 @@
+package generator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+func TestRenderPostman(t *testing.T) {
+	doc := &types.GeneratedDoc{
+		Scenario: "orders",
+		CallChain: []types.ChainStep{
+			{Seq: 1, Method: "POST", Path: "/orders"},
+			{Seq: 2, Method: "GET", Path: "/orders/{id}"},
+		},
+		Endpoints: []types.Endpoint{
+			{
+				Method: "GET", Path: "/orders/{id}", Summary: "Get order", Tags: []string{"Orders"},
+				PathParams: []types.Param{{Name: "id", Type: "string", Required: true}},
+				Responses:  []types.Response{{StatusCode: 200, ContentType: "application/json"}},
+				Auth:       [][]types.AuthScheme{{{Type: types.AuthBearer}}},
+				Examples: []types.RecordedExample{{
+					Name: "200", Seq: 2, StatusCode: 200, Path: "/orders/ord_81",
+					ResponseContentType: "application/json", ResponseBody: `{"id":"ord_81","status":"paid"}`,
+				}},
+			},
+			{
+				Method: "POST", Path: "/orders", Summary: "Create order", Tags: []string{"Orders"},
+				RequestBody: &types.BodySchema{ContentType: "application/json", Fields: []types.Param{{Name: "sku", Type: "string"}}},
+				Responses:   []types.Response{{StatusCode: 201, ContentType: "application/json"}},
+				Auth:        [][]types.AuthScheme{{{Type: types.AuthBearer}}},
+				Examples: []types.RecordedExample{{
+					Name: "201", Seq: 1, StatusCode: 201, Path: "/orders",
+					RequestContentType: "application/json", RequestBody: `{"sku":"A1"}`,
+					ResponseContentType: "application/json", ResponseBody: `{"data":{"order_id":"ord_81"}}`,
+				}},
+			},
+			{Method: "GET", Path: "/health", Responses: []types.Response{{StatusCode: 200}}},
+		},
+		DataFlow: []types.DataFlow{
+			{FromMethod: "POST", FromPath: "/orders", Field: "$.data.order_id", ToMethod: "GET", ToPath: "/orders/{id}", In: "path", Param: "id"},
+			{FromMethod: "POST", FromPath: "/orders", Field: "$.data['access-token']", ToMethod: "GET", ToPath: "/orders/{id}", In: "header", Param: "Authorization"},
+			{FromMethod: "GET", FromPath: "/orders/{id}", Field: "$.id", ToMethod: "POST", ToPath: "/orders", In: "body", Param: "$.ref"},
+		},
+	}
+	outDir := t.TempDir()
+	if err := RenderPostman(doc, outDir, []string{"https://shop.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(outDir, "postman_collection.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c struct {
+		Info     struct{ Schema string }
+		Item     []postmanItem
+		Variable []postmanKV
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Info.Schema != postmanSchema {
+		t.Fatalf("schema = %q", c.Info.Schema)
+	}
+	if len(c.Item) != 2 || c.Item[0].Name != "Orders" || c.Item[1].Name != "GET /health" {
+		t.Fatalf("expected Orders folder then untagged request, got %+v", c.Item)
+	}
+	folder := c.Item[0].Items
+	if len(folder) != 2 || folder[0].Name != "Create order" || folder[1].Name != "Get order" {
+		t.Fatalf("requests not in call chain order: %+v", folder)
+	}
+
+	create, get := folder[0], folder[1]
+	script := strings.Join(create.Event[0].Script.Exec, "\n")
+	if !strings.Contains(script, `pm.collectionVariables.set("orderId", json.data.order_id);`) {
+		t.Fatalf("create should capture the order id, script:\n%s", script)
+	}
+	if !strings.Contains(script, `pm.collectionVariables.set("bearerAuth", json.data["access-token"]);`) {
+		t.Fatalf("create should capture the bearer token, script:\n%s", script)
+	}
+	if len(get.Event[0].Script.Exec) != 3 {
+		t.Fatalf("flows against collection order should be dropped: %v", get.Event[0].Script.Exec)
+	}
+	if !strings.Contains(script, "pm.response.to.have.status(201)") {
+		t.Fatalf("missing status test:\n%s", script)
+	}
+	if get.Request.URL.Raw != "{{baseUrl}}/orders/:id" || get.Request.URL.Variable[0].Value != "{{orderId}}" {
+		t.Fatalf("get should read the captured id: %+v", get.Request.URL)
+	}
+	if get.Request.Auth == nil || get.Request.Auth.Type != "bearer" || get.Request.Auth.Bearer[0].Value != "{{bearerAuth}}" {
+		t.Fatalf("unexpected auth: %+v", get.Request.Auth)
+	}
+	if len(get.Response) != 1 || get.Response[0].Code != 200 || !strings.Contains(get.Response[0].Body, `"status": "paid"`) {
+		t.Fatalf("unexpected example responses: %+v", get.Response)
+	}
+	vars := map[string]string{}
+	for _, v := range c.Variable {
+		vars[v.Key] = v.Value
+	}
+	if vars["baseUrl"] != "https://shop.example.com" {
+		t.Fatalf("baseUrl = %q", vars["baseUrl"])
+	}
+	for _, key := range []string{"bearerAuth", "orderId"} {
+		if _, ok := vars[key]; !ok {
+			t.Fatalf("missing collection variable %q in %v", key, vars)
+		}
+	}
+}
+
+func TestBuildPostmanDropsFlowsAgainstFolderOrder(t *testing.T) {
+	// The chain runs A1, B1, A2, but the collection runs the Accounts
+	// folder (A1, A2) before Billing (B1).
+	doc := &types.GeneratedDoc{
+		CallChain: []types.ChainStep{
+			{Seq: 1, Method: "POST", Path: "/accounts"},
+			{Seq: 2, Method: "POST", Path: "/invoices"},
+			{Seq: 3, Method: "GET", Path: "/accounts/{id}/invoices/{invoiceId}"},
+		},
+		Endpoints: []types.Endpoint{
+			{Method: "POST", Path: "/accounts", Tags: []string{"Accounts"}},
+			{Method: "POST", Path: "/invoices", Tags: []string{"Billing"}},
+			{
+				Method: "GET", Path: "/accounts/{id}/invoices/{invoiceId}", Tags: []string{"Accounts"},
+				PathParams: []types.Param{{Name: "id", Type: "string"}, {Name: "invoiceId", Type: "string"}},
+			},
+		},
+		DataFlow: []types.DataFlow{
+			{FromMethod: "POST", FromPath: "/accounts", Field: "$.id", ToMethod: "POST", ToPath: "/invoices", In: "query", Param: "account"},
+			{FromMethod: "POST", FromPath: "/accounts", Field: "$.id", ToMethod: "GET", ToPath: "/accounts/{id}/invoices/{invoiceId}", In: "path", Param: "id"},
+			{FromMethod: "POST", FromPath: "/invoices", Field: "$.id", ToMethod: "GET", ToPath: "/accounts/{id}/invoices/{invoiceId}", In: "path", Param: "invoiceId"},
+		},
+	}
+	data, err := json.Marshal(BuildPostman(doc, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c struct{ Item []postmanItem }
+	if err := json.Unmarshal(data, &c); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Item) != 2 || c.Item[0].Name != "Accounts" || c.Item[1].Name != "Billing" {
+		t.Fatalf("unexpected folders: %+v", c.Item)
+	}
+	create, get, invoice := c.Item[0].Items[0], c.Item[0].Items[1], c.Item[1].Items[0]
+	if len(invoice.Event) > 0 && strings.Contains(strings.Join(invoice.Event[0].Script.Exec, "\n"), "collectionVariables.set") {
+		t.Fatalf("invoice runs after the get and should not capture: %v", invoice.Event[0].Script.Exec)
+	}
+	vars := map[string]string{}
+	for _, v := range get.Request.URL.Variable {
+		vars[v.Key] = v.Value
+	}
+	if !strings.HasPrefix(vars["id"], "{{") || strings.HasPrefix(vars["invoiceId"], "{{") {
+		t.Fatalf("get should only read the account id: %+v", get.Request.URL.Variable)
+	}
+	if len(create.Event) == 0 || !strings.Contains(strings.Join(create.Event[0].Script.Exec, "\n"), "collectionVariables.set") {
+		t.Fatalf("create should capture the account id: %+v", create.Event)
+	}
+}
@@ -1,78 +1,129 @@
 package generator
 
 import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
 
+	"github.com/yourorg/apidoc/internal/dataflow"
 	"github.com/yourorg/apidoc/pkg/types"
 )
 
-const systemPrompt = `你是一个 API 文档专家。你会收到：
-1. 用户对操作场景的描述
-2. 一组按时间排序的 HTTP 请求/响应记录（来自真实流量采集）
-
-你的任务：
-1. 分析调用链路，理解每个 API 的作用和调用顺序
-2. 为每个独立的 API 端点生成文档：
-   - 路径、方法、功能描述、Tags 分组
-   - Path/Query/Body 参数说明（名称、类型、是否必填、含义）
-   - 响应字段说明（支持嵌套结构）
-   - 示例请求和响应（基于真实数据脱敏后）
-3. 生成场景调用链路图（哪个 API 先调、为什么、数据如何流转）
-4. 如果同一 API 被调用多次（不同参数），合并为一个端点，列出所有参数组合
-5. 输出严格按照指定 JSON schema，只输出 JSON，不要包裹在 markdown 代码块中
-
-类型推断规则：
-- UUID 格式字符串 → string (uuid)
-- ISO 8601 时间 → string (datetime)
-- 纯整数 → integer
-- 带小数 → number
-- true/false → boolean
-- 数组 → array，标注元素类型
-
-请用中文撰写所有描述性文字，字段名保持英文原样。
-只输出 JSON，不要 markdown 代码块。`
-
-const userPromptExample = `## 输出示例（仅供参考格式）
-{
-  "scenario": "查看用户列表",
-  "call_chain": [
-    {"seq": 1, "method": "GET", "path": "/api/v1/users", "description": "获取用户列表", "depends_on": null}
-  ],
-  "endpoints": [
-    {
-      "method": "GET",
-      "path": "/api/v1/users",
-      "summary": "获取用户列表",
-      "tags": ["用户管理"],
-      "description": "分页查询系统中的用户列表",
-      "query_params": [{"name": "page", "type": "integer", "required": false, "description": "页码"}],
-      "responses": [
-        {
-          "status_code": 200,
-          "content_type": "application/json",
-          "description": "成功返回用户列表",
-          "fields": [
-            {"name": "total", "type": "integer", "required": true, "description": "总数"},
-            {"name": "items", "type": "array", "required": true, "description": "用户数组", "children": [
-              {"name": "id", "type": "string (uuid)", "required": true, "description": "用户ID"}
-            ]}
-          ]
-        }
-      ]
-    }
-  ]
+//go:embed prompts
+var promptFS embed.FS
+
+// PromptVersion labels the built-in prompts in prompts/. Bump it along with
+// notable prompt changes; any edit changes Prompts.Version regardless.
+const PromptVersion = "v1"
+
+// Prompt files, built in or in llm.prompt_dir. system.txt and
+// user_example.txt are used verbatim; translate.tmpl is a text/template
+// given .Language.
+const (
+	systemPromptFile    = "system.txt"
+	userExampleFile     = "user_example.txt"
+	translatePromptFile = "translate.tmpl"
+)
+
+// Prompts are the prompt texts sent to the LLM.
+type Prompts struct {
+	// Version identifies the prompts in llm_cache and doc output: the
+	// label ("custom" when llm.prompt_dir overrides a file) plus a hash of
+	// the texts, so cached batches are redone when a prompt changes.
+	Version     string
+	system      string
+	userExample string
+	translate   *template.Template
+}
+
+// defaultPrompts are the built-in prompts.
+var defaultPrompts = func() *Prompts {
+	p, err := LoadPrompts("")
+	if err != nil {
+		panic(err)
+	}
+	return p
+}()
+
+// LoadPrompts loads the built-in prompts, replacing any of system.txt,
+// user_example.txt and translate.tmpl found in dir.
+func LoadPrompts(dir string) (*Prompts, error) {
+	if dir != "" {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("llm.prompt_dir %q is not a directory", dir)
+		}
+	}
+	label := PromptVersion
+	texts := map[string]string{}
+	hash := sha256.New()
+	for _, name := range []string{systemPromptFile, userExampleFile, translatePromptFile} {
+		data, err := promptFS.ReadFile("prompts/" + name)
+		if err != nil {
+			return nil, err
+		}
+		if dir != "" {
+			override, err := os.ReadFile(filepath.Join(dir, name))
+			switch {
+			case err == nil:
+				data, label = override, "custom"
+			case !errors.Is(err, os.ErrNotExist):
+				return nil, err
+			}
+		}
+		text := strings.TrimSpace(string(data))
+		if text == "" {
+			return nil, fmt.Errorf("prompt %s is empty", name)
+		}
+		texts[name] = text
+		fmt.Fprintf(hash, "%s\x00%s\x00", name, text)
+	}
+	translate, err := template.New(translatePromptFile).Option("missingkey=error").Parse(texts[translatePromptFile])
+	if err != nil {
+		return nil, fmt.Errorf("parse prompt %s: %w", translatePromptFile, err)
+	}
+	return &Prompts{
+		Version:     label + "-" + hex.EncodeToString(hash.Sum(nil))[:12],
+		system:      texts[systemPromptFile],
+		userExample: texts[userExampleFile],
+		translate:   translate,
+	}, nil
+}
+
+// System returns the system prompt.
+func (p *Prompts) System() string {
+	return p.system
 }
 
-请分析以上流量，生成该场景的完整 API 文档。`
+// Translate returns the system prompt for translating doc texts into the
+// named language.
+func (p *Prompts) Translate(language string) (string, error) {
+	b := &strings.Builder{}
+	if err := p.translate.Execute(b, map[string]string{"Language": language}); err != nil {
+		return "", fmt.Errorf("render prompt %s: %w", translatePromptFile, err)
+	}
+	return b.String(), nil
+}
 
-// BuildSystemPrompt returns the static system prompt.
+// BuildSystemPrompt returns the built-in system prompt.
 func BuildSystemPrompt() string {
-	return systemPrompt
+	return defaultPrompts.System()
+}
+
+// BuildUserPrompt is Prompts.User with the built-in prompts.
+func BuildUserPrompt(scenario string, logs []types.TrafficLog, flows []dataflow.Flow) string {
+	return defaultPrompts.User(scenario, logs, flows)
 }
 
-// BuildUserPrompt builds a user prompt with scenario and traffic records.
-func BuildUserPrompt(scenario string, logs []types.TrafficLog) string {
+// User builds a user prompt with scenario and traffic records, plus the
+// traced data flows that touch them, followed by the output example.
+func (p *Prompts) User(scenario string, logs []types.TrafficLog, flows []dataflow.Flow) string {
 	filtered := logs
 	if len(filtered) > 30 {
 		seen := make(map[string]struct{})
@@ -109,6 +160,15 @@ func BuildUserPrompt(scenario string, logs []types.TrafficLog) string {
 			"response_content_type": l.ResponseContentType,
 			"call_count":            l.CallCount,
 		}
+		if names := cookieNames(l.RequestCookies); len(names) > 0 {
+			rec["request_cookies"] = names
+		}
+		if names := cookieNames(l.ResponseCookies); len(names) > 0 {
+			rec["response_cookies"] = names
+		}
+		if len(l.FormParams) > 0 {
+			rec["form_params"] = l.FormParams
+		}
 		if l.CallCount > 1 {
 			rec["note"] = fmt.Sprintf("此 API 被调用了 %d 次", l.CallCount)
 		}
@@ -116,7 +176,42 @@ func BuildUserPrompt(scenario string, logs []types.TrafficLog) string {
 	}
 
 	b, _ := json.MarshalIndent(records, "", "  ")
-	return fmt.Sprintf("## 场景描述\n%s\n\n## API 调用记录（共 %d 条，按时间排序）\n%s\n\n%s", scenario, len(records), string(b), userPromptExample)
+	return fmt.Sprintf("## 场景描述\n%s\n\n## API 调用记录（共 %d 条，按时间排序）\n%s\n\n%s%s", scenario, len(records), string(b), flowSection(logs, flows), p.userExample)
+}
+
+// maxPromptFlows bounds the data flow lines added to one prompt.
+const maxPromptFlows = 50
+
+// flowSection lists the flows into or out of the given logs, one line each.
+func flowSection(logs []types.TrafficLog, flows []dataflow.Flow) string {
+	inBatch := make(map[int]bool, len(logs))
+	for _, l := range logs {
+		inBatch[l.Seq] = true
+	}
+	var lines []string
+	for _, f := range flows {
+		if !inBatch[f.FromSeq] && !inBatch[f.ToSeq] {
+			continue
+		}
+		if len(lines) == maxPromptFlows {
+			lines = append(lines, "...")
+			break
+		}
+		lines = append(lines, "- "+f.String())
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "## 数据流转（由流量自动追踪，请据此填写 call_chain 的 depends_on）\n" + strings.Join(lines, "\n") + "\n\n"
+}
+
+// cookieNames lists cookie names only; values are redacted and add no signal.
+func cookieNames(cookies []types.Cookie) []string {
+	names := make([]string, 0, len(cookies))
+	for _, c := range cookies {
+		names = append(names, c.Name)
+	}
+	return names
 }
 
 func truncateJSONBody(body string) (string, bool) {
@@ -1,9 +1,12 @@
 package generator
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/yourorg/apidoc/internal/dataflow"
 	"github.com/yourorg/apidoc/pkg/types"
 )
 
@@ -20,9 +23,53 @@ func TestBuildSystemPromptContainsRules(t *testing.T) {
 	}
 }
 
+func TestLoadPrompts(t *testing.T) {
+	builtin, err := LoadPrompts("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(builtin.Version, PromptVersion+"-") || builtin.System() != BuildSystemPrompt() {
+		t.Fatalf("built-in prompts = %q", builtin.Version)
+	}
+	if tr, err := builtin.Translate("English"); err != nil || !strings.Contains(tr, "翻译成 English") {
+		t.Fatalf("translate prompt = %q, %v", tr, err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "system.txt"), []byte("You document APIs.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	custom, err := LoadPrompts(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if custom.System() != "You document APIs." || !strings.HasPrefix(custom.Version, "custom-") {
+		t.Fatalf("override not applied: %q %q", custom.System(), custom.Version)
+	}
+	if !strings.Contains(custom.User("s", nil, nil), "## 输出示例") {
+		t.Fatal("files missing from prompt_dir should fall back to the built-ins")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "system.txt"), []byte("You document HTTP APIs."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if edited, err := LoadPrompts(dir); err != nil || edited.Version == custom.Version {
+		t.Fatalf("editing a prompt should change the version: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "translate.tmpl"), []byte("{{.Language"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPrompts(dir); err == nil {
+		t.Fatal("expected error for a broken translate.tmpl")
+	}
+	if _, err := LoadPrompts(filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("expected error for a missing prompt_dir")
+	}
+}
+
 func TestBuildUserPromptIncludesScenarioAndExample(t *testing.T) {
 	logs := []types.TrafficLog{{Method: "GET", Path: "/api/test"}}
-	prompt := BuildUserPrompt("login flow", logs)
+	prompt := BuildUserPrompt("login flow", logs, nil)
 	if !strings.Contains(prompt, "login flow") {
 		t.Fatalf("expected prompt to include scenario")
 	}
@@ -35,7 +82,7 @@ func TestBuildUserPromptBodyTruncation(t *testing.T) {
 	long := strings.Repeat("x", 2100)
 	body := `{"a":{"b":"` + long + `"},"c":1}`
 	logs := []types.TrafficLog{{Method: "POST", Path: "/api/large", RequestBody: body}}
-	prompt := BuildUserPrompt("big body", logs)
+	prompt := BuildUserPrompt("big body", logs, nil)
 	if strings.Contains(prompt, long) {
 		t.Fatalf("expected long nested body to be truncated")
 	}
@@ -49,7 +96,7 @@ func TestBuildUserPromptDedupByPath(t *testing.T) {
 	for i := 0; i < 31; i++ {
 		logs = append(logs, types.TrafficLog{Method: "GET", Path: "/api/dup"})
 	}
-	prompt := BuildUserPrompt("dedup", logs)
+	prompt := BuildUserPrompt("dedup", logs, nil)
 	if strings.Count(prompt, "/api/dup") != 1 {
 		t.Fatalf("expected path to appear once after dedup")
 	}
@@ -57,8 +104,34 @@ func TestBuildUserPromptDedupByPath(t *testing.T) {
 
 func TestBuildUserPromptCallCountNote(t *testing.T) {
 	logs := []types.TrafficLog{{Method: "GET", Path: "/api/call", CallCount: 3}}
-	prompt := BuildUserPrompt("note", logs)
+	prompt := BuildUserPrompt("note", logs, nil)
 	if !strings.Contains(prompt, "此 API 被调用了 3 次") {
 		t.Fatalf("expected call count note")
 	}
 }
+
+func TestBuildUserPromptCookieNames(t *testing.T) {
+	logs := []types.TrafficLog{{Method: "GET", Path: "/api/me", RequestCookies: []types.Cookie{{Name: "sid", Value: "***REDACTED***"}}}}
+	prompt := BuildUserPrompt("cookies", logs, nil)
+	if !strings.Contains(prompt, `"request_cookies"`) || !strings.Contains(prompt, `"sid"`) {
+		t.Fatalf("expected request cookie names in prompt")
+	}
+	if strings.Contains(prompt, "REDACTED") {
+		t.Fatalf("expected cookie values omitted from prompt")
+	}
+}
+
+func TestBuildUserPromptDataFlow(t *testing.T) {
+	logs := []types.TrafficLog{{Seq: 2, Method: "GET", Path: "/orders/ord_81"}}
+	flows := []dataflow.Flow{
+		{FromSeq: 1, FromMethod: "POST", FromPath: "/orders", Field: "$.id", ToSeq: 2, ToMethod: "GET", ToPath: "/orders/ord_81", In: dataflow.InPath, Segment: 1},
+		{FromSeq: 5, FromMethod: "POST", FromPath: "/carts", Field: "$.id", ToSeq: 6, ToMethod: "GET", ToPath: "/carts/c_1", In: dataflow.InPath, Segment: 1},
+	}
+	prompt := BuildUserPrompt("flow", logs, flows)
+	if !strings.Contains(prompt, "#1 POST /orders → $.id → #2 GET /orders/ord_81 (path)") {
+		t.Fatalf("expected traced flow in prompt:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "/carts") {
+		t.Fatalf("flows outside the batch should be left out")
+	}
+}
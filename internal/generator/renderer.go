@@ -1,6 +1,7 @@
 package generator
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -12,75 +13,120 @@ import (
 	"github.com/yourorg/apidoc/pkg/types"
 )
 
-// RenderMarkdown renders README.md and api-docs.md.
+// MarkdownOptions controls Markdown rendering.
+type MarkdownOptions struct {
+	// Diagrams lists the call chain diagram formats embedded in README.md:
+	// DiagramMermaid and DiagramPlantUML. PlantUML is also written to
+	// call-chain.puml.
+	Diagrams []string
+	// Server names the API host in sequence diagrams.
+	Server string
+	// BaseURL prefixes curl examples.
+	BaseURL string
+	// TemplatesDir holds *.tmpl files overriding the built-in
+	// readme.md.tmpl, api-docs.md.tmpl and endpoint.md.tmpl.
+	TemplatesDir string
+}
+
+// RenderMarkdown renders README.md and api-docs.md with Mermaid diagrams.
 func RenderMarkdown(doc *types.GeneratedDoc, outputDir string) error {
+	return WriteMarkdown(doc, outputDir, MarkdownOptions{Diagrams: []string{DiagramMermaid}})
+}
+
+// WriteMarkdown renders README.md and api-docs.md from templates.
+func WriteMarkdown(doc *types.GeneratedDoc, outputDir string, opts MarkdownOptions) error {
 	if doc == nil {
 		return fmt.Errorf("doc is nil")
 	}
+	tmpl, err := markdownTemplates(opts.TemplatesDir)
+	if err != nil {
+		return err
+	}
 	if err := os.MkdirAll(outputDir, 0o755); err != nil {
 		return err
 	}
 
-	readme := &strings.Builder{}
-	fmt.Fprintf(readme, "# %s\n\n", doc.Scenario)
-	fmt.Fprintln(readme, "## Call Chain")
-	for _, step := range doc.CallChain {
-		if step.DependsOn != nil {
-			fmt.Fprintf(readme, "- %d. %s %s — %s (depends on %d)\n", step.Seq, step.Method, step.Path, step.Description, *step.DependsOn)
-		} else {
-			fmt.Fprintf(readme, "- %d. %s %s — %s\n", step.Seq, step.Method, step.Path, step.Description)
+	readmeData := markdownReadme{GeneratedDoc: doc, BaseURL: opts.BaseURL}
+	var puml string
+	for _, format := range opts.Diagrams {
+		seq := SequenceDiagram(doc, format, opts.Server)
+		if seq == "" {
+			continue
+		}
+		d := markdownDiagram{Format: format, Sequence: seq}
+		if format == DiagramMermaid {
+			d.Flow = FlowDiagram(doc)
 		}
+		if format == DiagramPlantUML {
+			puml = seq
+		}
+		readmeData.Diagrams = append(readmeData.Diagrams, d)
+	}
+	readme, err := executeTemplate(tmpl, "readme.md.tmpl", readmeData)
+	if err != nil {
+		return err
 	}
 
-	apiDocs := &strings.Builder{}
-	fmt.Fprintln(apiDocs, "# API Docs")
+	docsData := markdownAPIDocs{GeneratedDoc: doc, BaseURL: opts.BaseURL}
 	for _, ep := range doc.Endpoints {
-		fmt.Fprintf(apiDocs, "\n## %s %s\n", ep.Method, ep.Path)
-		if ep.Summary != "" {
-			fmt.Fprintf(apiDocs, "**Summary:** %s\n\n", ep.Summary)
-		}
-		if ep.Description != "" {
-			fmt.Fprintf(apiDocs, "**Description:** %s\n\n", ep.Description)
-		}
-		if len(ep.Tags) > 0 {
-			fmt.Fprintf(apiDocs, "**Tags:** %s\n\n", strings.Join(ep.Tags, ", "))
-		}
-		if len(ep.PathParams) > 0 {
-			fmt.Fprintln(apiDocs, "### Path Parameters")
-			apiDocs.WriteString(renderParams(ep.PathParams, ""))
-			apiDocs.WriteString("\n")
-		}
-		if len(ep.QueryParams) > 0 {
-			fmt.Fprintln(apiDocs, "### Query Parameters")
-			apiDocs.WriteString(renderParams(ep.QueryParams, ""))
-			apiDocs.WriteString("\n")
-		}
-		if ep.RequestBody != nil {
-			fmt.Fprintf(apiDocs, "### Request Body (%s)\n", ep.RequestBody.ContentType)
-			apiDocs.WriteString(renderParams(ep.RequestBody.Fields, ""))
-			apiDocs.WriteString("\n")
-		}
-		if len(ep.Responses) > 0 {
-			fmt.Fprintln(apiDocs, "### Responses")
-			for _, resp := range ep.Responses {
-				fmt.Fprintf(apiDocs, "- %d (%s): %s\n", resp.StatusCode, resp.ContentType, resp.Description)
-				if len(resp.Fields) > 0 {
-					apiDocs.WriteString(renderParams(resp.Fields, "  "))
-				}
-			}
-			apiDocs.WriteString("\n")
-		}
+		docsData.Endpoints = append(docsData.Endpoints, markdownEndpoint{Endpoint: ep, BaseURL: opts.BaseURL})
+	}
+	apiDocs, err := executeTemplate(tmpl, "api-docs.md.tmpl", docsData)
+	if err != nil {
+		return err
 	}
 
-	if err := os.WriteFile(filepath.Join(outputDir, "README.md"), []byte(readme.String()), 0o644); err != nil {
+	if err := os.WriteFile(filepath.Join(outputDir, "README.md"), []byte(readme), 0o644); err != nil {
 		return err
 	}
-	if err := os.WriteFile(filepath.Join(outputDir, "api-docs.md"), []byte(apiDocs.String()), 0o644); err != nil {
+	if err := os.WriteFile(filepath.Join(outputDir, "api-docs.md"), []byte(apiDocs), 0o644); err != nil {
 		return err
 	}
+	if puml != "" {
+		if err := os.WriteFile(filepath.Join(outputDir, "call-chain.puml"), []byte(puml), 0o644); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// renderAuth lists each alternative on its own line; schemes sent together
+// are joined with "and".
+func renderAuth(alternatives [][]types.AuthScheme) string {
+	b := &strings.Builder{}
+	for i, req := range alternatives {
+		prefix := "- "
+		if i > 0 {
+			prefix = "- or "
+		}
+		if len(req) == 0 {
+			fmt.Fprintf(b, "%sno authentication\n", prefix)
+			continue
+		}
+		parts := make([]string, len(req))
+		for j, a := range req {
+			parts[j] = describeAuth(a)
+		}
+		fmt.Fprintf(b, "%s%s\n", prefix, strings.Join(parts, " and "))
+	}
+	return b.String()
+}
+
+func describeAuth(a types.AuthScheme) string {
+	switch a.Type {
+	case types.AuthBearer:
+		if a.Format != "" {
+			return fmt.Sprintf("Bearer token (%s) in `Authorization` header", a.Format)
+		}
+		return "Bearer token in `Authorization` header"
+	case types.AuthBasic:
+		return "HTTP Basic auth"
+	case types.AuthSessionCookie:
+		return fmt.Sprintf("Session cookie `%s`", a.Name)
+	}
+	return fmt.Sprintf("API key in %s `%s`", a.In, a.Name)
+}
+
 func renderParams(params []types.Param, indent string) string {
 	b := &strings.Builder{}
 	for _, p := range params {
@@ -96,22 +142,76 @@ func renderParams(params []types.Param, indent string) string {
 	return b.String()
 }
 
+// OpenAPIOptions controls OpenAPI rendering.
+type OpenAPIOptions struct {
+	// Version is "3.0" (the default) or "3.1".
+	Version string
+	// Servers are base URLs such as "https://api.example.com".
+	Servers []string
+	// JSON writes openapi.json instead of openapi.yaml.
+	JSON bool
+}
+
 // RenderOpenAPI renders OpenAPI 3.0 YAML to outputDir/openapi.yaml.
 func RenderOpenAPI(doc *types.GeneratedDoc, outputDir string) error {
-	if doc == nil {
-		return fmt.Errorf("doc is nil")
+	return WriteOpenAPI(doc, outputDir, OpenAPIOptions{})
+}
+
+// WriteOpenAPI renders doc to outputDir/openapi.yaml, or openapi.json when
+// opts.JSON is set.
+func WriteOpenAPI(doc *types.GeneratedDoc, outputDir string, opts OpenAPIOptions) error {
+	spec, err := BuildOpenAPI(doc, opts)
+	if err != nil {
+		return err
 	}
 	if err := os.MkdirAll(outputDir, 0o755); err != nil {
 		return err
 	}
+	if opts.JSON {
+		data, err := json.MarshalIndent(spec, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(outputDir, "openapi.json"), append(data, '\n'), 0o644)
+	}
+	data, err := yaml.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outputDir, "openapi.yaml"), data, 0o644)
+}
 
+// BuildOpenAPI builds the OpenAPI document for doc as a generic map.
+func BuildOpenAPI(doc *types.GeneratedDoc, opts OpenAPIOptions) (map[string]interface{}, error) {
+	if doc == nil {
+		return nil, fmt.Errorf("doc is nil")
+	}
+	version := opts.Version
+	if version == "" {
+		version = "3.0"
+	}
+	if version != "3.0" && version != "3.1" {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q", version)
+	}
+
+	info := map[string]interface{}{
+		"title":   doc.Scenario,
+		"version": "1.0.0",
+	}
+	if doc.PromptVersion != "" {
+		info["x-prompt-version"] = doc.PromptVersion
+	}
 	spec := map[string]interface{}{
-		"openapi": "3.0.0",
-		"info": map[string]interface{}{
-			"title":   doc.Scenario,
-			"version": "1.0.0",
-		},
-		"paths": map[string]interface{}{},
+		"openapi": version + ".0",
+		"info":    info,
+		"paths":   map[string]interface{}{},
+	}
+	if len(opts.Servers) > 0 {
+		servers := make([]map[string]interface{}, 0, len(opts.Servers))
+		for _, u := range opts.Servers {
+			servers = append(servers, map[string]interface{}{"url": u})
+		}
+		spec["servers"] = servers
 	}
 
 	// tags
@@ -135,6 +235,8 @@ func RenderOpenAPI(doc *types.GeneratedDoc, outputDir string) error {
 	}
 
 	paths := spec["paths"].(map[string]interface{})
+	securitySchemes := map[string]interface{}{}
+	registry := newSchemaRegistry(doc)
 	for _, ep := range doc.Endpoints {
 		method := strings.ToLower(ep.Method)
 		pathItem, ok := paths[ep.Path].(map[string]interface{})
@@ -164,46 +266,87 @@ func RenderOpenAPI(doc *types.GeneratedDoc, outputDir string) error {
 			op["parameters"] = params
 		}
 
+		// Examples sit on the media type: a $ref schema cannot carry siblings.
+		// Recorded traffic wins over the single LLM-written example.
 		if ep.RequestBody != nil {
-			schema := buildObjectSchema(ep.RequestBody.Fields)
-			if ep.Example != nil && ep.Example.Request != "" {
-				schema["example"] = ep.Example.Request
+			media := map[string]interface{}{"schema": registry.object(ep.RequestBody.Fields)}
+			if examples := requestExamples(ep.Examples); len(examples) > 0 {
+				media["examples"] = examples
+			} else if ep.Example != nil && ep.Example.Request != "" {
+				media["example"] = exampleData(ep.Example.Request)
 			}
 			op["requestBody"] = map[string]interface{}{
 				"content": map[string]interface{}{
-					ep.RequestBody.ContentType: map[string]interface{}{
-						"schema": schema,
-					},
+					ep.RequestBody.ContentType: media,
 				},
 			}
 		}
 
 		responses := map[string]interface{}{}
+		fallback := fallbackExampleStatus(ep)
 		for _, resp := range ep.Responses {
 			respObj := map[string]interface{}{"description": resp.Description}
 			if resp.ContentType != "" {
-				schema := buildObjectSchema(resp.Fields)
-				if ep.Example != nil && ep.Example.Response != "" {
-					schema["example"] = ep.Example.Response
+				media := map[string]interface{}{"schema": registry.object(resp.Fields)}
+				if examples := responseExamples(ep.Examples, resp.StatusCode); len(examples) > 0 {
+					media["examples"] = examples
+				} else if resp.StatusCode == fallback {
+					media["example"] = exampleData(ep.Example.Response)
 				}
 				respObj["content"] = map[string]interface{}{
-					resp.ContentType: map[string]interface{}{
-						"schema": schema,
-					},
+					resp.ContentType: media,
 				}
 			}
 			responses[fmt.Sprintf("%d", resp.StatusCode)] = respObj
 		}
 		op["responses"] = responses
 
+		if len(ep.Auth) > 0 {
+			security := make([]map[string]interface{}, 0, len(ep.Auth))
+			for _, req := range ep.Auth {
+				item := map[string]interface{}{}
+				for _, a := range req {
+					item[a.ID()] = []string{}
+					securitySchemes[a.ID()] = securityScheme(a)
+				}
+				security = append(security, item)
+			}
+			op["security"] = security
+		}
+
 		pathItem[method] = op
 	}
+	components := map[string]interface{}{}
+	if len(registry.schemas) > 0 {
+		components["schemas"] = registry.schemas
+	}
+	if len(securitySchemes) > 0 {
+		components["securitySchemes"] = securitySchemes
+	}
+	if len(components) > 0 {
+		spec["components"] = components
+	}
 
-	data, err := yaml.Marshal(spec)
-	if err != nil {
-		return err
+	if version == "3.1" {
+		upgradeTo31(spec)
 	}
-	return os.WriteFile(filepath.Join(outputDir, "openapi.yaml"), data, 0o644)
+	return spec, nil
+}
+
+func securityScheme(a types.AuthScheme) map[string]interface{} {
+	switch a.Type {
+	case types.AuthBearer:
+		scheme := map[string]interface{}{"type": "http", "scheme": "bearer"}
+		if a.Format != "" {
+			scheme["bearerFormat"] = a.Format
+		}
+		return scheme
+	case types.AuthBasic:
+		return map[string]interface{}{"type": "http", "scheme": "basic"}
+	case types.AuthSessionCookie:
+		return map[string]interface{}{"type": "apiKey", "in": "cookie", "name": a.Name, "description": "Session cookie"}
+	}
+	return map[string]interface{}{"type": "apiKey", "in": a.In, "name": a.Name}
 }
 
 func paramToOpenAPIParam(p types.Param, in string) map[string]interface{} {
@@ -226,6 +369,9 @@ func paramToSchema(p types.Param) map[string]interface{} {
 	if format != "" {
 		schema["format"] = format
 	}
+	if isNullable(p.Type) {
+		schema["nullable"] = true
+	}
 	if p.Description != "" {
 		schema["description"] = p.Description
 	}
@@ -262,6 +408,11 @@ func buildObjectSchema(fields []types.Param) map[string]interface{} {
 	return schema
 }
 
+// isNullable reports types such as "string | null" or "integer (nullable)".
+func isNullable(t string) bool {
+	return strings.Contains(strings.ToLower(t), "null")
+}
+
 func inferType(t string) (string, string) {
 	lt := strings.ToLower(t)
 	switch {
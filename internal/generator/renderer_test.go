@@ -1,8 +1,10 @@
 package generator
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"gopkg.in/yaml.v3"
@@ -98,3 +100,71 @@ func TestRenderOpenAPIAndValidate(t *testing.T) {
 		t.Fatalf("expected nested user.id schema")
 	}
 }
+
+func TestWriteOpenAPI31JSON(t *testing.T) {
+	doc := &types.GeneratedDoc{Scenario: "Test", Endpoints: []types.Endpoint{{
+		Method: "GET",
+		Path:   "/users/{id}",
+		Responses: []types.Response{{
+			StatusCode:  200,
+			ContentType: "application/json",
+			Fields:      []types.Param{{Name: "id", Type: "integer", Required: true}, {Name: "nickname", Type: "string|null"}},
+		}},
+		Example: &types.Example{Response: `{"id":1,"nickname":null}`},
+	}}, PromptVersion: "v1-abc"}
+	outDir := t.TempDir()
+	opts := OpenAPIOptions{Version: "3.1", Servers: []string{"https://api.example.com"}, JSON: true}
+	if err := WriteOpenAPI(doc, outDir, opts); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(outDir, "openapi.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec map[string]interface{}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatalf("openapi.json is not JSON: %v", err)
+	}
+	if spec["openapi"] != "3.1.0" {
+		t.Fatalf("openapi = %v, want 3.1.0", spec["openapi"])
+	}
+	if v := spec["info"].(map[string]interface{})["x-prompt-version"]; v != "v1-abc" {
+		t.Fatalf("info x-prompt-version = %v", v)
+	}
+	servers := spec["servers"].([]interface{})
+	if len(servers) != 1 || servers[0].(map[string]interface{})["url"] != "https://api.example.com" {
+		t.Fatalf("unexpected servers: %v", servers)
+	}
+	media := spec["paths"].(map[string]interface{})["/users/{id}"].(map[string]interface{})["get"].(map[string]interface{})["responses"].(map[string]interface{})["200"].(map[string]interface{})["content"].(map[string]interface{})["application/json"].(map[string]interface{})
+	if _, ok := media["example"]; ok {
+		t.Fatalf("3.1 example should move into schema examples")
+	}
+	schema := media["schema"].(map[string]interface{})
+	if examples, ok := schema["examples"].([]interface{}); !ok || len(examples) != 1 {
+		t.Fatalf("expected schema examples, got %v", schema["examples"])
+	}
+	nickname := schema["properties"].(map[string]interface{})["nickname"].(map[string]interface{})
+	if typ, ok := nickname["type"].([]interface{}); !ok || len(typ) != 2 || typ[1] != "null" {
+		t.Fatalf("expected nullable type list, got %v", nickname["type"])
+	}
+	if _, ok := nickname["nullable"]; ok {
+		t.Fatalf("nullable keyword must not appear in 3.1")
+	}
+}
+
+func TestServerURLs(t *testing.T) {
+	logs := []types.TrafficLog{
+		{Host: "api.example.com"},
+		{Scheme: "http", Host: "legacy.example.com"},
+		{Scheme: "https", Host: "api.example.com"},
+		{Host: "localhost:8080"},
+	}
+	got := ServerURLs(logs, "")
+	want := []string{"https://api.example.com", "http://legacy.example.com", "http://localhost:8080"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("ServerURLs = %v, want %v", got, want)
+	}
+	if got := ServerURLs(nil, "api.example.com"); len(got) != 1 || got[0] != "https://api.example.com" {
+		t.Fatalf("fallback = %v", got)
+	}
+}
@@ -0,0 +1,14 @@
+// Renders <pre class="mermaid"> blocks with the Mermaid library named by
+// output.mermaid_url; without it, or offline, the diagram source stays
+// readable as text.
+(function () {
+  var src = document.currentScript && document.currentScript.getAttribute('data-mermaid');
+  if (!src || !document.querySelector('pre.mermaid')) return;
+  var script = document.createElement('script');
+  script.src = src;
+  script.onload = function () {
+    window.mermaid.initialize({ startOnLoad: false });
+    window.mermaid.run({ querySelector: 'pre.mermaid' });
+  };
+  document.head.appendChild(script);
+})();
@@ -0,0 +1,33 @@
+(function () {
+  var input = document.getElementById('search');
+  var results = document.getElementById('search-results');
+  var index = window.APIDOC_SEARCH_INDEX || [];
+  var root = document.body.getAttribute('data-root') || '';
+  if (!input || !results) return;
+
+  input.addEventListener('input', function () {
+    var terms = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    results.innerHTML = '';
+    if (terms.length === 0) {
+      results.hidden = true;
+      return;
+    }
+    var hits = index.filter(function (e) {
+      return terms.every(function (t) { return e.text.indexOf(t) !== -1; });
+    }).slice(0, 20);
+    hits.forEach(function (e) {
+      var li = document.createElement('li');
+      var a = document.createElement('a');
+      a.href = root + e.url;
+      a.textContent = e.method + ' ' + e.path + (e.summary ? ' — ' + e.summary : '');
+      li.appendChild(a);
+      results.appendChild(li);
+    });
+    if (hits.length === 0) {
+      var empty = document.createElement('li');
+      empty.textContent = 'No matches';
+      results.appendChild(empty);
+    }
+    results.hidden = false;
+  });
+})();
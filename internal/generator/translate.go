@@ -0,0 +1,217 @@
+package generator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/yourorg/apidoc/internal/config"
+	"github.com/yourorg/apidoc/internal/store"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// SourceLanguage is the language the system prompt asks the LLM to write in.
+const SourceLanguage = "zh"
+
+// languages are the supported output.languages.
+var languages = []struct{ code, name string }{
+	{"zh", "简体中文"},
+	{"en", "English"},
+}
+
+// ErrNoTranslation is returned by LoadDocLang when a language was never
+// generated for a session.
+var ErrNoTranslation = errors.New("translation not found")
+
+// maxTranslateTexts bounds the strings sent in one translation request.
+const maxTranslateTexts = 100
+
+func languageName(lang string) (string, bool) {
+	for _, l := range languages {
+		if l.code == lang {
+			return l.name, true
+		}
+	}
+	return "", false
+}
+
+// mapDocText calls fn on each prose field of doc and stores the result:
+// the scenario, call chain descriptions, summaries, tags and descriptions.
+// Names, types, paths and examples are left alone.
+func mapDocText(doc *types.GeneratedDoc, fn func(string) string) {
+	doc.Scenario = fn(doc.Scenario)
+	for i := range doc.CallChain {
+		doc.CallChain[i].Description = fn(doc.CallChain[i].Description)
+	}
+	var params func([]types.Param)
+	params = func(ps []types.Param) {
+		for i := range ps {
+			ps[i].Description = fn(ps[i].Description)
+			params(ps[i].Children)
+		}
+	}
+	for i := range doc.Endpoints {
+		ep := &doc.Endpoints[i]
+		ep.Summary = fn(ep.Summary)
+		ep.Description = fn(ep.Description)
+		for j := range ep.Tags {
+			ep.Tags[j] = fn(ep.Tags[j])
+		}
+		params(ep.PathParams)
+		params(ep.QueryParams)
+		if ep.RequestBody != nil {
+			params(ep.RequestBody.Fields)
+		}
+		for j := range ep.Responses {
+			ep.Responses[j].Description = fn(ep.Responses[j].Description)
+			params(ep.Responses[j].Fields)
+		}
+	}
+}
+
+// docTexts lists the distinct non-empty prose strings of doc.
+func docTexts(doc *types.GeneratedDoc) []string {
+	seen := map[string]bool{}
+	var texts []string
+	mapDocText(doc, func(s string) string {
+		if s != "" && !seen[s] {
+			seen[s] = true
+			texts = append(texts, s)
+		}
+		return s
+	})
+	return texts
+}
+
+// ApplyTranslation returns a copy of doc with its prose replaced from dict.
+// Texts missing from dict keep the source language.
+func ApplyTranslation(doc *types.GeneratedDoc, dict map[string]string) (*types.GeneratedDoc, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var out types.GeneratedDoc
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	mapDocText(&out, func(s string) string {
+		if t, ok := dict[s]; ok && t != "" {
+			return t
+		}
+		return s
+	})
+	return &out, nil
+}
+
+// translateDoc translates doc's prose into lang. Translations cached for the
+// session with the same prompts are reused, so only new texts go to the LLM;
+// the cache is then rewritten with exactly the texts of doc.
+func translateDoc(st store.Store, sessionID string, doc *types.GeneratedDoc, lang string, cfg LLMConfig, prompts *Prompts, onProgress ProgressFunc) (*types.GeneratedDoc, error) {
+	name, ok := languageName(lang)
+	if !ok {
+		return nil, fmt.Errorf("unsupported language %q", lang)
+	}
+	system, err := prompts.Translate(name)
+	if err != nil {
+		return nil, err
+	}
+	dict, err := cachedTranslation(st, sessionID, lang, prompts.Version)
+	if err != nil {
+		return nil, err
+	}
+	texts := docTexts(doc)
+	var missing []string
+	for _, s := range texts {
+		if _, ok := dict[s]; !ok {
+			missing = append(missing, s)
+		}
+	}
+	if len(missing) > 0 {
+		report(onProgress, fmt.Sprintf("translating %d texts to %s", len(missing), lang))
+		client := &Client{
+			BaseURL:     cfg.BaseURL,
+			APIKey:      cfg.APIKey,
+			Model:       cfg.Model,
+			MaxTokens:   cfg.MaxTokens,
+			Temperature: cfg.Temperature,
+		}
+		for start := 0; start < len(missing); start += maxTranslateTexts {
+			chunk := missing[start:min(start+maxTranslateTexts, len(missing))]
+			translated, err := translateTexts(client, system, chunk)
+			if err != nil {
+				return nil, fmt.Errorf("translate to %s: %w", lang, err)
+			}
+			for i, s := range chunk {
+				dict[s] = translated[i]
+			}
+		}
+	}
+
+	used := make(map[string]string, len(texts))
+	for _, s := range texts {
+		used[s] = dict[s]
+	}
+	if err := st.SaveTranslation(&types.Translation{
+		SessionID:     sessionID,
+		Lang:          lang,
+		Texts:         used,
+		Model:         cfg.Model,
+		PromptVersion: prompts.Version,
+	}); err != nil {
+		return nil, err
+	}
+	return ApplyTranslation(doc, used)
+}
+
+// translateTexts sends texts with the translation system prompt and returns
+// the translations in order.
+func translateTexts(client *Client, system string, texts []string) ([]string, error) {
+	input, err := json.Marshal(texts)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	if err := client.ChatJSON(system, string(input), &out); err != nil {
+		return nil, err
+	}
+	if len(out) != len(texts) {
+		return nil, fmt.Errorf("got %d translations for %d texts", len(out), len(texts))
+	}
+	return out, nil
+}
+
+// cachedTranslation reads the dictionary stored for lang by promptVersion,
+// or an empty one.
+func cachedTranslation(st store.Store, sessionID, lang, promptVersion string) (map[string]string, error) {
+	t, err := st.GetTranslation(sessionID, lang)
+	if err != nil {
+		return nil, err
+	}
+	dict := map[string]string{}
+	if t != nil && t.PromptVersion == promptVersion {
+		for k, v := range t.Texts {
+			dict[k] = v
+		}
+	}
+	return dict, nil
+}
+
+// LoadDocLang is LoadDoc in lang, using the translation cached by the last
+// generate. An empty lang or SourceLanguage returns the doc as generated.
+func LoadDocLang(st store.Store, sess *types.Session, cfg *config.Config, lang string) (*types.GeneratedDoc, error) {
+	doc, err := LoadDoc(st, sess, cfg)
+	if err != nil || lang == "" || lang == SourceLanguage {
+		return doc, err
+	}
+	if _, ok := languageName(lang); !ok {
+		return nil, ErrNoTranslation
+	}
+	t, err := st.GetTranslation(sess.ID, lang)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, ErrNoTranslation
+	}
+	return ApplyTranslation(doc, t.Texts)
+}
@@ -0,0 +1,135 @@
+package generator
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/yourorg/apidoc/internal/config"
+	"github.com/yourorg/apidoc/internal/store"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+func TestApplyTranslation(t *testing.T) {
+	doc := &types.GeneratedDoc{
+		Scenario:  "下单",
+		CallChain: []types.ChainStep{{Seq: 1, Method: "POST", Path: "/orders", Description: "创建订单"}},
+		Endpoints: []types.Endpoint{{
+			Method: "POST", Path: "/orders", Summary: "创建订单", Tags: []string{"订单"},
+			RequestBody: &types.BodySchema{ContentType: "application/json", Fields: []types.Param{
+				{Name: "items", Type: "array", Description: "商品", Children: []types.Param{{Name: "sku", Type: "string", Description: "编码"}}},
+			}},
+			Responses: []types.Response{{StatusCode: 201, Description: "成功"}},
+			Example:   &types.Example{Response: `{"note":"成功"}`},
+		}},
+	}
+	if got := docTexts(doc); strings.Join(got, ",") != "下单,创建订单,订单,商品,编码,成功" {
+		t.Fatalf("docTexts = %v", got)
+	}
+	out, err := ApplyTranslation(doc, map[string]string{"创建订单": "Create order", "订单": "Orders", "编码": "SKU", "成功": "Created"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ep := out.Endpoints[0]
+	if out.Scenario != "下单" || out.CallChain[0].Description != "Create order" || ep.Summary != "Create order" || ep.Tags[0] != "Orders" {
+		t.Fatalf("translated doc = %+v", out)
+	}
+	if f := ep.RequestBody.Fields[0]; f.Name != "items" || f.Children[0].Name != "sku" || f.Children[0].Description != "SKU" {
+		t.Fatalf("field names must be kept: %+v", f)
+	}
+	if ep.Responses[0].Description != "Created" || ep.Example.Response != `{"note":"成功"}` {
+		t.Fatalf("examples must not be translated: %+v", ep)
+	}
+	if doc.Endpoints[0].Summary != "创建订单" {
+		t.Fatal("source doc was modified")
+	}
+}
+
+func TestGenerateLanguages(t *testing.T) {
+	s, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "apidoc.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	sess, err := s.CreateSession("har", "用户", "api.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs := []types.TrafficLog{{Seq: 1, Method: "GET", Host: "api.example.com", Path: "/v1/users", StatusCode: 200}}
+
+	var translations int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct{ Content string } `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		content := `{"scenario":"用户","endpoints":[{"method":"GET","path":"/v1/users","summary":"用户列表","tags":["用户"],"responses":[{"status_code":200,"description":"成功"}]}]}`
+		if strings.Contains(req.Messages[0].Content, "English") {
+			atomic.AddInt32(&translations, 1)
+			var texts []string
+			_ = json.Unmarshal([]byte(req.Messages[1].Content), &texts)
+			for i := range texts {
+				texts[i] = "en:" + texts[i]
+			}
+			data, _ := json.Marshal(texts)
+			content = string(data)
+		}
+		resp := map[string]any{"choices": []map[string]any{{"message": map[string]string{"content": content}}}}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{}
+	cfg.SetDefaults()
+	cfg.LLM = LLMConfig{BaseURL: srv.URL, Model: "gpt-4o", MaxTokens: 4096}
+	cfg.Output.Dir = t.TempDir()
+	cfg.Output.Formats = []string{"markdown"}
+	cfg.Output.Languages = []string{"zh", "en"}
+	doc, err := GenerateWithConfig(sess, logs, cfg, s, nil, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Endpoints[0].Summary != "用户列表" {
+		t.Fatalf("generate should return the source language doc: %+v", doc)
+	}
+	for lang, want := range map[string]string{"zh": "**Summary:** 用户列表", "en": "**Summary:** en:用户列表"} {
+		data, err := os.ReadFile(filepath.Join(cfg.Output.Dir, lang, "api-docs.md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("%s/api-docs.md missing %q:\n%s", lang, want, data)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(cfg.Output.Dir, "api-docs.md")); !os.IsNotExist(err) {
+		t.Fatalf("languages should render into subdirectories only: %v", err)
+	}
+
+	// Cached translations are reused.
+	if _, err := GenerateWithConfig(sess, logs, cfg, s, nil, false, true); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&translations); n != 1 {
+		t.Fatalf("expected 1 translation call, got %d", n)
+	}
+
+	en, err := LoadDocLang(s, sess, cfg, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if en.Endpoints[0].Tags[0] != "en:用户" || en.Endpoints[0].Path != "/v1/users" {
+		t.Fatalf("LoadDocLang(en) = %+v", en.Endpoints[0])
+	}
+	if zh, err := LoadDoc(s, sess, cfg); err != nil || len(zh.Endpoints) != 1 || zh.Endpoints[0].Summary != "用户列表" {
+		t.Fatalf("LoadDoc should skip translation caches: %+v, %v", zh, err)
+	}
+	if _, err := LoadDocLang(s, sess, cfg, "fr"); !errors.Is(err, ErrNoTranslation) {
+		t.Fatalf("want ErrNoTranslation, got %v", err)
+	}
+}
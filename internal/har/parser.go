@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/url"
 	"os"
 	"sort"
@@ -20,33 +21,50 @@ type HARFile struct {
 }
 
 type Entry struct {
-	StartedDateTime string `json:"startedDateTime"`
-	Time            int64  `json:"time"`
+	StartedDateTime string  `json:"startedDateTime"`
+	Time            float64 `json:"time"`
 	Request         struct {
-		Method  string `json:"method"`
-		URL     string `json:"url"`
-		Headers []struct {
-			Name  string `json:"name"`
-			Value string `json:"value"`
-		} `json:"headers"`
-		PostData struct {
-			MimeType string `json:"mimeType"`
-			Text     string `json:"text"`
-			Encoding string `json:"encoding"`
+		Method      string      `json:"method"`
+		URL         string      `json:"url"`
+		HTTPVersion string      `json:"httpVersion"`
+		Headers     []NameValue `json:"headers"`
+		Cookies     []Cookie    `json:"cookies"`
+		QueryString []NameValue `json:"queryString"`
+		PostData    struct {
+			MimeType string      `json:"mimeType"`
+			Text     string      `json:"text"`
+			Encoding string      `json:"encoding"`
+			Params   []NameValue `json:"params"`
 		} `json:"postData"`
 	} `json:"request"`
 	Response struct {
-		Status  int `json:"status"`
-		Headers []struct {
-			Name  string `json:"name"`
-			Value string `json:"value"`
-		} `json:"headers"`
-		Content struct {
+		Status      int         `json:"status"`
+		HTTPVersion string      `json:"httpVersion"`
+		Headers     []NameValue `json:"headers"`
+		Cookies     []Cookie    `json:"cookies"`
+		Content     struct {
 			MimeType string `json:"mimeType"`
 			Text     string `json:"text"`
 			Encoding string `json:"encoding"`
 		} `json:"content"`
 	} `json:"response"`
+	Timings *types.Timings `json:"timings"`
+}
+
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type Cookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path"`
+	Domain   string `json:"domain"`
+	Expires  string `json:"expires"`
+	HTTPOnly bool   `json:"httpOnly"`
+	Secure   bool   `json:"secure"`
+	SameSite string `json:"sameSite"`
 }
 
 func Parse(filePath string) ([]types.TrafficLog, error) {
@@ -68,47 +86,100 @@ func Parse(filePath string) ([]types.TrafficLog, error) {
 		if err != nil {
 			return nil, fmt.Errorf("parse request url: %w", err)
 		}
-		reqHeaders := map[string]string{}
-		for _, h := range e.Request.Headers {
-			reqHeaders[h.Name] = h.Value
-		}
-		respHeaders := map[string]string{}
-		for _, h := range e.Response.Headers {
-			respHeaders[h.Name] = h.Value
+		query := u.Query()
+		if len(query) == 0 && len(e.Request.QueryString) > 0 {
+			query = toValues(e.Request.QueryString)
 		}
 
-		reqBody, reqEnc := decodeBody(e.Request.PostData.Text, e.Request.PostData.Encoding, e.Request.PostData.MimeType)
-		respBody, _ := decodeBody(e.Response.Content.Text, e.Response.Content.Encoding, e.Response.Content.MimeType)
+		reqBody, reqEnc := DecodeBody(e.Request.PostData.Text, e.Request.PostData.Encoding, e.Request.PostData.MimeType)
+		respBody, _ := DecodeBody(e.Response.Content.Text, e.Response.Content.Encoding, e.Response.Content.MimeType)
+
+		httpVersion := e.Response.HTTPVersion
+		if httpVersion == "" {
+			httpVersion = e.Request.HTTPVersion
+		}
 
 		logs = append(logs, types.TrafficLog{
 			Timestamp:           ts,
 			Method:              strings.ToUpper(e.Request.Method),
+			Scheme:              u.Scheme,
 			Host:                u.Host,
 			Path:                u.Path,
-			QueryParams:         u.Query(),
-			RequestHeaders:      reqHeaders,
+			HTTPVersion:         httpVersion,
+			QueryParams:         query,
+			RequestHeaders:      toHeaders(e.Request.Headers),
+			RequestCookies:      toCookies(e.Request.Cookies),
 			RequestBody:         reqBody,
 			RequestBodyEncoding: reqEnc,
+			FormParams:          toValues(e.Request.PostData.Params),
 			ContentType:         e.Request.PostData.MimeType,
 			StatusCode:          e.Response.Status,
-			ResponseHeaders:     respHeaders,
+			ResponseHeaders:     toHeaders(e.Response.Headers),
+			ResponseCookies:     toCookies(e.Response.Cookies),
 			ResponseBody:        respBody,
 			ResponseContentType: e.Response.Content.MimeType,
-			LatencyMs:           e.Time,
+			LatencyMs:           int64(math.Round(e.Time)),
+			Timings:             e.Timings,
 			CallCount:           1,
 		})
 	}
 
-	sort.Slice(logs, func(i, j int) bool {
+	Sequence(logs)
+	return logs, nil
+}
+
+// Sequence orders logs by timestamp and assigns 1-based Seq numbers.
+func Sequence(logs []types.TrafficLog) {
+	sort.SliceStable(logs, func(i, j int) bool {
 		return logs[i].Timestamp.Before(logs[j].Timestamp)
 	})
 	for i := range logs {
 		logs[i].Seq = i + 1
 	}
-	return logs, nil
 }
 
-func decodeBody(text, encoding, mimeType string) (string, string) {
+func toHeaders(in []NameValue) types.Headers {
+	out := types.Headers{}
+	for _, h := range in {
+		out.Add(h.Name, h.Value)
+	}
+	return out
+}
+
+func toValues(in []NameValue) map[string][]string {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make(map[string][]string, len(in))
+	for _, p := range in {
+		out[p.Name] = append(out[p.Name], p.Value)
+	}
+	return out
+}
+
+func toCookies(in []Cookie) []types.Cookie {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]types.Cookie, 0, len(in))
+	for _, c := range in {
+		out = append(out, types.Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Domain:   c.Domain,
+			Expires:  c.Expires,
+			HTTPOnly: c.HTTPOnly,
+			Secure:   c.Secure,
+			SameSite: c.SameSite,
+		})
+	}
+	return out
+}
+
+// DecodeBody returns the textual body and its encoding marker
+// ("plain", "base64" or "omitted" for binary payloads).
+func DecodeBody(text, encoding, mimeType string) (string, string) {
 	if text == "" {
 		return "", "plain"
 	}
@@ -37,6 +37,35 @@ func TestParseBase64Body(t *testing.T) {
 	}
 }
 
+func TestParseCookiesHeadersAndTimings(t *testing.T) {
+	logs, err := Parse(filepath.Join("..", "..", "testdata", "cookies.har"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 1 {
+		t.Fatalf("expected 1 log, got %d", len(logs))
+	}
+	l := logs[0]
+	if l.HTTPVersion != "HTTP/2" {
+		t.Fatalf("expected response http version, got %q", l.HTTPVersion)
+	}
+	if len(l.ResponseHeaders.Values("Set-Cookie")) != 2 || len(l.ResponseHeaders.Values("vary")) != 2 {
+		t.Fatalf("expected repeated headers preserved, got %v", l.ResponseHeaders)
+	}
+	if len(l.ResponseCookies) != 2 || !l.ResponseCookies[0].HTTPOnly || l.RequestCookies[0].Name != "theme" {
+		t.Fatalf("unexpected cookies: req=%+v resp=%+v", l.RequestCookies, l.ResponseCookies)
+	}
+	if len(l.FormParams["scope"]) != 2 || l.FormParams["user"][0] != "alice" {
+		t.Fatalf("expected post params, got %v", l.FormParams)
+	}
+	if l.Timings == nil || l.Timings.Wait != 30 || l.Timings.Blocked != -1 {
+		t.Fatalf("unexpected timings: %+v", l.Timings)
+	}
+	if l.LatencyMs != 43 {
+		t.Fatalf("expected fractional time rounded, got %d", l.LatencyMs)
+	}
+}
+
 func TestParseEmptyHAR(t *testing.T) {
 	logs, err := Parse(filepath.Join("..", "..", "testdata", "empty.har"))
 	if err != nil {
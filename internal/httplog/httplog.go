@@ -0,0 +1,126 @@
+// Package httplog holds the pieces shared by the recorders that turn live
+// net/http exchanges into types.TrafficLog: cookie conversion and capped
+// capture of streamed bodies.
+package httplog
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// Cookies converts parsed cookies, keeping the attributes a Set-Cookie
+// header carries.
+func Cookies(in []*http.Cookie) []types.Cookie {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]types.Cookie, 0, len(in))
+	for _, c := range in {
+		ck := types.Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Domain:   c.Domain,
+			HTTPOnly: c.HttpOnly,
+			Secure:   c.Secure,
+		}
+		if !c.Expires.IsZero() {
+			ck.Expires = c.Expires.UTC().Format(time.RFC3339)
+		}
+		switch c.SameSite {
+		case http.SameSiteLaxMode:
+			ck.SameSite = "Lax"
+		case http.SameSiteStrictMode:
+			ck.SameSite = "Strict"
+		case http.SameSiteNoneMode:
+			ck.SameSite = "None"
+		}
+		out = append(out, ck)
+	}
+	return out
+}
+
+// ResponseCookies converts the Set-Cookie headers of h.
+func ResponseCookies(h http.Header) []types.Cookie {
+	return Cookies((&http.Response{Header: h}).Cookies())
+}
+
+// Buffer keeps up to limit bytes written to it and remembers whether more
+// arrived, in which case it keeps nothing.
+type Buffer struct {
+	mu       sync.Mutex
+	buf      bytes.Buffer
+	limit    int64
+	overflow bool
+}
+
+// NewBuffer returns a Buffer capped at limit bytes.
+func NewBuffer(limit int64) *Buffer {
+	return &Buffer{limit: limit}
+}
+
+// Write always consumes p so a tee never fails the stream it copies.
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.overflow {
+		return len(p), nil
+	}
+	if int64(b.buf.Len()+len(p)) > b.limit {
+		b.overflow = true
+		b.buf = bytes.Buffer{}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+// Captured returns a copy of the kept bytes and whether the limit was hit.
+func (b *Buffer) Captured() ([]byte, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.buf.Bytes()...), b.overflow
+}
+
+// Body tees a streamed body into a Buffer without buffering the stream
+// itself; onDone fires once on EOF or Close.
+type Body struct {
+	rc     io.ReadCloser
+	buf    *Buffer
+	onDone func()
+	once   sync.Once
+}
+
+// NewBody wraps rc, copying what is read into buf. onDone may be nil.
+func NewBody(rc io.ReadCloser, buf *Buffer, onDone func()) *Body {
+	return &Body{rc: rc, buf: buf, onDone: onDone}
+}
+
+func (b *Body) Read(p []byte) (int, error) {
+	n, err := b.rc.Read(p)
+	if n > 0 {
+		_, _ = b.buf.Write(p[:n])
+	}
+	if err == io.EOF {
+		b.done()
+	}
+	return n, err
+}
+
+func (b *Body) Close() error {
+	err := b.rc.Close()
+	b.done()
+	return err
+}
+
+func (b *Body) done() {
+	b.once.Do(func() {
+		if b.onDone != nil {
+			b.onDone()
+		}
+	})
+}
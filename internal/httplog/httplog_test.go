@@ -0,0 +1,50 @@
+package httplog
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestResponseCookies(t *testing.T) {
+	h := http.Header{"Set-Cookie": {"sid=abc; Path=/; HttpOnly; Secure; SameSite=Lax; Expires=Wed, 21 Oct 2026 07:28:00 GMT"}}
+	got := ResponseCookies(h)
+	if len(got) != 1 {
+		t.Fatalf("cookies = %+v", got)
+	}
+	c := got[0]
+	if c.Name != "sid" || c.Value != "abc" || c.Path != "/" || !c.HTTPOnly || !c.Secure || c.SameSite != "Lax" || c.Expires != "2026-10-21T07:28:00Z" {
+		t.Fatalf("cookie = %+v", c)
+	}
+	if ResponseCookies(http.Header{}) != nil {
+		t.Fatal("no Set-Cookie should give nil")
+	}
+}
+
+func TestBody(t *testing.T) {
+	for _, c := range []struct {
+		limit    int64
+		body     string
+		overflow bool
+	}{
+		{limit: 8, body: "short"},
+		{limit: 4, body: "too long", overflow: true},
+	} {
+		buf := NewBuffer(c.limit)
+		done := 0
+		b := NewBody(io.NopCloser(strings.NewReader(c.body)), buf, func() { done++ })
+		data, err := io.ReadAll(b)
+		if err != nil || string(data) != c.body {
+			t.Fatalf("read %q, %v", data, err)
+		}
+		_ = b.Close()
+		if done != 1 {
+			t.Fatalf("onDone called %d times", done)
+		}
+		got, overflow := buf.Captured()
+		if overflow != c.overflow || (!overflow && string(got) != c.body) || (overflow && len(got) != 0) {
+			t.Fatalf("limit %d: captured %q overflow=%v", c.limit, got, overflow)
+		}
+	}
+}
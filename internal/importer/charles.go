@@ -0,0 +1,176 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yourorg/apidoc/internal/har"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// charlesEntry is one transaction of a Charles JSON session (.chlsj).
+type charlesEntry struct {
+	Status          string `json:"status"`
+	Method          string `json:"method"`
+	ProtocolVersion string `json:"protocolVersion"`
+	Scheme          string `json:"scheme"`
+	Host            string `json:"host"`
+	Port            int    `json:"port"`
+	Path            string `json:"path"`
+	Query           string `json:"query"`
+	Tunnel          bool   `json:"tunnel"`
+	Times           struct {
+		Start string `json:"start"`
+	} `json:"times"`
+	Durations struct {
+		Total    *float64 `json:"total"`
+		DNS      *float64 `json:"dns"`
+		Connect  *float64 `json:"connect"`
+		SSL      *float64 `json:"ssl"`
+		Request  *float64 `json:"request"`
+		Response *float64 `json:"response"`
+		Latency  *float64 `json:"latency"`
+	} `json:"durations"`
+	Request  charlesMessage `json:"request"`
+	Response struct {
+		Status int `json:"status"`
+		charlesMessage
+	} `json:"response"`
+}
+
+type charlesMessage struct {
+	MimeType string `json:"mimeType"`
+	Header   struct {
+		FirstLine string `json:"firstLine"`
+		Headers   []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"headers"`
+	} `json:"header"`
+	Body *struct {
+		Text    string `json:"text"`
+		Encoded string `json:"encoded"`
+	} `json:"body"`
+}
+
+// ParseCharles parses a Charles Proxy JSON session export.
+func ParseCharles(filePath string) ([]types.TrafficLog, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var entries []charlesEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	logs := make([]types.TrafficLog, 0, len(entries))
+	for _, e := range entries {
+		// CONNECT tunnels Charles could not decrypt carry no HTTP exchange.
+		if e.Tunnel || strings.EqualFold(e.Method, "CONNECT") {
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339Nano, e.Times.Start)
+		if err != nil {
+			return nil, fmt.Errorf("parse times.start: %w", err)
+		}
+		query, err := url.ParseQuery(e.Query)
+		if err != nil {
+			return nil, fmt.Errorf("parse query: %w", err)
+		}
+		if len(query) == 0 {
+			query = nil
+		}
+
+		reqText, reqEncoding := e.Request.body()
+		respText, respEncoding := e.Response.body()
+		reqBody, reqEnc := har.DecodeBody(reqText, reqEncoding, e.Request.MimeType)
+		respBody, _ := har.DecodeBody(respText, respEncoding, e.Response.MimeType)
+
+		var latency int64
+		if e.Durations.Total != nil {
+			latency = int64(math.Round(*e.Durations.Total))
+		}
+
+		logs = append(logs, types.TrafficLog{
+			Timestamp:           ts,
+			Method:              strings.ToUpper(e.Method),
+			Scheme:              e.Scheme,
+			Host:                hostWithPort(e.Host, e.Port, e.Scheme),
+			Path:                e.Path,
+			HTTPVersion:         e.ProtocolVersion,
+			QueryParams:         query,
+			RequestHeaders:      e.Request.headers(),
+			RequestBody:         reqBody,
+			RequestBodyEncoding: reqEnc,
+			ContentType:         e.Request.MimeType,
+			StatusCode:          e.Response.Status,
+			ResponseHeaders:     e.Response.headers(),
+			ResponseBody:        respBody,
+			ResponseContentType: e.Response.MimeType,
+			LatencyMs:           latency,
+			Timings:             e.timings(),
+			CallCount:           1,
+		})
+	}
+	har.Sequence(logs)
+	return logs, nil
+}
+
+func (m charlesMessage) headers() types.Headers {
+	out := types.Headers{}
+	for _, h := range m.Header.Headers {
+		out.Add(h.Name, h.Value)
+	}
+	return out
+}
+
+// body returns the body text and its HAR-style encoding; Charles stores
+// binary or non-UTF-8 bodies base64-encoded under "encoded".
+func (m charlesMessage) body() (string, string) {
+	if m.Body == nil {
+		return "", ""
+	}
+	if m.Body.Encoded != "" {
+		return m.Body.Encoded, "base64"
+	}
+	return m.Body.Text, ""
+}
+
+func (e charlesEntry) timings() *types.Timings {
+	d := e.Durations
+	if d.Total == nil {
+		return nil
+	}
+	val := func(p *float64) float64 {
+		if p == nil {
+			return -1
+		}
+		return *p
+	}
+	return &types.Timings{
+		Blocked: -1,
+		DNS:     val(d.DNS),
+		Connect: val(d.Connect),
+		SSL:     val(d.SSL),
+		Send:    val(d.Request),
+		Wait:    val(d.Latency),
+		Receive: val(d.Response),
+	}
+}
+
+// hostWithPort appends the port unless it is the scheme default, matching
+// url.URL.Host as produced by the HAR parser.
+func hostWithPort(host string, port int, scheme string) string {
+	if port == 0 {
+		return host
+	}
+	if (scheme == "https" && port == 443) || (scheme == "http" && port == 80) {
+		return host
+	}
+	return fmt.Sprintf("%s:%d", host, port)
+}
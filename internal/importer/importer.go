@@ -0,0 +1,91 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yourorg/apidoc/internal/har"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// Supported capture formats.
+const (
+	FormatAuto      = "auto"
+	FormatHAR       = "har"
+	FormatCharles   = "charles"
+	FormatMitmproxy = "mitmproxy"
+)
+
+// Parse reads a capture file in the given format and returns normalized
+// traffic logs ordered by time. An empty format or FormatAuto detects it.
+func Parse(filePath, format string) ([]types.TrafficLog, error) {
+	if format == "" || format == FormatAuto {
+		detected, err := DetectFormat(filePath)
+		if err != nil {
+			return nil, err
+		}
+		format = detected
+	}
+	switch format {
+	case FormatHAR:
+		return har.Parse(filePath)
+	case FormatCharles:
+		return ParseCharles(filePath)
+	case FormatMitmproxy:
+		return ParseMitmproxy(filePath)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// DetectFormat guesses the capture format from the file extension, falling
+// back to sniffing the JSON shape.
+func DetectFormat(filePath string) (string, error) {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".har":
+		return FormatHAR, nil
+	case ".chlsj":
+		return FormatCharles, nil
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return "", fmt.Errorf("cannot detect format of empty file %s", filePath)
+	}
+	if data[0] == '{' {
+		var probe struct {
+			Log   json.RawMessage `json:"log"`
+			Flows json.RawMessage `json:"flows"`
+		}
+		if err := json.Unmarshal(data, &probe); err == nil {
+			if probe.Log != nil {
+				return FormatHAR, nil
+			}
+			if probe.Flows != nil {
+				return FormatMitmproxy, nil
+			}
+		}
+		// A single mitmproxy flow per line (jsondump style).
+		return FormatMitmproxy, nil
+	}
+	var items []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return "", fmt.Errorf("detect format: %w", err)
+	}
+	if len(items) > 0 {
+		if _, ok := items[0]["times"]; ok {
+			return FormatCharles, nil
+		}
+		if _, ok := items[0]["scheme"]; ok {
+			return FormatCharles, nil
+		}
+	}
+	return FormatMitmproxy, nil
+}
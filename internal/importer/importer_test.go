@@ -0,0 +1,97 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseCharles(t *testing.T) {
+	logs, err := Parse(filepath.Join("..", "..", "testdata", "sample.chlsj"), FormatAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("expected 2 logs (tunnel skipped), got %d", len(logs))
+	}
+	if logs[0].Path != "/v1/login" || logs[0].Seq != 1 {
+		t.Fatalf("expected login first by time, got %s seq %d", logs[0].Path, logs[0].Seq)
+	}
+	if logs[0].RequestBody != `{"user":"alice"}` || logs[0].RequestBodyEncoding != "base64" {
+		t.Fatalf("expected decoded request body, got %q (%s)", logs[0].RequestBody, logs[0].RequestBodyEncoding)
+	}
+	users := logs[1]
+	if users.Host != "api.example.com" || len(users.QueryParams["id"]) != 2 {
+		t.Fatalf("unexpected host/query: %s %v", users.Host, users.QueryParams)
+	}
+	if len(users.ResponseHeaders.Values("Set-Cookie")) != 2 {
+		t.Fatalf("expected repeated Set-Cookie headers")
+	}
+	if users.LatencyMs != 120 || users.Timings == nil || users.Timings.Wait != 100 {
+		t.Fatalf("unexpected latency/timings: %d %+v", users.LatencyMs, users.Timings)
+	}
+}
+
+func TestParseMitmproxy(t *testing.T) {
+	logs, err := Parse(filepath.Join("..", "..", "testdata", "sample-mitmproxy.json"), FormatMitmproxy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("expected 2 logs (tcp flow skipped), got %d", len(logs))
+	}
+	login := logs[0]
+	if login.Host != "localhost:8080" || login.Path != "/v1/login" || login.StatusCode != 201 {
+		t.Fatalf("unexpected login log: %+v", login)
+	}
+	if login.LatencyMs != 80 {
+		t.Fatalf("expected latency from timestamps, got %d", login.LatencyMs)
+	}
+	users := logs[1]
+	if len(users.QueryParams["id"]) != 2 || users.ResponseContentType != "application/json" {
+		t.Fatalf("unexpected users log: %+v", users)
+	}
+	if len(users.ResponseHeaders.Values("Vary")) != 2 {
+		t.Fatalf("expected repeated headers from pair list")
+	}
+}
+
+func TestParseMitmproxyLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flows.jsonl")
+	lines := `{"request":{"method":"GET","url":"https://api.example.com/v1/ping","headers":{},"timestamp_start":1771927200},"response":{"status_code":204,"headers":{},"timestamp_end":1771927200.005}}
+{"request":{"method":"GET","url":"https://api.example.com/v1/pong","headers":{},"timestamp_start":1771927201},"response":{"status_code":200,"headers":{},"timestamp_end":1771927201.005}}
+`
+	if err := os.WriteFile(path, []byte(lines), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	logs, err := Parse(path, FormatAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 || logs[0].Host != "api.example.com" || logs[1].Path != "/v1/pong" {
+		t.Fatalf("unexpected logs: %+v", logs)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	cases := map[string]string{
+		"sample.har":            FormatHAR,
+		"sample.chlsj":          FormatCharles,
+		"sample-mitmproxy.json": FormatMitmproxy,
+	}
+	for name, want := range cases {
+		got, err := DetectFormat(filepath.Join("..", "..", "testdata", name))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got != want {
+			t.Fatalf("%s: expected %s, got %s", name, want, got)
+		}
+	}
+}
+
+func TestParseUnsupportedFormat(t *testing.T) {
+	if _, err := Parse(filepath.Join("..", "..", "testdata", "sample.har"), "pcap"); err == nil {
+		t.Fatalf("expected error for unsupported format")
+	}
+}
@@ -0,0 +1,200 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yourorg/apidoc/internal/har"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// mitmFlow is one HTTP flow as exported by mitmweb's flow JSON or the
+// jsondump addon. Headers may be a list of [name, value] pairs or an object.
+type mitmFlow struct {
+	Type     string       `json:"type"`
+	Request  *mitmMessage `json:"request"`
+	Response *mitmMessage `json:"response"`
+}
+
+type mitmMessage struct {
+	Method         string      `json:"method"`
+	Scheme         string      `json:"scheme"`
+	Host           string      `json:"host"`
+	Port           int         `json:"port"`
+	Path           string      `json:"path"`
+	URL            string      `json:"url"`
+	HTTPVersion    string      `json:"http_version"`
+	StatusCode     int         `json:"status_code"`
+	Headers        mitmHeaders `json:"headers"`
+	Content        *string     `json:"content"`
+	Text           *string     `json:"text"`
+	TimestampStart float64     `json:"timestamp_start"`
+	TimestampEnd   float64     `json:"timestamp_end"`
+}
+
+type mitmHeaders struct {
+	types.Headers
+}
+
+func (h *mitmHeaders) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var pairs [][]string
+		if err := json.Unmarshal(data, &pairs); err != nil {
+			return err
+		}
+		h.Headers = types.Headers{}
+		for _, p := range pairs {
+			if len(p) != 2 {
+				return fmt.Errorf("invalid header pair %v", p)
+			}
+			h.Headers.Add(p[0], p[1])
+		}
+		return nil
+	}
+	return json.Unmarshal(data, &h.Headers)
+}
+
+// ParseMitmproxy parses mitmproxy flows exported as JSON: an array of flows,
+// an object with a "flows" array, or one flow object per line.
+func ParseMitmproxy(filePath string) ([]types.TrafficLog, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	flows, err := decodeMitmFlows(data)
+	if err != nil {
+		return nil, err
+	}
+	logs := make([]types.TrafficLog, 0, len(flows))
+	for i, f := range flows {
+		if f.Type != "" && f.Type != "http" {
+			continue
+		}
+		if f.Request == nil || f.Response == nil {
+			continue
+		}
+		l, err := mitmFlowToLog(f)
+		if err != nil {
+			return nil, fmt.Errorf("flow %d: %w", i, err)
+		}
+		logs = append(logs, l)
+	}
+	har.Sequence(logs)
+	return logs, nil
+}
+
+func decodeMitmFlows(data []byte) ([]mitmFlow, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if data[0] == '[' {
+		var flows []mitmFlow
+		err := json.Unmarshal(data, &flows)
+		return flows, err
+	}
+	var wrapped struct {
+		Flows []mitmFlow `json:"flows"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err == nil && wrapped.Flows != nil {
+		return wrapped.Flows, nil
+	}
+	var flows []mitmFlow
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var f mitmFlow
+		if err := json.Unmarshal(line, &f); err != nil {
+			return nil, err
+		}
+		flows = append(flows, f)
+	}
+	return flows, sc.Err()
+}
+
+func mitmFlowToLog(f mitmFlow) (types.TrafficLog, error) {
+	req, resp := f.Request, f.Response
+	host := hostWithPort(req.Host, req.Port, req.Scheme)
+	scheme := req.Scheme
+	rawPath := req.Path
+	if req.URL != "" {
+		u, err := url.Parse(req.URL)
+		if err != nil {
+			return types.TrafficLog{}, fmt.Errorf("parse request url: %w", err)
+		}
+		if host == "" {
+			host = u.Host
+		}
+		if scheme == "" {
+			scheme = u.Scheme
+		}
+		if rawPath == "" {
+			rawPath = u.RequestURI()
+		}
+	}
+	u, err := url.ParseRequestURI(rawPath)
+	if err != nil {
+		return types.TrafficLog{}, fmt.Errorf("parse request path: %w", err)
+	}
+	query := u.Query()
+	if len(query) == 0 {
+		query = nil
+	}
+
+	reqCT := req.Headers.Get("Content-Type")
+	respCT := resp.Headers.Get("Content-Type")
+	reqBody, reqEnc := har.DecodeBody(req.body(), "", reqCT)
+	respBody, _ := har.DecodeBody(resp.body(), "", respCT)
+
+	var latency int64
+	if resp.TimestampEnd > 0 && req.TimestampStart > 0 {
+		latency = int64(math.Round((resp.TimestampEnd - req.TimestampStart) * 1000))
+	}
+
+	return types.TrafficLog{
+		Timestamp:           unixSeconds(req.TimestampStart),
+		Method:              strings.ToUpper(req.Method),
+		Scheme:              scheme,
+		Host:                host,
+		Path:                u.Path,
+		HTTPVersion:         resp.HTTPVersion,
+		QueryParams:         query,
+		RequestHeaders:      req.Headers.Headers,
+		RequestBody:         reqBody,
+		RequestBodyEncoding: reqEnc,
+		ContentType:         reqCT,
+		StatusCode:          resp.StatusCode,
+		ResponseHeaders:     resp.Headers.Headers,
+		ResponseBody:        respBody,
+		ResponseContentType: respCT,
+		LatencyMs:           latency,
+		CallCount:           1,
+	}, nil
+}
+
+func (m *mitmMessage) body() string {
+	if m.Text != nil {
+		return *m.Text
+	}
+	if m.Content != nil {
+		return *m.Content
+	}
+	return ""
+}
+
+func unixSeconds(ts float64) time.Time {
+	sec, frac := math.Modf(ts)
+	return time.Unix(int64(sec), int64(frac*1e9)).UTC()
+}
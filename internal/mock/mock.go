@@ -0,0 +1,280 @@
+// Package mock serves recorded responses so front-end work can proceed
+// without the real backend.
+package mock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/yourorg/apidoc/internal/generator"
+	"github.com/yourorg/apidoc/internal/pathmatch"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// Latency modes.
+const (
+	LatencyOff      = "off"
+	LatencyRecorded = "recorded"
+)
+
+// Options configures the mock server.
+type Options struct {
+	// Latency is LatencyOff, LatencyRecorded, or a fixed Go duration such
+	// as "250ms".
+	Latency string
+	// Sleep is used to simulate latency; defaults to time.Sleep.
+	Sleep func(time.Duration)
+}
+
+// Server answers requests from a session's logs and generated doc.
+type Server struct {
+	logs  []types.TrafficLog
+	doc   *types.GeneratedDoc
+	delay func(types.TrafficLog) time.Duration
+	sleep func(time.Duration)
+}
+
+// New builds a mock server. doc may be nil; without it only recorded
+// exchanges are served and path templates are inferred from ID-like
+// segments.
+func New(logs []types.TrafficLog, doc *types.GeneratedDoc, opts Options) (*Server, error) {
+	if len(logs) == 0 && (doc == nil || len(doc.Endpoints) == 0) {
+		return nil, errors.New("nothing to serve: session has no logs and no generated doc")
+	}
+	s := &Server{logs: logs, doc: doc, sleep: opts.Sleep}
+	if s.sleep == nil {
+		s.sleep = time.Sleep
+	}
+	switch opts.Latency {
+	case "", LatencyOff:
+		s.delay = func(types.TrafficLog) time.Duration { return 0 }
+	case LatencyRecorded:
+		s.delay = func(l types.TrafficLog) time.Duration { return time.Duration(l.LatencyMs) * time.Millisecond }
+	default:
+		d, err := time.ParseDuration(opts.Latency)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("latency must be %q, %q or a duration, got %q", LatencyOff, LatencyRecorded, opts.Latency)
+		}
+		s.delay = func(types.TrafficLog) time.Duration { return d }
+	}
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		if h := r.Header.Get("Access-Control-Request-Headers"); h != "" {
+			w.Header().Set("Access-Control-Allow-Headers", h)
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	body, _ := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+
+	ep, hasEndpoint := s.endpointFor(r.Method, r.URL.Path)
+	if l, ok := s.bestLog(r, string(body), ep, hasEndpoint); ok {
+		if d := s.delay(l); d > 0 {
+			s.sleep(d)
+		}
+		writeRecorded(w, l)
+		return
+	}
+	if hasEndpoint {
+		if status, payload, ok := exampleResponse(ep); ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-Apidoc-Mock", "example")
+			w.WriteHeader(status)
+			_, _ = io.WriteString(w, payload)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotFound)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": "no recorded response for " + r.Method + " " + r.URL.Path})
+}
+
+// endpointFor finds the documented endpoint whose templated path matches
+// most specifically.
+func (s *Server) endpointFor(method, path string) (types.Endpoint, bool) {
+	if s.doc == nil {
+		return types.Endpoint{}, false
+	}
+	var templates []string
+	var candidates []types.Endpoint
+	for _, ep := range s.doc.Endpoints {
+		if strings.EqualFold(ep.Method, method) {
+			templates = append(templates, ep.Path)
+			candidates = append(candidates, ep)
+		}
+	}
+	if i := pathmatch.Best(templates, path); i >= 0 {
+		return candidates[i], true
+	}
+	return types.Endpoint{}, false
+}
+
+// bestLog picks the recorded exchange most similar to r among those with the
+// same method and path template.
+func (s *Server) bestLog(r *http.Request, body string, ep types.Endpoint, hasEndpoint bool) (types.TrafficLog, bool) {
+	var best types.TrafficLog
+	bestScore := -1.0
+	for _, l := range s.logs {
+		if !strings.EqualFold(l.Method, r.Method) {
+			continue
+		}
+		var score float64
+		switch {
+		case l.Path == r.URL.Path:
+			score = 2
+		case hasEndpoint && matches(ep.Path, l.Path):
+			score = 1
+		case !hasEndpoint && sameShape(l.Path, r.URL.Path):
+			score = 1
+		default:
+			continue
+		}
+		score += similarity(queryPairs(l.QueryParams), queryPairs(r.URL.Query()))
+		score += similarity(bodyPairs(l.RequestBody), bodyPairs(body))
+		// Later recordings win ties: they reflect the state after earlier calls.
+		if score >= bestScore {
+			best, bestScore = l, score
+		}
+	}
+	return best, bestScore >= 0
+}
+
+func matches(template, path string) bool {
+	_, ok := pathmatch.Match(template, path)
+	return ok
+}
+
+var idSegment = regexp.MustCompile(`^(\d+|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9a-fA-F]{24,})$`)
+
+// sameShape treats two paths as the same endpoint when they differ only in
+// ID-like segments.
+func sameShape(a, b string) bool {
+	as := strings.Split(strings.Trim(a, "/"), "/")
+	bs := strings.Split(strings.Trim(b, "/"), "/")
+	if len(as) != len(bs) {
+		return false
+	}
+	for i := range as {
+		if as[i] != bs[i] && !(idSegment.MatchString(as[i]) && idSegment.MatchString(bs[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+func queryPairs(q map[string][]string) map[string]bool {
+	out := map[string]bool{}
+	for k, vs := range q {
+		out[k] = true
+		for _, v := range vs {
+			out[k+"="+v] = true
+		}
+	}
+	return out
+}
+
+// bodyPairs flattens a JSON body into path and path=value tokens.
+func bodyPairs(body string) map[string]bool {
+	out := map[string]bool{}
+	var v any
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		if body != "" {
+			out["="+body] = true
+		}
+		return out
+	}
+	flatten("$", v, out)
+	return out
+}
+
+func flatten(path string, v any, out map[string]bool) {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, child := range t {
+			flatten(path+"."+k, child, out)
+		}
+	case []any:
+		for _, child := range t {
+			flatten(path+"[]", child, out)
+		}
+	default:
+		out[path] = true
+		out[fmt.Sprintf("%s=%v", path, t)] = true
+	}
+}
+
+// similarity is the Jaccard index of two token sets; two empty sets match.
+func similarity(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	inter := 0
+	for k := range a {
+		if b[k] {
+			inter++
+		}
+	}
+	union := len(a) + len(b) - inter
+	return float64(inter) / float64(union)
+}
+
+// skipResponseHeaders are recomputed by net/http or invalid after decoding.
+var skipResponseHeaders = map[string]bool{
+	"content-length":    true,
+	"content-encoding":  true,
+	"transfer-encoding": true,
+	"connection":        true,
+	"date":              true,
+	"keep-alive":        true,
+}
+
+func writeRecorded(w http.ResponseWriter, l types.TrafficLog) {
+	for name, vs := range l.ResponseHeaders {
+		if skipResponseHeaders[strings.ToLower(name)] || strings.HasPrefix(name, ":") {
+			continue
+		}
+		for _, v := range vs {
+			w.Header().Add(name, v)
+		}
+	}
+	if w.Header().Get("Content-Type") == "" && l.ResponseContentType != "" {
+		w.Header().Set("Content-Type", l.ResponseContentType)
+	}
+	w.Header().Set("X-Apidoc-Mock", fmt.Sprintf("recorded; seq=%d", l.Seq))
+	status := l.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, l.ResponseBody)
+}
+
+// exampleResponse renders the first successful documented response, using
+// the doc's example when it has one.
+func exampleResponse(ep types.Endpoint) (int, string, bool) {
+	for _, resp := range ep.Responses {
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			continue
+		}
+		if ep.Example != nil && json.Valid([]byte(ep.Example.Response)) {
+			return resp.StatusCode, ep.Example.Response, true
+		}
+		data, err := json.Marshal(generator.ExampleObject(resp.Fields))
+		if err != nil {
+			return 0, "", false
+		}
+		return resp.StatusCode, string(data), true
+	}
+	return 0, "", false
+}
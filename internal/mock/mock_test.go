@@ -0,0 +1,97 @@
+package mock
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+func logs() []types.TrafficLog {
+	return []types.TrafficLog{
+		{Seq: 1, Method: "GET", Path: "/api/users", QueryParams: map[string][]string{"page": {"1"}}, StatusCode: 200,
+			ResponseHeaders: types.Headers{"Content-Type": {"application/json"}, "Content-Length": {"99"}}, ResponseBody: `{"page":1}`, LatencyMs: 40},
+		{Seq: 2, Method: "GET", Path: "/api/users", QueryParams: map[string][]string{"page": {"2"}}, StatusCode: 200,
+			ResponseContentType: "application/json", ResponseBody: `{"page":2}`},
+		{Seq: 3, Method: "GET", Path: "/api/users/7", StatusCode: 200, ResponseContentType: "application/json", ResponseBody: `{"id":7}`},
+		{Seq: 4, Method: "POST", Path: "/api/login", RequestBody: `{"user":"alice","remember":true}`, StatusCode: 200, ResponseBody: `{"who":"alice"}`},
+		{Seq: 5, Method: "POST", Path: "/api/login", RequestBody: `{"user":"bob","remember":true}`, StatusCode: 401, ResponseBody: `{"who":"bob"}`},
+	}
+}
+
+func get(t *testing.T, h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMockMatchesRecordedResponses(t *testing.T) {
+	var slept time.Duration
+	srv, err := New(logs(), nil, Options{Latency: LatencyRecorded, Sleep: func(d time.Duration) { slept += d }})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rec := get(t, srv, "GET", "/api/users?page=2", ""); rec.Body.String() != `{"page":2}` {
+		t.Fatalf("query match: %s", rec.Body.String())
+	}
+	rec := get(t, srv, "GET", "/api/users?page=1", "")
+	if rec.Body.String() != `{"page":1}` || rec.Header().Get("Content-Length") == "99" {
+		t.Fatalf("recorded headers/body: %v %s", rec.Header(), rec.Body.String())
+	}
+	if slept != 40*time.Millisecond {
+		t.Fatalf("slept %v, want recorded 40ms", slept)
+	}
+	if rec := get(t, srv, "GET", "/api/users/42", ""); rec.Body.String() != `{"id":7}` {
+		t.Fatalf("ID-like segment should match: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := get(t, srv, "POST", "/api/login", `{"user":"bob","remember":true}`); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("body similarity should pick bob's exchange, got %d", rec.Code)
+	}
+	if rec := get(t, srv, "DELETE", "/api/users/7", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("unmatched method should 404, got %d", rec.Code)
+	}
+}
+
+func TestMockFallsBackToDocExamples(t *testing.T) {
+	doc := &types.GeneratedDoc{Endpoints: []types.Endpoint{
+		{Method: "GET", Path: "/api/orders/{id}", Responses: []types.Response{{StatusCode: 200, Fields: []types.Param{
+			{Name: "id", Type: "integer"},
+			{Name: "items", Type: "array", Children: []types.Param{{Name: "sku", Type: "string"}}},
+		}}}},
+		{Method: "GET", Path: "/api/users/{id}", Responses: []types.Response{{StatusCode: 200}}},
+	}}
+	srv, err := New(logs(), doc, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := get(t, srv, "GET", "/api/orders/9", "")
+	body, _ := io.ReadAll(rec.Body)
+	if rec.Code != http.StatusOK || string(body) != `{"id":0,"items":[{"sku":"string"}]}` {
+		t.Fatalf("example fallback: %d %s", rec.Code, body)
+	}
+	if rec := get(t, srv, "GET", "/api/users/99", ""); rec.Body.String() != `{"id":7}` {
+		t.Fatalf("documented template should match recorded path: %s", rec.Body.String())
+	}
+}
+
+func TestMockLatencyOption(t *testing.T) {
+	if _, err := New(logs(), nil, Options{Latency: "soon"}); err == nil {
+		t.Fatal("expected invalid latency error")
+	}
+	var slept time.Duration
+	srv, _ := New(logs(), nil, Options{Latency: "150ms", Sleep: func(d time.Duration) { slept = d }})
+	get(t, srv, "GET", "/api/users", "")
+	if slept != 150*time.Millisecond {
+		t.Fatalf("slept %v, want 150ms", slept)
+	}
+	if _, err := New(nil, nil, Options{}); err == nil {
+		t.Fatal("expected error with nothing to serve")
+	}
+}
@@ -0,0 +1,74 @@
+// Package pathmatch matches concrete request paths against templated API
+// paths such as /users/{id} or /users/:id.
+package pathmatch
+
+import "strings"
+
+// Match reports whether path fits template, returning the values bound to
+// each template parameter.
+func Match(template, path string) (map[string]string, bool) {
+	ts := split(template)
+	ps := split(path)
+	if len(ts) != len(ps) {
+		return nil, false
+	}
+	params := map[string]string{}
+	for i, seg := range ts {
+		if name, ok := paramName(seg); ok {
+			if ps[i] == "" {
+				return nil, false
+			}
+			params[name] = ps[i]
+			continue
+		}
+		if seg != ps[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// Best returns the index of the template in templates that path fits most
+// specifically, or -1 if none fits. Fewer parameters win, so /users/me beats
+// /users/{id}; ties go to the earlier template.
+func Best(templates []string, path string) int {
+	best, bestParams := -1, 0
+	for i, t := range templates {
+		params, ok := Match(t, path)
+		if !ok {
+			continue
+		}
+		if best < 0 || len(params) < bestParams {
+			best, bestParams = i, len(params)
+		}
+	}
+	return best
+}
+
+// IsTemplate reports whether template contains at least one parameter.
+func IsTemplate(template string) bool {
+	for _, seg := range split(template) {
+		if _, ok := paramName(seg); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func paramName(seg string) (string, bool) {
+	if len(seg) > 2 && strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+		return seg[1 : len(seg)-1], true
+	}
+	if len(seg) > 1 && strings.HasPrefix(seg, ":") {
+		return seg[1:], true
+	}
+	return "", false
+}
+
+func split(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
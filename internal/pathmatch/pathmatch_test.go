@@ -0,0 +1,46 @@
+package pathmatch
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		tmpl, path string
+		ok         bool
+		param      string
+	}{
+		{"/users/{id}", "/users/42", true, "42"},
+		{"/users/:id", "/users/abc/", true, "abc"},
+		{"/users/{id}", "/users/42/orders", false, ""},
+		{"/users", "/users", true, ""},
+		{"/users", "/orders", false, ""},
+		{"/", "/", true, ""},
+	}
+	for _, c := range cases {
+		params, ok := Match(c.tmpl, c.path)
+		if ok != c.ok {
+			t.Fatalf("Match(%q, %q) = %v, want %v", c.tmpl, c.path, ok, c.ok)
+		}
+		if c.param != "" && params["id"] != c.param {
+			t.Fatalf("Match(%q, %q) id = %q, want %q", c.tmpl, c.path, params["id"], c.param)
+		}
+	}
+	if !IsTemplate("/a/{b}") || IsTemplate("/a/b") {
+		t.Fatal("IsTemplate mismatch")
+	}
+}
+
+func TestBest(t *testing.T) {
+	templates := []string{"/users/{id}/{tab}", "/users/{id}", "/users/me", "/users/{id}/orders"}
+	cases := map[string]int{
+		"/users/me":         2,
+		"/users/42":         1,
+		"/users/42/orders":  3,
+		"/users/42/profile": 0,
+		"/orders":           -1,
+	}
+	for path, want := range cases {
+		if got := Best(templates, path); got != want {
+			t.Fatalf("Best(%q) = %d, want %d", path, got, want)
+		}
+	}
+}
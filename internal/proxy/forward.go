@@ -0,0 +1,273 @@
+package proxy
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Forward is an HTTP(S) forward proxy. Requests to allowlisted hosts are
+// recorded; HTTPS CONNECT tunnels to those hosts are intercepted with leaf
+// certificates minted by the local CA. Everything else is passed through
+// untouched.
+type Forward struct {
+	// Transport sends intercepted requests upstream; defaults to a
+	// transport that ignores proxy environment variables.
+	Transport http.RoundTripper
+
+	rec   *Recorder
+	ca    *CA
+	allow []string
+}
+
+// NewForward creates a forward proxy recording through rec. allowHosts
+// accepts exact host names or "*.example.com" wildcards.
+func NewForward(rec *Recorder, ca *CA, allowHosts []string) (*Forward, error) {
+	if rec == nil {
+		return nil, errors.New("recorder is nil")
+	}
+	if ca == nil {
+		return nil, errors.New("CA is nil")
+	}
+	allow := make([]string, 0, len(allowHosts))
+	for _, h := range allowHosts {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if h != "" {
+			allow = append(allow, h)
+		}
+	}
+	if len(allow) == 0 {
+		return nil, errors.New("at least one allowed host is required")
+	}
+	return &Forward{
+		Transport: &http.Transport{
+			Proxy:               nil,
+			TLSHandshakeTimeout: 10 * time.Second,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		rec:   rec,
+		ca:    ca,
+		allow: allow,
+	}, nil
+}
+
+func (f *Forward) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		f.handleConnect(w, r)
+		return
+	}
+	if !r.URL.IsAbs() {
+		http.Error(w, "this is a forward proxy; request an absolute URL", http.StatusBadRequest)
+		return
+	}
+	f.forward(w, r, f.allowed(r.URL.Host))
+}
+
+// forward relays a plain-HTTP proxy request, recording it when record is set.
+func (f *Forward) forward(w http.ResponseWriter, r *http.Request, record bool) {
+	out := r.Clone(r.Context())
+	out.RequestURI = ""
+	removeHopHeaders(out.Header)
+
+	var ex *exchange
+	if record {
+		ex = f.rec.begin(out, out.URL.Scheme, displayHost(out.URL.Host, out.URL.Scheme))
+	}
+	resp, err := f.Transport.RoundTrip(out)
+	if err != nil {
+		if ex != nil {
+			ex.fail(http.StatusBadGateway)
+		}
+		http.Error(w, "upstream error: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	if ex != nil {
+		ex.respond(resp)
+	}
+	defer resp.Body.Close()
+	removeHopHeaders(resp.Header)
+	for k, vs := range resp.Header {
+		for _, v := range vs {
+			w.Header().Add(k, v)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_ = copyFlushing(w, resp.Body)
+}
+
+func (f *Forward) handleConnect(w http.ResponseWriter, r *http.Request) {
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "hijacking not supported", http.StatusInternalServerError)
+		return
+	}
+	hostPort := r.Host
+	if !strings.Contains(hostPort, ":") {
+		hostPort += ":443"
+	}
+	clientConn, _, err := hj.Hijack()
+	if err != nil {
+		return
+	}
+	defer clientConn.Close()
+	if _, err := io.WriteString(clientConn, "HTTP/1.1 200 Connection Established\r\n\r\n"); err != nil {
+		return
+	}
+	if !f.allowed(hostPort) {
+		tunnel(clientConn, hostPort)
+		return
+	}
+	f.intercept(clientConn, hostPort)
+}
+
+// intercept terminates TLS with a CA-signed leaf and relays each HTTP/1.1
+// request on the connection upstream, recording it.
+func (f *Forward) intercept(clientConn net.Conn, hostPort string) {
+	host, _, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return
+	}
+	leaf, err := f.ca.Leaf(host)
+	if err != nil {
+		return
+	}
+	tlsConn := tls.Server(clientConn, &tls.Config{
+		Certificates: []tls.Certificate{*leaf},
+		NextProtos:   []string{"http/1.1"},
+	})
+	if err := tlsConn.Handshake(); err != nil {
+		return
+	}
+	defer tlsConn.Close()
+
+	br := bufio.NewReader(tlsConn)
+	for {
+		req, err := http.ReadRequest(br)
+		if err != nil {
+			return
+		}
+		req.URL.Scheme = "https"
+		req.URL.Host = hostPort
+		req.RequestURI = ""
+		removeHopHeaders(req.Header)
+
+		ex := f.rec.begin(req, "https", displayHost(hostPort, "https"))
+		resp, err := f.Transport.RoundTrip(req)
+		if err != nil {
+			ex.fail(http.StatusBadGateway)
+			resp = &http.Response{
+				StatusCode: http.StatusBadGateway,
+				ProtoMajor: 1,
+				ProtoMinor: 1,
+				Header:     http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+				Body:       io.NopCloser(strings.NewReader("upstream error: " + err.Error())),
+				Close:      true,
+			}
+		} else {
+			ex.respond(resp)
+		}
+		removeHopHeaders(resp.Header)
+		writeErr := resp.Write(tlsConn)
+		_ = resp.Body.Close()
+		if writeErr != nil || req.Close || resp.Close {
+			return
+		}
+	}
+}
+
+// allowed reports whether host (with or without port) matches the allowlist.
+func (f *Forward) allowed(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+	for _, pattern := range f.allow {
+		if strings.HasPrefix(pattern, "*.") {
+			if strings.HasSuffix(host, pattern[1:]) {
+				return true
+			}
+			continue
+		}
+		if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// tunnel blindly pipes bytes between the client and hostPort.
+func tunnel(clientConn net.Conn, hostPort string) {
+	upstream, err := net.DialTimeout("tcp", hostPort, 10*time.Second)
+	if err != nil {
+		return
+	}
+	defer upstream.Close()
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(upstream, clientConn)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(clientConn, upstream)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+var hopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+func removeHopHeaders(h http.Header) {
+	for _, k := range hopHeaders {
+		h.Del(k)
+	}
+}
+
+// displayHost drops the scheme's default port, matching url.URL.Host as
+// recorded by the HAR importer.
+func displayHost(hostPort, scheme string) string {
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return hostPort
+	}
+	if (scheme == "https" && port == "443") || (scheme == "http" && port == "80") {
+		return host
+	}
+	return hostPort
+}
+
+func copyFlushing(w http.ResponseWriter, body io.Reader) error {
+	flusher, _ := w.(http.Flusher)
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return werr
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCASaveLoadAndLeaf(t *testing.T) {
+	ca, err := NewCA("apidoc test CA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(t.TempDir(), "ca")
+	if err := ca.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadCA(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(loaded.CertPEM()) != string(ca.CertPEM()) {
+		t.Fatalf("loaded CA cert differs")
+	}
+	leaf, err := loaded.Leaf("api.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(ca.CertPEM())
+	if _, err := leaf.Leaf.Verify(x509.VerifyOptions{DNSName: "api.example.com", Roots: pool}); err != nil {
+		t.Fatalf("leaf does not verify against CA: %v", err)
+	}
+	again, _ := loaded.Leaf("api.example.com")
+	if again != leaf {
+		t.Fatalf("expected cached leaf")
+	}
+}
+
+func TestForwardProxyInterceptsAllowedHTTPS(t *testing.T) {
+	upstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"path":"`+r.URL.Path+`"}`)
+	}))
+	defer upstream.Close()
+
+	st := newTestStore(t)
+	ca, err := NewCA("apidoc test CA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := NewRecorder(st, "proxy", "forward", "127.0.0.1", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fwd, err := NewForward(rec, ca, []string{"127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fwd.Transport = upstream.Client().Transport
+	front := httptest.NewServer(fwd)
+	defer front.Close()
+
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(ca.CertPEM())
+	proxyURL, _ := url.Parse(front.URL)
+	client := &http.Client{Transport: &http.Transport{
+		Proxy:           http.ProxyURL(proxyURL),
+		TLSClientConfig: &tls.Config{RootCAs: pool},
+	}}
+	for _, p := range []string{"/v1/orders?limit=2", "/v1/orders/7"} {
+		resp, err := client.Get(upstream.URL + p)
+		if err != nil {
+			t.Fatalf("get via proxy: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if !strings.Contains(string(body), strings.Split(p, "?")[0]) {
+			t.Fatalf("unexpected body %s", body)
+		}
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	logs, err := st.GetLogs(rec.Session().ID)
+	if err != nil || len(logs) != 2 {
+		t.Fatalf("expected 2 recorded logs, got %d err=%v", len(logs), err)
+	}
+	if logs[0].Path != "/v1/orders" || logs[0].QueryParams["limit"][0] != "2" || logs[0].ResponseBody != `{"path":"/v1/orders"}` {
+		t.Fatalf("unexpected first log: %+v", logs[0])
+	}
+	if logs[1].Seq != 2 || logs[1].Host != strings.TrimPrefix(upstream.URL, "https://") {
+		t.Fatalf("unexpected second log: %+v", logs[1])
+	}
+}
+
+func TestForwardProxyTunnelsOtherHosts(t *testing.T) {
+	upstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer upstream.Close()
+
+	st := newTestStore(t)
+	ca, _ := NewCA("apidoc test CA")
+	rec, _ := NewRecorder(st, "proxy", "forward", "api.example.com", 0)
+	fwd, err := NewForward(rec, ca, []string{"api.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	front := httptest.NewServer(fwd)
+	defer front.Close()
+
+	// The client only trusts the real upstream cert, so this succeeds only
+	// if the tunnel is passed through without interception.
+	proxyURL, _ := url.Parse(front.URL)
+	client := upstream.Client()
+	client.Transport.(*http.Transport).Proxy = http.ProxyURL(proxyURL)
+	resp, err := client.Get(upstream.URL + "/private")
+	if err != nil {
+		t.Fatalf("tunnel: %v", err)
+	}
+	resp.Body.Close()
+	_ = rec.Close()
+	if logs, _ := st.GetLogs(rec.Session().ID); len(logs) != 0 {
+		t.Fatalf("expected nothing recorded for non-allowed host, got %d", len(logs))
+	}
+}
+
+func TestForwardProxyRecordsPlainHTTP(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Proxy-Connection") != "" {
+			t.Errorf("hop-by-hop header leaked upstream")
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer upstream.Close()
+
+	st := newTestStore(t)
+	ca, _ := NewCA("apidoc test CA")
+	rec, _ := NewRecorder(st, "proxy", "forward", "127.0.0.1", 0)
+	fwd, _ := NewForward(rec, ca, []string{"*.example.com", "127.0.0.1"})
+	front := httptest.NewServer(fwd)
+	defer front.Close()
+
+	proxyURL, _ := url.Parse(front.URL)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+	req, _ := http.NewRequest(http.MethodPost, upstream.URL+"/v1/jobs", strings.NewReader(`{"a":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Proxy-Connection", "keep-alive")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("unexpected status %d", resp.StatusCode)
+	}
+	_ = rec.Close()
+	logs, _ := st.GetLogs(rec.Session().ID)
+	if len(logs) != 1 || logs[0].RequestBody != `{"a":1}` || logs[0].StatusCode != http.StatusAccepted {
+		t.Fatalf("unexpected logs: %+v", logs)
+	}
+}
+
+func TestForwardAllowlist(t *testing.T) {
+	f := &Forward{allow: []string{"api.example.com", "*.internal.example.com"}}
+	cases := map[string]bool{
+		"api.example.com:443":       true,
+		"API.example.com":           true,
+		"svc.internal.example.com":  true,
+		"internal.example.com":      false,
+		"evil-api.example.com":      false,
+		"tracker.thirdparty.io:443": false,
+	}
+	for host, want := range cases {
+		if got := f.allowed(host); got != want {
+			t.Fatalf("allowed(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
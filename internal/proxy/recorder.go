@@ -0,0 +1,190 @@
+package proxy
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/yourorg/apidoc/internal/har"
+	"github.com/yourorg/apidoc/internal/httplog"
+	"github.com/yourorg/apidoc/internal/store"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// DefaultMaxBodyBytes caps how much of each request/response body is kept.
+const DefaultMaxBodyBytes = 1 << 20
+
+// Recorder writes proxied exchanges into a live session as they complete.
+type Recorder struct {
+	store        store.Store
+	session      *types.Session
+	maxBodyBytes int64
+
+	mu     sync.Mutex
+	seq    int
+	count  int
+	closed bool
+	errs   []error
+}
+
+// NewRecorder creates a session for source/scenario/host and marks it as
+// recording until Close is called.
+func NewRecorder(st store.Store, source, scenario, host string, maxBodyBytes int64) (*Recorder, error) {
+	if st == nil {
+		return nil, errors.New("store is nil")
+	}
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultMaxBodyBytes
+	}
+	sess, err := st.CreateSession(source, scenario, host)
+	if err != nil {
+		return nil, err
+	}
+	if err := st.UpdateSessionStatus(sess.ID, "recording"); err != nil {
+		return nil, err
+	}
+	sess.Status = "recording"
+	return &Recorder{store: st, session: sess, maxBodyBytes: maxBodyBytes}, nil
+}
+
+// Session returns the session being recorded.
+func (r *Recorder) Session() *types.Session {
+	return r.session
+}
+
+// Count returns the number of exchanges recorded so far.
+func (r *Recorder) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.count
+}
+
+// nextSeq reserves the next sequence number.
+func (r *Recorder) nextSeq() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	return r.seq
+}
+
+// Record persists the log. Proxied exchanges are numbered when their request
+// arrives, so concurrent exchanges keep request order; a log without a
+// sequence number gets the next one.
+func (r *Recorder) Record(l types.TrafficLog) error {
+	if l.Seq == 0 {
+		l.Seq = r.nextSeq()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return errors.New("recorder is closed")
+	}
+	l.SessionID = r.session.ID
+	if l.CallCount == 0 {
+		l.CallCount = 1
+	}
+	if err := r.store.SaveLogs(r.session.ID, []types.TrafficLog{l}); err != nil {
+		r.errs = append(r.errs, err)
+		return err
+	}
+	r.count++
+	return nil
+}
+
+// Close finalizes the session so it can be generated like an imported one.
+// It returns any errors encountered while recording.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	if err := r.store.UpdateSessionStatus(r.session.ID, "imported"); err != nil {
+		r.errs = append(r.errs, err)
+	}
+	return errors.Join(r.errs...)
+}
+
+// exchange accumulates one request/response pair while it is in flight.
+type exchange struct {
+	rec     *Recorder
+	start   time.Time
+	log     types.TrafficLog
+	reqBody *httplog.Buffer
+	once    sync.Once
+}
+
+func (r *Recorder) begin(req *http.Request, scheme, host string) *exchange {
+	ex := &exchange{
+		rec:   r,
+		start: time.Now(),
+		log: types.TrafficLog{
+			Seq:            r.nextSeq(),
+			Timestamp:      time.Now().UTC(),
+			Method:         req.Method,
+			Scheme:         scheme,
+			Host:           host,
+			Path:           req.URL.Path,
+			HTTPVersion:    req.Proto,
+			QueryParams:    nilIfEmpty(req.URL.Query()),
+			RequestHeaders: types.Headers(req.Header.Clone()),
+			RequestCookies: httplog.Cookies(req.Cookies()),
+			ContentType:    req.Header.Get("Content-Type"),
+		},
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		ex.reqBody = httplog.NewBuffer(r.maxBodyBytes)
+		req.Body = httplog.NewBody(req.Body, ex.reqBody, nil)
+	}
+	return ex
+}
+
+// respond wraps resp.Body so the exchange is recorded once the client has
+// consumed (or abandoned) the streamed body.
+func (ex *exchange) respond(resp *http.Response) {
+	ex.log.StatusCode = resp.StatusCode
+	ex.log.HTTPVersion = resp.Proto
+	ex.log.ResponseHeaders = types.Headers(resp.Header.Clone())
+	ex.log.ResponseCookies = httplog.Cookies(resp.Cookies())
+	ex.log.ResponseContentType = resp.Header.Get("Content-Type")
+	buf := httplog.NewBuffer(ex.rec.maxBodyBytes)
+	resp.Body = httplog.NewBody(resp.Body, buf, func() {
+		if body, overflow := buf.Captured(); !overflow {
+			ex.log.ResponseBody, _ = har.DecodeBody(string(body), "", ex.log.ResponseContentType)
+		}
+		ex.finish()
+	})
+}
+
+// fail records an exchange that never got an upstream response.
+func (ex *exchange) fail(status int) {
+	ex.log.StatusCode = status
+	ex.finish()
+}
+
+func (ex *exchange) finish() {
+	ex.once.Do(ex.record)
+}
+
+func (ex *exchange) record() {
+	ex.log.LatencyMs = time.Since(ex.start).Milliseconds()
+	ex.log.RequestBodyEncoding = "plain"
+	if ex.reqBody != nil {
+		body, overflow := ex.reqBody.Captured()
+		if overflow {
+			ex.log.RequestBodyEncoding = "omitted"
+		} else {
+			ex.log.RequestBody, ex.log.RequestBodyEncoding = har.DecodeBody(string(body), "", ex.log.ContentType)
+		}
+	}
+	_ = ex.rec.Record(ex.log)
+}
+
+func nilIfEmpty(v map[string][]string) map[string][]string {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
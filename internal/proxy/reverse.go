@@ -0,0 +1,49 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+)
+
+type exchangeKey struct{}
+
+// NewReverse returns a reverse proxy to target that records every exchange
+// through rec. Streaming and chunked responses are forwarded as they arrive;
+// only the first maxBodyBytes of each body are kept for the log.
+func NewReverse(target *url.URL, rec *Recorder) (http.Handler, error) {
+	if target == nil || target.Scheme == "" || target.Host == "" {
+		return nil, errors.New("target must be an absolute URL")
+	}
+	if rec == nil {
+		return nil, errors.New("recorder is nil")
+	}
+	rp := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.SetXForwarded()
+			pr.Out.Host = target.Host
+			if ex, ok := pr.In.Context().Value(exchangeKey{}).(*exchange); ok {
+				ex.log.Path = pr.Out.URL.Path
+			}
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			if ex, ok := resp.Request.Context().Value(exchangeKey{}).(*exchange); ok {
+				ex.respond(resp)
+			}
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			if ex, ok := r.Context().Value(exchangeKey{}).(*exchange); ok {
+				ex.fail(http.StatusBadGateway)
+			}
+			http.Error(w, "upstream error: "+err.Error(), http.StatusBadGateway)
+		},
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ex := rec.begin(r, target.Scheme, target.Host)
+		rp.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), exchangeKey{}, ex)))
+	}), nil
+}
@@ -0,0 +1,197 @@
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/apidoc/internal/store"
+)
+
+func newTestStore(t *testing.T) *store.SQLiteStore {
+	t.Helper()
+	s, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "apidoc.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestReverseProxyRecordsExchanges(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/users":
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Add("Set-Cookie", "sid=abc; HttpOnly")
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"echo":%s}`, body)
+		case "/api/stream":
+			w.Header().Set("Content-Type", "application/json")
+			flusher := w.(http.Flusher)
+			for i := 0; i < 3; i++ {
+				fmt.Fprintf(w, `{"chunk":%d}`+"\n", i)
+				flusher.Flush()
+			}
+		case "/api/big":
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte(strings.Repeat("x", 64)))
+		}
+	}))
+	defer upstream.Close()
+
+	st := newTestStore(t)
+	target, _ := url.Parse(upstream.URL)
+	rec, err := NewRecorder(st, "proxy", "proxy test", target.Host, 48)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sess, _ := st.GetSession(rec.Session().ID); sess.Status != "recording" {
+		t.Fatalf("expected recording status, got %s", sess.Status)
+	}
+	handler, err := NewReverse(target, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	front := httptest.NewServer(handler)
+	defer front.Close()
+
+	resp, err := http.Post(front.URL+"/api/users?team=a", "application/json", strings.NewReader(`{"name":"bob"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || string(got) != `{"echo":{"name":"bob"}}` {
+		t.Fatalf("unexpected proxied response %d %s", resp.StatusCode, got)
+	}
+	for _, p := range []string{"/api/stream", "/api/big"} {
+		resp, err := http.Get(front.URL + p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	if err := rec.Close(); err != nil {
+		t.Fatalf("close recorder: %v", err)
+	}
+	sess, err := st.GetSession(rec.Session().ID)
+	if err != nil || sess.Status != "imported" || sess.LogCount != 3 {
+		t.Fatalf("unexpected session after close: %+v err=%v", sess, err)
+	}
+	logs, err := st.GetLogs(sess.ID)
+	if err != nil || len(logs) != 3 {
+		t.Fatalf("expected 3 logs, got %d err=%v", len(logs), err)
+	}
+	byPath := map[string]int{}
+	for i, l := range logs {
+		byPath[l.Path] = i
+		if l.Seq != i+1 {
+			t.Fatalf("expected sequential seq, got %d at %d", l.Seq, i)
+		}
+	}
+	users := logs[byPath["/api/users"]]
+	if users.Method != "POST" || users.StatusCode != 201 || users.RequestBody != `{"name":"bob"}` || users.QueryParams["team"][0] != "a" {
+		t.Fatalf("unexpected users log: %+v", users)
+	}
+	if len(users.ResponseCookies) != 1 || users.ResponseCookies[0].Name != "sid" {
+		t.Fatalf("expected response cookie recorded, got %+v", users.ResponseCookies)
+	}
+	if stream := logs[byPath["/api/stream"]]; !strings.Contains(stream.ResponseBody, `{"chunk":2}`) {
+		t.Fatalf("expected streamed body captured, got %q", stream.ResponseBody)
+	}
+	if big := logs[byPath["/api/big"]]; big.ResponseBody != "" || big.StatusCode != 200 {
+		t.Fatalf("expected oversized body dropped, got %q", big.ResponseBody)
+	}
+}
+
+func TestReverseProxySeqFollowsRequestOrder(t *testing.T) {
+	arrived, release := make(chan struct{}), make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(arrived)
+			<-release
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer upstream.Close()
+
+	st := newTestStore(t)
+	target, _ := url.Parse(upstream.URL)
+	rec, err := NewRecorder(st, "proxy", "order", target.Host, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler, err := NewReverse(target, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	front := httptest.NewServer(handler)
+	defer front.Close()
+
+	get := func(p string) {
+		resp, err := http.Get(front.URL + p)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	done := make(chan struct{})
+	go func() {
+		get("/slow")
+		close(done)
+	}()
+	<-arrived
+	get("/fast")
+	close(release)
+	<-done
+
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	logs, err := st.GetLogs(rec.Session().ID)
+	if err != nil || len(logs) != 2 {
+		t.Fatalf("expected 2 logs, got %d err=%v", len(logs), err)
+	}
+	if logs[0].Path != "/slow" || logs[0].Seq != 1 || logs[1].Path != "/fast" || logs[1].Seq != 2 {
+		t.Fatalf("seq should follow request order: %s=%d %s=%d", logs[0].Path, logs[0].Seq, logs[1].Path, logs[1].Seq)
+	}
+}
+
+func TestReverseProxyUpstreamDown(t *testing.T) {
+	st := newTestStore(t)
+	target, _ := url.Parse("http://127.0.0.1:1")
+	rec, err := NewRecorder(st, "proxy", "down", target.Host, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler, _ := NewReverse(target, rec)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if w.Code != http.StatusBadGateway {
+		t.Fatalf("expected 502, got %d", w.Code)
+	}
+	_ = rec.Close()
+	logs, _ := st.GetLogs(rec.Session().ID)
+	if len(logs) != 1 || logs[0].StatusCode != http.StatusBadGateway {
+		t.Fatalf("expected failed exchange recorded, got %+v", logs)
+	}
+}
+
+func TestNewReverseRejectsRelativeTarget(t *testing.T) {
+	st := newTestStore(t)
+	rec, _ := NewRecorder(st, "proxy", "bad", "x", 0)
+	if _, err := NewReverse(&url.URL{Path: "/api"}, rec); err == nil {
+		t.Fatalf("expected error for relative target")
+	}
+}
@@ -0,0 +1,246 @@
+// Package replay re-issues a recorded session against another base URL,
+// carrying values such as tokens and IDs from earlier responses into later
+// requests, and compares every replayed response with the original.
+package replay
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/yourorg/apidoc/internal/dataflow"
+	"github.com/yourorg/apidoc/internal/httplog"
+	"github.com/yourorg/apidoc/internal/store"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// Options configures a replay run.
+type Options struct {
+	// BaseURL replaces the scheme and host of every request; its path is
+	// prefixed to the recorded paths.
+	BaseURL *url.URL
+	// Client sends the requests; defaults to a client with a 30s timeout
+	// that does not follow redirects.
+	Client *http.Client
+}
+
+// Result compares one replayed request with its recording.
+type Result struct {
+	Seq            int      `json:"seq"`
+	Method         string   `json:"method"`
+	Path           string   `json:"path"`
+	OriginalStatus int      `json:"original_status"`
+	ReplayStatus   int      `json:"replay_status"`
+	StatusMatch    bool     `json:"status_match"`
+	ShapeMatch     bool     `json:"shape_match"`
+	ShapeDiffs     []string `json:"shape_diffs,omitempty"`
+	Substitutions  int      `json:"substitutions"`
+	Error          string   `json:"error,omitempty"`
+}
+
+// Report is the outcome of a replay.
+type Report struct {
+	OriginalSessionID string   `json:"original_session_id"`
+	SessionID         string   `json:"session_id"`
+	BaseURL           string   `json:"base_url"`
+	Results           []Result `json:"results"`
+}
+
+// Passed reports whether every request matched in status and shape.
+func (r *Report) Passed() bool {
+	for _, res := range r.Results {
+		if !res.StatusMatch || !res.ShapeMatch || res.Error != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Run replays logs in Seq order and stores the replayed traffic as a new
+// session with source "replay".
+func Run(ctx context.Context, st store.Store, sess *types.Session, logs []types.TrafficLog, opts Options) (*Report, error) {
+	if st == nil {
+		return nil, errors.New("store is nil")
+	}
+	if opts.BaseURL == nil || opts.BaseURL.Scheme == "" || opts.BaseURL.Host == "" {
+		return nil, errors.New("base URL must be absolute")
+	}
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{
+			Timeout: 30 * time.Second,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	}
+
+	report := &Report{OriginalSessionID: sess.ID, BaseURL: opts.BaseURL.String()}
+	captured := newCaptures()
+	received := receivedValues(logs)
+	replayed := make([]types.TrafficLog, 0, len(logs))
+	for _, orig := range logs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		res := Result{Seq: orig.Seq, Method: orig.Method, Path: orig.Path, OriginalStatus: orig.StatusCode}
+		values := captured.valuesFor(received[orig.Seq])
+		req, subs, err := buildRequest(ctx, opts.BaseURL, orig, values)
+		res.Substitutions = subs
+		if err != nil {
+			res.Error = err.Error()
+			report.Results = append(report.Results, res)
+			continue
+		}
+
+		start := time.Now()
+		rl := requestLog(req, orig)
+		resp, err := client.Do(req)
+		if err != nil {
+			res.Error = err.Error()
+			report.Results = append(report.Results, res)
+			continue
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			res.Error = err.Error()
+		}
+		rl.LatencyMs = time.Since(start).Milliseconds()
+		rl.StatusCode = resp.StatusCode
+		rl.HTTPVersion = resp.Proto
+		rl.ResponseHeaders = types.Headers(resp.Header.Clone())
+		rl.ResponseCookies = httplog.Cookies(resp.Cookies())
+		rl.ResponseContentType = resp.Header.Get("Content-Type")
+		rl.ResponseBody = string(body)
+		replayed = append(replayed, rl)
+
+		captured.record(orig, rl)
+		res.ReplayStatus = resp.StatusCode
+		res.StatusMatch = resp.StatusCode == orig.StatusCode
+		res.ShapeDiffs = compareShapes(orig.ResponseBody, rl.ResponseBody)
+		res.ShapeMatch = len(res.ShapeDiffs) == 0
+		report.Results = append(report.Results, res)
+	}
+
+	scenario := fmt.Sprintf("replay of %s", sess.ID)
+	if sess.Scenario != "" {
+		scenario += ": " + sess.Scenario
+	}
+	out, err := st.CreateSession("replay", scenario, opts.BaseURL.Host)
+	if err != nil {
+		return nil, err
+	}
+	for i := range replayed {
+		replayed[i].Seq = i + 1
+	}
+	if err := st.SaveLogs(out.ID, replayed); err != nil {
+		return nil, err
+	}
+	report.SessionID = out.ID
+	return report, nil
+}
+
+// receivedValues lists, per request Seq, the values it sent that an earlier
+// response had returned, keyed by the Seq of that response. Only these body
+// values are substituted, so a value that merely looks the same elsewhere is
+// left alone.
+func receivedValues(logs []types.TrafficLog) map[int]map[int][]string {
+	out := make(map[int]map[int][]string)
+	for _, f := range dataflow.Trace(logs) {
+		if out[f.ToSeq] == nil {
+			out[f.ToSeq] = make(map[int][]string)
+		}
+		out[f.ToSeq][f.FromSeq] = append(out[f.ToSeq][f.FromSeq], f.Value)
+	}
+	return out
+}
+
+// skipHeaders are recorded request headers that must not be replayed as-is.
+var skipHeaders = map[string]bool{
+	"host":              true,
+	"content-length":    true,
+	"connection":        true,
+	"accept-encoding":   true,
+	"transfer-encoding": true,
+	"keep-alive":        true,
+	"upgrade":           true,
+	"te":                true,
+}
+
+func buildRequest(ctx context.Context, base *url.URL, orig types.TrafficLog, values map[string]string) (*http.Request, int, error) {
+	if orig.RequestBodyEncoding == "omitted" {
+		return nil, 0, errors.New("request body was not recorded")
+	}
+	s := &substituter{values: values}
+	u := *base
+	u.Path = strings.TrimRight(base.Path, "/") + s.path(orig.Path)
+	u.RawPath = ""
+	q := url.Values{}
+	for k, vs := range orig.QueryParams {
+		for _, v := range vs {
+			q.Add(k, s.text(v))
+		}
+	}
+	u.RawQuery = q.Encode()
+
+	var body io.Reader
+	if orig.RequestBody != "" {
+		body = bytes.NewReader([]byte(s.body(orig.RequestBody, orig.ContentType)))
+	}
+	req, err := http.NewRequestWithContext(ctx, orig.Method, u.String(), body)
+	if err != nil {
+		return nil, 0, err
+	}
+	for name, vs := range orig.RequestHeaders {
+		if skipHeaders[strings.ToLower(name)] || strings.HasPrefix(name, ":") {
+			continue
+		}
+		for _, v := range vs {
+			if strings.EqualFold(name, "Cookie") {
+				v = s.cookies(v)
+			}
+			req.Header.Add(name, s.text(v))
+		}
+	}
+	if req.Header.Get("Cookie") == "" {
+		for _, c := range orig.RequestCookies {
+			req.AddCookie(&http.Cookie{Name: c.Name, Value: s.text(c.Value)})
+		}
+	}
+	return req, s.count, nil
+}
+
+// requestLog starts the replay log from the request actually sent.
+func requestLog(req *http.Request, orig types.TrafficLog) types.TrafficLog {
+	l := types.TrafficLog{
+		Timestamp:           time.Now().UTC(),
+		Method:              req.Method,
+		Scheme:              req.URL.Scheme,
+		Host:                req.URL.Host,
+		Path:                req.URL.Path,
+		RequestHeaders:      types.Headers(req.Header.Clone()),
+		ContentType:         req.Header.Get("Content-Type"),
+		RequestBodyEncoding: "plain",
+		CallCount:           1,
+	}
+	if q := req.URL.Query(); len(q) > 0 {
+		l.QueryParams = q
+	}
+	if req.GetBody != nil {
+		if rc, err := req.GetBody(); err == nil {
+			b, _ := io.ReadAll(rc)
+			l.RequestBody = string(b)
+		}
+	}
+	if l.ContentType == "" {
+		l.ContentType = orig.ContentType
+	}
+	return l
+}
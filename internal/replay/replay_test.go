@@ -0,0 +1,141 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourorg/apidoc/internal/store"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+func newTestStore(t *testing.T) *store.SQLiteStore {
+	t.Helper()
+	s, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "apidoc.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func recorded() []types.TrafficLog {
+	now := time.Now().UTC()
+	return []types.TrafficLog{
+		{
+			Seq: 1, Timestamp: now, Method: "POST", Host: "prod.example.com", Path: "/api/login",
+			RequestHeaders: types.Headers{"Content-Type": {"application/json"}}, ContentType: "application/json",
+			RequestBody: `{"user":"alice"}`, StatusCode: 200,
+			ResponseBody:    `{"token":"prod-token-aaaaaaaa"}`,
+			ResponseCookies: []types.Cookie{{Name: "sid", Value: "prod-sid"}},
+		},
+		{
+			Seq: 2, Timestamp: now, Method: "POST", Host: "prod.example.com", Path: "/api/orders",
+			RequestHeaders: types.Headers{"Authorization": {"Bearer prod-token-aaaaaaaa"}, "Cookie": {"sid=prod-sid"}},
+			ContentType:    "application/json", RequestBody: `{"sku":"x1"}`, StatusCode: 201,
+			ResponseBody: `{"id":1001,"status":"new"}`,
+		},
+		{
+			Seq: 3, Timestamp: now, Method: "GET", Host: "prod.example.com", Path: "/api/orders/1001",
+			RequestHeaders: types.Headers{"Authorization": {"Bearer prod-token-aaaaaaaa"}},
+			StatusCode:     200, ResponseBody: `{"id":1001,"status":"new","items":[{"sku":"x1"}]}`,
+		},
+	}
+}
+
+func TestRunSubstitutesAndCompares(t *testing.T) {
+	var seen []string
+	staging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		cookie, _ := r.Cookie("sid")
+		sid := ""
+		if cookie != nil {
+			sid = cookie.Value
+		}
+		seen = append(seen, r.Method+" "+r.URL.Path+" "+r.Header.Get("Authorization")+" sid="+sid+" "+string(body))
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/v2/api/login":
+			http.SetCookie(w, &http.Cookie{Name: "sid", Value: "stg-sid"})
+			_, _ = w.Write([]byte(`{"token":"stg-token-bbbbbbbb"}`))
+		case r.URL.Path == "/v2/api/orders":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":2002,"status":"new"}`))
+		case r.URL.Path == "/v2/api/orders/2002" && r.Header.Get("Authorization") == "Bearer stg-token-bbbbbbbb":
+			_, _ = w.Write([]byte(`{"id":2002,"status":1}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer staging.Close()
+
+	st := newTestStore(t)
+	sess, _ := st.CreateSession("har", "checkout", "prod.example.com")
+	logs := recorded()
+	_ = st.SaveLogs(sess.ID, logs)
+
+	base, _ := url.Parse(staging.URL + "/v2")
+	rep, err := Run(context.Background(), st, sess, logs, Options{BaseURL: base})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(rep.Results))
+	}
+	if !strings.Contains(seen[1], "Bearer stg-token-bbbbbbbb sid=stg-sid") {
+		t.Fatalf("token and cookie not substituted: %q", seen[1])
+	}
+	third := rep.Results[2]
+	if third.ReplayStatus != http.StatusOK || !third.StatusMatch {
+		t.Fatalf("order id not substituted into path: %+v (seen %v)", third, seen)
+	}
+	if third.ShapeMatch {
+		t.Fatal("expected shape differences")
+	}
+	want := []string{"missing $.items", "missing $.items[]", "missing $.items[].sku", "type $.status: string → number"}
+	if strings.Join(third.ShapeDiffs, "|") != strings.Join(want, "|") {
+		t.Fatalf("shape diffs = %v, want %v", third.ShapeDiffs, want)
+	}
+	if !rep.Results[0].ShapeMatch || !rep.Results[1].StatusMatch || rep.Passed() {
+		t.Fatalf("unexpected comparison: %+v", rep.Results)
+	}
+
+	out, err := st.GetSession(rep.SessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Source != "replay" || out.LogCount != 3 {
+		t.Fatalf("unexpected replay session: %+v", out)
+	}
+	replayed, _ := st.GetLogs(rep.SessionID)
+	if replayed[2].Path != "/v2/api/orders/2002" || replayed[2].StatusCode != http.StatusOK {
+		t.Fatalf("unexpected replayed log: %+v", replayed[2])
+	}
+	var created map[string]any
+	_ = json.Unmarshal([]byte(replayed[1].ResponseBody), &created)
+	if created["id"] != float64(2002) {
+		t.Fatalf("response body not recorded: %q", replayed[1].ResponseBody)
+	}
+}
+
+func TestSubstitutesOnlyReceivedValues(t *testing.T) {
+	logs := []types.TrafficLog{
+		{Seq: 1, Method: "POST", Path: "/api/orders", RequestBody: `{"sku":"sku-12345"}`, ResponseBody: `{"id":1001,"sku":"sku-12345","status":"new"}`},
+		{Seq: 2, Method: "POST", Path: "/api/orders/1001/items", RequestBody: `{"sku":"sku-12345","status":"new"}`},
+	}
+	c := newCaptures()
+	c.record(logs[0], types.TrafficLog{ResponseBody: `{"id":2002,"sku":"sku-67890","status":"NEW"}`})
+	// The sku was sent before the response echoed it and "new" is not
+	// distinctive, so only the id is carried over.
+	got := c.valuesFor(receivedValues(logs)[2])
+	if len(got) != 1 || got["1001"] != "2002" {
+		t.Fatalf("values = %v, want only 1001 → 2002", got)
+	}
+}
@@ -0,0 +1,70 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// compareShapes lists structural differences between two JSON bodies:
+// fields missing from or added to the replay, and changed value types.
+// Array elements are merged into one "[]" shape. Non-JSON bodies only
+// differ when exactly one side is JSON.
+func compareShapes(original, replayed string) []string {
+	a, errA := decodeJSON(original)
+	b, errB := decodeJSON(replayed)
+	switch {
+	case errA != nil && errB != nil:
+		return nil
+	case errA != nil:
+		return []string{"replay returned JSON, original did not"}
+	case errB != nil:
+		return []string{"replay did not return JSON"}
+	}
+	sa, sb := map[string]string{}, map[string]string{}
+	shapeOf("$", a, sa)
+	shapeOf("$", b, sb)
+
+	var diffs []string
+	for path, ta := range sa {
+		tb, ok := sb[path]
+		switch {
+		case !ok:
+			diffs = append(diffs, "missing "+path)
+		case ta != tb && ta != "null" && tb != "null":
+			diffs = append(diffs, fmt.Sprintf("type %s: %s → %s", path, ta, tb))
+		}
+	}
+	for path := range sb {
+		if _, ok := sa[path]; !ok {
+			diffs = append(diffs, "extra "+path)
+		}
+	}
+	sort.Strings(diffs)
+	return diffs
+}
+
+func shapeOf(path string, v any, out map[string]string) {
+	switch t := v.(type) {
+	case map[string]any:
+		out[path] = "object"
+		for k, child := range t {
+			shapeOf(path+"."+k, child, out)
+		}
+	case []any:
+		out[path] = "array"
+		for _, child := range t {
+			shapeOf(path+"[]", child, out)
+		}
+	case string:
+		out[path] = "string"
+	case json.Number:
+		out[path] = "number"
+	case bool:
+		out[path] = "boolean"
+	case nil:
+		if _, ok := out[path]; !ok {
+			out[path] = "null"
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/yourorg/apidoc/internal/dataflow"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// minInlineLen is the shortest value replaced inside larger strings such as
+// "Bearer <token>"; shorter values are only replaced when they make up the
+// whole path segment, query value or JSON field.
+const minInlineLen = 8
+
+// captures maps values seen in recorded responses to the values the
+// replayed responses returned in the same place.
+type captures struct {
+	// bySeq holds the JSON body pairs of each original Seq.
+	bySeq map[int]map[string]string
+	// session holds cookie and token header pairs; a later response
+	// replaces what an earlier one set, as a client's cookie jar would.
+	session map[string]string
+}
+
+func newCaptures() *captures {
+	return &captures{bySeq: make(map[int]map[string]string), session: make(map[string]string)}
+}
+
+// record pairs up JSON leaves, cookies and token-like headers of the
+// original and replayed responses.
+func (c *captures) record(orig, replayed types.TrafficLog) {
+	m := make(map[string]string)
+	a, errA := decodeJSON(orig.ResponseBody)
+	b, errB := decodeJSON(replayed.ResponseBody)
+	if errA == nil && errB == nil {
+		pairLeaves(a, b, m)
+	}
+	if len(m) > 0 {
+		c.bySeq[orig.Seq] = m
+	}
+	replayCookies := (&http.Response{Header: http.Header(replayed.ResponseHeaders)}).Cookies()
+	for _, oc := range orig.ResponseCookies {
+		for _, rc := range replayCookies {
+			if oc.Name == rc.Name {
+				addPair(c.session, oc.Value, rc.Value)
+			}
+		}
+	}
+	for name, vs := range orig.ResponseHeaders {
+		lower := strings.ToLower(name)
+		if !strings.Contains(lower, "token") && !strings.Contains(lower, "csrf") && !strings.Contains(lower, "session") && lower != "location" {
+			continue
+		}
+		if rv := replayed.ResponseHeaders.Get(name); rv != "" && len(vs) > 0 {
+			addPair(c.session, vs[0], rv)
+		}
+	}
+}
+
+// valuesFor returns the substitutions for a request: the cookies and token
+// headers set so far, plus the body values it received, keyed by the Seq of
+// the response each came from.
+func (c *captures) valuesFor(received map[int][]string) map[string]string {
+	out := make(map[string]string, len(c.session))
+	for k, v := range c.session {
+		out[k] = v
+	}
+	for seq, values := range received {
+		for _, v := range values {
+			if to, ok := c.bySeq[seq][v]; ok {
+				out[v] = to
+			}
+		}
+	}
+	return out
+}
+
+func pairLeaves(a, b any, m map[string]string) {
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok {
+			return
+		}
+		for k, v := range av {
+			if w, ok := bv[k]; ok {
+				pairLeaves(v, w, m)
+			}
+		}
+	case []any:
+		bv, ok := b.([]any)
+		if !ok {
+			return
+		}
+		for i := range av {
+			if i < len(bv) {
+				pairLeaves(av[i], bv[i], m)
+			}
+		}
+	case string:
+		if bv, ok := b.(string); ok {
+			addPair(m, av, bv)
+		}
+	case json.Number:
+		if bv, ok := b.(json.Number); ok {
+			addPair(m, av.String(), bv.String())
+		}
+	}
+}
+
+// addPair keeps values distinctive enough to be worth substituting, by the
+// same rule data flow tracing uses.
+func addPair(m map[string]string, from, to string) {
+	if to == "" || from == to || !dataflow.Distinctive(from) {
+		return
+	}
+	m[from] = to
+}
+
+// substituter rewrites one request using captured values.
+type substituter struct {
+	values map[string]string
+	count  int
+	inline []string
+}
+
+func (s *substituter) exact(v string) (string, bool) {
+	if to, ok := s.values[v]; ok {
+		s.count++
+		return to, true
+	}
+	return v, false
+}
+
+func (s *substituter) path(p string) string {
+	segs := strings.Split(p, "/")
+	for i, seg := range segs {
+		segs[i], _ = s.exact(seg)
+	}
+	return strings.Join(segs, "/")
+}
+
+// cookies replaces whole cookie values in a Cookie request header.
+func (s *substituter) cookies(header string) string {
+	parts := strings.Split(header, ";")
+	for i, part := range parts {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		if to, ok := s.exact(value); ok {
+			parts[i] = name + "=" + to
+		} else {
+			parts[i] = strings.TrimSpace(part)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// text replaces a whole value, or long captured values embedded in it.
+func (s *substituter) text(v string) string {
+	if to, ok := s.exact(v); ok {
+		return to
+	}
+	if s.inline == nil {
+		for k := range s.values {
+			if len(k) >= minInlineLen {
+				s.inline = append(s.inline, k)
+			}
+		}
+		// Longest first so a token is not clobbered by one of its substrings.
+		sort.Slice(s.inline, func(i, j int) bool {
+			if len(s.inline[i]) != len(s.inline[j]) {
+				return len(s.inline[i]) > len(s.inline[j])
+			}
+			return s.inline[i] < s.inline[j]
+		})
+	}
+	for _, k := range s.inline {
+		if strings.Contains(v, k) {
+			v = strings.ReplaceAll(v, k, s.values[k])
+			s.count++
+		}
+	}
+	return v
+}
+
+func (s *substituter) body(body, contentType string) string {
+	if strings.Contains(strings.ToLower(contentType), "json") || looksLikeJSON(body) {
+		if doc, err := decodeJSON(body); err == nil {
+			before := s.count
+			doc = s.jsonValue(doc)
+			if s.count == before {
+				return body
+			}
+			if out, err := json.Marshal(doc); err == nil {
+				return string(out)
+			}
+		}
+	}
+	return s.text(body)
+}
+
+func (s *substituter) jsonValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, child := range t {
+			t[k] = s.jsonValue(child)
+		}
+		return t
+	case []any:
+		for i, child := range t {
+			t[i] = s.jsonValue(child)
+		}
+		return t
+	case string:
+		return s.text(t)
+	case json.Number:
+		to, ok := s.exact(t.String())
+		if !ok {
+			return t
+		}
+		if n := json.Number(to); isNumber(n) {
+			return n
+		}
+		return to
+	}
+	return v
+}
+
+func decodeJSON(s string) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func looksLikeJSON(s string) bool {
+	t := bytes.TrimSpace([]byte(s))
+	return len(t) > 0 && (t[0] == '{' || t[0] == '[')
+}
+
+func isNumber(n json.Number) bool {
+	_, err := n.Float64()
+	return err == nil
+}
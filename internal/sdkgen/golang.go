@@ -0,0 +1,365 @@
+package sdkgen
+
+import (
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+func renderGo(a *api, opts Options) ([]byte, error) {
+	b := &strings.Builder{}
+	fmt.Fprintln(b, "// Code generated by apidoc sdk; DO NOT EDIT.")
+	fmt.Fprintln(b)
+	if a.scenario != "" {
+		fmt.Fprintf(b, "// Package %s is a typed client for: %s\n", opts.Package, oneLine(a.scenario))
+	}
+	fmt.Fprintf(b, "package %s\n\n", opts.Package)
+	b.WriteString(goImports)
+	fmt.Fprintf(b, "// DefaultBaseURL is the server the docs were recorded against.\nconst DefaultBaseURL = %q\n\n", opts.BaseURL)
+
+	b.WriteString("// Client calls the API. Credentials left empty are not sent.\ntype Client struct {\n")
+	b.WriteString("BaseURL string\nHTTPClient *http.Client\n")
+	for _, s := range a.schemes {
+		switch s.Type {
+		case types.AuthBearer:
+			fmt.Fprintf(b, "// %s is sent as \"Authorization: Bearer <token>\".\n%s string\n", s.ident, s.ident)
+		case types.AuthBasic:
+			fmt.Fprintf(b, "// %sUsername and %sPassword are sent as HTTP basic auth.\n%sUsername, %sPassword string\n", s.ident, s.ident, s.ident, s.ident)
+		default:
+			fmt.Fprintf(b, "// %s is sent in %s %s.\n%s string\n", s.ident, credentialIn(s.AuthScheme), s.Name, s.ident)
+		}
+	}
+	b.WriteString("}\n\n")
+	b.WriteString(goClientFuncs)
+
+	for _, obj := range a.objects {
+		fmt.Fprintf(b, "type %s struct {\n", obj.name)
+		writeGoFields(b, obj.fields)
+		b.WriteString("}\n\n")
+	}
+	for _, op := range a.operations {
+		writeGoOperation(b, op)
+	}
+	writeGoAuthorize(b, a.schemes)
+	b.WriteString(goRuntime)
+
+	out, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return nil, fmt.Errorf("format generated Go client: %w", err)
+	}
+	return out, nil
+}
+
+func writeGoFields(b *strings.Builder, fields []field) {
+	for _, f := range fields {
+		if f.doc != "" {
+			fmt.Fprintf(b, "// %s\n", oneLine(f.doc))
+		}
+		typ := goType(f.typ)
+		tag := f.name
+		if !f.required {
+			tag += ",omitempty"
+			if f.typ.kind == kindObject && f.typ.object != "" {
+				typ = "*" + typ
+			}
+		}
+		fmt.Fprintf(b, "%s %s `json:%s`\n", f.ident, typ, strconv.Quote(tag))
+	}
+}
+
+func writeGoOperation(b *strings.Builder, op operation) {
+	if op.hasRequest() {
+		fmt.Fprintf(b, "// %sRequest holds the parameters of %s.\ntype %sRequest struct {\n", op.name, op.name, op.name)
+		for _, f := range append(append([]field(nil), op.pathParams...), op.queryParams...) {
+			if f.doc != "" {
+				fmt.Fprintf(b, "// %s\n", oneLine(f.doc))
+			}
+			typ := goType(f.typ)
+			if !f.required && f.typ.kind != kindArray {
+				typ = "*" + typ
+			}
+			fmt.Fprintf(b, "%s %s\n", f.ident, typ)
+		}
+		if op.body != "" {
+			fmt.Fprintf(b, "Body %s\n", op.body)
+		}
+		b.WriteString("}\n\n")
+	}
+
+	for _, e := range op.errors {
+		fmt.Fprintf(b, "// %s is returned for status %d", e.name, e.status)
+		if e.doc != "" {
+			fmt.Fprintf(b, ": %s", oneLine(e.doc))
+		}
+		b.WriteString(".\n")
+		body := "[]byte"
+		if e.body != "" {
+			body = e.body
+		}
+		fmt.Fprintf(b, "type %s struct {\nStatusCode int\nBody %s\n}\n\n", e.name, body)
+		fmt.Fprintf(b, "func (e *%s) Error() string {\nreturn fmt.Sprintf(%q, e.StatusCode)\n}\n\n", e.name, op.method+" "+op.path+": status %d")
+	}
+
+	fmt.Fprintf(b, "// %s calls %s %s.\n", op.name, op.method, op.path)
+	if summary := oneLine(op.summary); summary != "" {
+		fmt.Fprintf(b, "//\n// %s\n", summary)
+	}
+	params := "ctx context.Context"
+	if op.hasRequest() {
+		params += ", req " + op.name + "Request"
+	}
+	if op.result != "" {
+		fmt.Fprintf(b, "func (c *Client) %s(%s) (*%s, error) {\n", op.name, params, op.result)
+	} else {
+		fmt.Fprintf(b, "func (c *Client) %s(%s) error {\n", op.name, params)
+	}
+
+	query := "nil"
+	if len(op.queryParams) > 0 {
+		query = "query"
+		b.WriteString("query := url.Values{}\n")
+		for _, f := range op.queryParams {
+			switch {
+			case f.typ.kind == kindArray:
+				fmt.Fprintf(b, "for _, v := range req.%s {\nquery.Add(%q, fmt.Sprint(v))\n}\n", f.ident, f.name)
+			case f.required:
+				fmt.Fprintf(b, "query.Set(%q, fmt.Sprint(req.%s))\n", f.name, f.ident)
+			default:
+				fmt.Fprintf(b, "if req.%s != nil {\nquery.Set(%q, fmt.Sprint(*req.%s))\n}\n", f.ident, f.name, f.ident)
+			}
+		}
+	}
+	body := "nil"
+	if op.body != "" {
+		body = "req.Body"
+	}
+	out := "nil"
+	if op.result != "" {
+		fmt.Fprintf(b, "var out %s\n", op.result)
+		out = "&out"
+	}
+	errorFor := "nil"
+	if len(op.errors) > 0 {
+		errorFor = "func(status int, body []byte) error {\nswitch status {\n"
+		for _, e := range op.errors {
+			errorFor += fmt.Sprintf("case %d:\ne := &%s{StatusCode: status}\n", e.status, e.name)
+			if e.body != "" {
+				errorFor += "_ = json.Unmarshal(body, &e.Body)\n"
+			} else {
+				errorFor += "e.Body = body\n"
+			}
+			errorFor += "return e\n"
+		}
+		errorFor += "}\nreturn nil\n}"
+	}
+	auth := "nil"
+	if len(op.auth) > 0 {
+		quoted := make([]string, len(op.auth))
+		for i, id := range op.auth {
+			quoted[i] = strconv.Quote(id)
+		}
+		auth = "[]string{" + strings.Join(quoted, ", ") + "}"
+	}
+	call := fmt.Sprintf("c.do(ctx, %q, %s, %s, %s, %t, %s, %s, %s)", op.method, goPath(op), query, body, op.form, auth, out, errorFor)
+	if op.result == "" {
+		fmt.Fprintf(b, "return %s\n}\n\n", call)
+		return
+	}
+	fmt.Fprintf(b, "if err := %s; err != nil {\nreturn nil, err\n}\nreturn &out, nil\n}\n\n", call)
+}
+
+// goPath builds the request path expression, escaping parameters.
+func goPath(op operation) string {
+	var parts []string
+	literal := ""
+	for _, p := range op.parts {
+		literal += p.literal
+		if p.param < 0 {
+			continue
+		}
+		parts = append(parts, strconv.Quote(literal), fmt.Sprintf("url.PathEscape(fmt.Sprint(req.%s))", op.pathParams[p.param].ident))
+		literal = ""
+	}
+	if literal != "" || len(parts) == 0 {
+		parts = append(parts, strconv.Quote(literal))
+	}
+	return strings.Join(parts, " + ")
+}
+
+func writeGoAuthorize(b *strings.Builder, schemes []scheme) {
+	b.WriteString("// authorize adds the configured credentials of the given schemes.\n")
+	if len(schemes) == 0 {
+		b.WriteString("func (c *Client) authorize(req *http.Request, query url.Values, schemes []string) {}\n\n")
+		return
+	}
+	b.WriteString("func (c *Client) authorize(req *http.Request, query url.Values, schemes []string) {\nfor _, s := range schemes {\nswitch s {\n")
+	for _, s := range schemes {
+		fmt.Fprintf(b, "case %q:\n", s.id)
+		switch {
+		case s.Type == types.AuthBearer:
+			fmt.Fprintf(b, "if c.%s != \"\" {\nreq.Header.Set(\"Authorization\", \"Bearer \"+c.%s)\n}\n", s.ident, s.ident)
+		case s.Type == types.AuthBasic:
+			fmt.Fprintf(b, "if c.%[1]sUsername != \"\" || c.%[1]sPassword != \"\" {\nreq.SetBasicAuth(c.%[1]sUsername, c.%[1]sPassword)\n}\n", s.ident)
+		case credentialIn(s.AuthScheme) == "header":
+			fmt.Fprintf(b, "if c.%s != \"\" {\nreq.Header.Set(%q, c.%s)\n}\n", s.ident, s.Name, s.ident)
+		case credentialIn(s.AuthScheme) == "query":
+			fmt.Fprintf(b, "if c.%s != \"\" {\nquery.Set(%q, c.%s)\n}\n", s.ident, s.Name, s.ident)
+		default:
+			fmt.Fprintf(b, "if c.%s != \"\" {\nreq.AddCookie(&http.Cookie{Name: %q, Value: c.%s})\n}\n", s.ident, s.Name, s.ident)
+		}
+	}
+	b.WriteString("}\n}\n}\n\n")
+}
+
+// credentialIn is where an API key or session cookie travels.
+func credentialIn(s types.AuthScheme) string {
+	if s.Type == types.AuthSessionCookie {
+		return "cookie"
+	}
+	return s.In
+}
+
+func goType(t typeRef) string {
+	switch t.kind {
+	case kindString:
+		return "string"
+	case kindInteger:
+		return "int64"
+	case kindNumber:
+		return "float64"
+	case kindBoolean:
+		return "bool"
+	case kindObject:
+		if t.object == "" {
+			return "map[string]any"
+		}
+		return t.object
+	case kindArray:
+		return "[]" + goType(*t.elem)
+	}
+	return "any"
+}
+
+const goImports = `import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+`
+
+const goClientFuncs = `// NewClient returns a client for baseURL, or DefaultBaseURL when empty.
+func NewClient(baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{BaseURL: baseURL, HTTPClient: http.DefaultClient}
+}
+
+// APIError is returned for statuses the docs do not describe.
+type APIError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %.200s", e.StatusCode, e.Body)
+}
+
+`
+
+// goRuntime is the static part of the generated client. Bodies are sent as
+// JSON, or form-encoded from their top-level fields.
+const goRuntime = `func (c *Client) do(ctx context.Context, method, path string, query url.Values, body any, form bool, auth []string, out any, errorFor func(status int, body []byte) error) error {
+	var reader io.Reader
+	contentType := ""
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		contentType = "application/json"
+		if form {
+			values, err := formValues(data)
+			if err != nil {
+				return err
+			}
+			data = []byte(values.Encode())
+			contentType = "application/x-www-form-urlencoded"
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(c.BaseURL, "/")+path, reader)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	if query == nil {
+		query = url.Values{}
+	}
+	c.authorize(req, query, auth)
+	req.URL.RawQuery = query.Encode()
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if errorFor != nil {
+			if err := errorFor(resp.StatusCode, data); err != nil {
+				return err
+			}
+		}
+		return &APIError{StatusCode: resp.StatusCode, Body: data}
+	}
+	if out == nil || len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decode %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+func formValues(data []byte) (url.Values, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var fields map[string]any
+	if err := dec.Decode(&fields); err != nil {
+		return nil, err
+	}
+	values := url.Values{}
+	for k, v := range fields {
+		switch v := v.(type) {
+		case nil:
+		case []any:
+			for _, item := range v {
+				values.Add(k, fmt.Sprint(item))
+			}
+		default:
+			values.Set(k, fmt.Sprint(v))
+		}
+	}
+	return values, nil
+}
+`
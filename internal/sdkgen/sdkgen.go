@@ -0,0 +1,462 @@
+// Package sdkgen turns a generated doc into a typed API client: one method
+// per endpoint, request and response types built from the documented
+// parameter trees, credential injection and an error type per documented
+// failure status.
+package sdkgen
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/yourorg/apidoc/internal/generator"
+	"github.com/yourorg/apidoc/internal/pathmatch"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// Supported output languages.
+const (
+	LangGo         = "go"
+	LangTypeScript = "typescript"
+)
+
+// Options configures client generation.
+type Options struct {
+	// Lang is the output language; defaults to LangGo.
+	Lang string
+	// Package is the Go package name; defaults to "apiclient".
+	Package string
+	// BaseURL is the server the client calls unless told otherwise.
+	BaseURL string
+}
+
+// File is one generated source file.
+type File struct {
+	Name    string
+	Content []byte
+}
+
+// Generate renders a client for doc.
+func Generate(doc *types.GeneratedDoc, opts Options) ([]File, error) {
+	if doc == nil || len(doc.Endpoints) == 0 {
+		return nil, errors.New("no generated doc: run generate first")
+	}
+	if opts.Package == "" {
+		opts.Package = "apiclient"
+	}
+	a := buildAPI(doc)
+	switch strings.ToLower(strings.TrimSpace(opts.Lang)) {
+	case LangGo, "":
+		if !token.IsIdentifier(opts.Package) || token.IsKeyword(opts.Package) {
+			return nil, fmt.Errorf("invalid Go package name %q", opts.Package)
+		}
+		content, err := renderGo(a, opts)
+		if err != nil {
+			return nil, err
+		}
+		return []File{{Name: "client.go", Content: content}}, nil
+	case LangTypeScript, "ts":
+		return []File{{Name: "client.ts", Content: renderTypeScript(a, opts)}}, nil
+	}
+	return nil, fmt.Errorf("unsupported language %q (want %s or %s)", opts.Lang, LangGo, LangTypeScript)
+}
+
+// kind is a value's wire type.
+type kind int
+
+const (
+	kindAny kind = iota
+	kindString
+	kindInteger
+	kindNumber
+	kindBoolean
+	kindObject
+	kindArray
+)
+
+type typeRef struct {
+	kind   kind
+	object string   // named object; empty for free-form objects
+	elem   *typeRef // array elements
+}
+
+type field struct {
+	name     string // on the wire
+	ident    string // exported identifier, unique within its owner
+	typ      typeRef
+	required bool
+	doc      string
+}
+
+type object struct {
+	name   string
+	fields []field
+	sig    string
+}
+
+type errorType struct {
+	status int
+	name   string
+	doc    string
+	body   string // object name; empty when the body is undocumented
+}
+
+// pathPart is a literal piece of the path or, when param >= 0, an index
+// into operation.pathParams.
+type pathPart struct {
+	literal string
+	param   int
+}
+
+type operation struct {
+	name        string
+	method      string
+	path        string
+	summary     string
+	description string
+	parts       []pathPart
+	pathParams  []field
+	queryParams []field
+	body        string // object name
+	form        bool
+	result      string // object name; empty when the success response has no fields
+	errors      []errorType
+	auth        []string // scheme IDs
+}
+
+// hasRequest reports whether the operation takes a request value.
+func (op operation) hasRequest() bool {
+	return len(op.pathParams) > 0 || len(op.queryParams) > 0 || op.body != ""
+}
+
+type scheme struct {
+	types.AuthScheme
+	id    string
+	ident string // client credential field
+}
+
+type api struct {
+	scenario   string
+	objects    []object
+	operations []operation
+	schemes    []scheme
+	index      map[string]int  // object name → objects index
+	taken      map[string]bool // names reserved for other declarations
+}
+
+// runtimeNames are declared by every client.
+var runtimeNames = []string{"Client", "NewClient", "ClientOptions", "DefaultBaseURL", "DEFAULT_BASE_URL", "APIError", "ApiError"}
+
+func buildAPI(doc *types.GeneratedDoc) *api {
+	a := &api{scenario: doc.Scenario, index: map[string]int{}, taken: map[string]bool{}}
+	for _, n := range runtimeNames {
+		a.taken[n] = true
+	}
+	opNames := map[string]bool{}
+	names := make([]string, len(doc.Endpoints))
+	for i, ep := range doc.Endpoints {
+		names[i] = uniqueName(operationName(ep.Method, ep.Path), opNames)
+		a.taken[names[i]+"Request"] = true
+		for _, r := range ep.Responses {
+			a.taken[fmt.Sprintf("%s%dError", names[i], r.StatusCode)] = true
+		}
+	}
+	for n := range opNames {
+		a.taken[n] = true
+	}
+
+	schemes := map[string]bool{}
+	for i, ep := range doc.Endpoints {
+		op := operation{
+			name:        names[i],
+			method:      strings.ToUpper(ep.Method),
+			path:        ep.Path,
+			summary:     ep.Summary,
+			description: ep.Description,
+		}
+		idents := map[string]bool{"Body": true}
+		for _, seg := range strings.Split(strings.Trim(ep.Path, "/"), "/") {
+			if !pathmatch.IsTemplate("/" + seg) {
+				op.parts = append(op.parts, pathPart{literal: "/" + seg, param: -1})
+				continue
+			}
+			name := strings.Trim(seg, "{}:")
+			p := types.Param{Name: name, Type: "string"}
+			for _, pp := range ep.PathParams {
+				if pp.Name == name {
+					p = pp
+				}
+			}
+			p.Required = true
+			op.parts = append(op.parts, pathPart{literal: "/", param: len(op.pathParams)})
+			op.pathParams = append(op.pathParams, a.scalarField(p, idents))
+		}
+		for _, p := range ep.QueryParams {
+			if p.Name != "" {
+				op.queryParams = append(op.queryParams, a.scalarField(p, idents))
+			}
+		}
+		if ep.RequestBody != nil && len(ep.RequestBody.Fields) > 0 {
+			op.body = a.object(op.name+"Body", ep.RequestBody.Fields)
+			op.form = strings.Contains(strings.ToLower(ep.RequestBody.ContentType), "x-www-form-urlencoded")
+		}
+
+		responses := append([]types.Response(nil), ep.Responses...)
+		sort.SliceStable(responses, func(i, j int) bool { return responses[i].StatusCode < responses[j].StatusCode })
+		seen := map[int]bool{}
+		for _, r := range responses {
+			switch {
+			case r.StatusCode >= 200 && r.StatusCode < 300:
+				if !seen[0] && len(r.Fields) > 0 {
+					op.result = a.object(op.name+"Response", r.Fields)
+				}
+				seen[0] = true
+			case r.StatusCode >= 400 && !seen[r.StatusCode]:
+				seen[r.StatusCode] = true
+				e := errorType{status: r.StatusCode, name: fmt.Sprintf("%s%dError", op.name, r.StatusCode), doc: r.Description}
+				if len(r.Fields) > 0 {
+					e.body = a.object(e.name+"Body", r.Fields)
+				}
+				op.errors = append(op.errors, e)
+			}
+		}
+
+		for _, alt := range ep.Auth {
+			for _, s := range alt {
+				id := s.ID()
+				if !contains(op.auth, id) {
+					op.auth = append(op.auth, id)
+				}
+				if !schemes[id] {
+					schemes[id] = true
+					a.schemes = append(a.schemes, scheme{AuthScheme: s, id: id, ident: goName(id, "Credential")})
+				}
+			}
+		}
+		a.operations = append(a.operations, op)
+	}
+	return a
+}
+
+// scalarField builds a path or query parameter; objects are sent as text.
+func (a *api) scalarField(p types.Param, idents map[string]bool) field {
+	f := field{name: p.Name, ident: uniqueName(goName(p.Name, "Param"), idents), required: p.Required, doc: p.Description}
+	f.typ = a.typeOf(types.Param{Type: p.Type}, "")
+	if f.typ.kind == kindObject || f.typ.kind == kindAny {
+		f.typ = typeRef{kind: kindString}
+	}
+	if f.typ.kind == kindArray && (f.typ.elem.kind == kindObject || f.typ.elem.kind == kindAny) {
+		f.typ.elem = &typeRef{kind: kindString}
+	}
+	return f
+}
+
+// object registers an object type under name, or a numbered variant when
+// the name is taken by a different shape, and returns the name used.
+func (a *api) object(name string, params []types.Param) string {
+	var fields []field
+	idents := map[string]bool{}
+	for _, p := range params {
+		if p.Name == "" {
+			continue
+		}
+		f := field{name: p.Name, ident: uniqueName(goName(p.Name, "Field"), idents), required: p.Required, doc: p.Description}
+		f.typ = a.typeOf(p, name+f.ident)
+		fields = append(fields, f)
+	}
+	sig := signature(fields)
+	candidate := name
+	for n := 2; ; n++ {
+		if i, ok := a.index[candidate]; ok {
+			if a.objects[i].sig == sig {
+				return candidate
+			}
+		} else if !a.taken[candidate] {
+			a.index[candidate] = len(a.objects)
+			a.objects = append(a.objects, object{name: candidate, fields: fields, sig: sig})
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s%d", name, n)
+	}
+}
+
+// typeOf maps a documented type, registering nested objects; nested names
+// the object when the param carries no schema_name.
+func (a *api) typeOf(p types.Param, nested string) typeRef {
+	lt := strings.ToLower(strings.TrimSpace(p.Type))
+	if strings.Contains(lt, "array") || strings.HasPrefix(lt, "[]") {
+		elem := typeRef{kind: kindAny}
+		if len(p.Children) > 0 {
+			elem = typeRef{kind: kindObject, object: a.object(objectName(p, nested+"Item"), p.Children)}
+		} else if inner := strings.Trim(strings.NewReplacer("array", "", "[]", "").Replace(lt), "<>() "); inner != "" {
+			elem = scalarType(inner)
+		}
+		return typeRef{kind: kindArray, elem: &elem}
+	}
+	if len(p.Children) > 0 {
+		return typeRef{kind: kindObject, object: a.object(objectName(p, nested), p.Children)}
+	}
+	return scalarType(lt)
+}
+
+func objectName(p types.Param, fallback string) string {
+	if p.SchemaName != "" {
+		return goName(p.SchemaName, fallback)
+	}
+	return fallback
+}
+
+func scalarType(t string) typeRef {
+	switch t {
+	case "", "any", "mixed", "unknown":
+		return typeRef{kind: kindAny}
+	}
+	typeName, _ := generator.FieldType(t)
+	switch typeName {
+	case "integer":
+		return typeRef{kind: kindInteger}
+	case "number":
+		return typeRef{kind: kindNumber}
+	case "boolean":
+		return typeRef{kind: kindBoolean}
+	case "object":
+		return typeRef{kind: kindObject}
+	}
+	return typeRef{kind: kindString}
+}
+
+func signature(fields []field) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = fmt.Sprintf("%s:%s:%t", f.name, typeSig(f.typ), f.required)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func typeSig(t typeRef) string {
+	if t.kind == kindArray {
+		return "[]" + typeSig(*t.elem)
+	}
+	return fmt.Sprintf("%d%s", t.kind, t.object)
+}
+
+var versionSegment = regexp.MustCompile(`^v\d+$`)
+
+// operationName derives a method name such as GetOrdersByID from the
+// method and path, skipping "api" and version prefixes.
+func operationName(method, path string) string {
+	name := goName(strings.ToLower(method), "Call")
+	for _, seg := range strings.Split(strings.Trim(path, "/"), "/") {
+		switch {
+		case seg == "":
+		case pathmatch.IsTemplate("/" + seg):
+			name += "By" + goName(strings.Trim(seg, "{}:"), "Param")
+		case strings.EqualFold(seg, "api") || versionSegment.MatchString(strings.ToLower(seg)):
+		default:
+			name += goName(seg, "")
+		}
+	}
+	return name
+}
+
+var initialisms = map[string]string{
+	"api": "API", "html": "HTML", "http": "HTTP", "https": "HTTPS", "id": "ID", "ip": "IP",
+	"json": "JSON", "jwt": "JWT", "sql": "SQL", "uri": "URI", "url": "URL", "uuid": "UUID", "xml": "XML",
+}
+
+// goName turns s into an exported identifier, e.g. order_id → OrderID.
+// Characters outside ASCII are dropped; fallback is used when nothing is
+// left.
+func goName(s, fallback string) string {
+	b := &strings.Builder{}
+	for _, w := range words(s) {
+		lw := strings.ToLower(w)
+		if up, ok := initialisms[lw]; ok {
+			b.WriteString(up)
+			continue
+		}
+		b.WriteString(strings.ToUpper(lw[:1]) + lw[1:])
+	}
+	name := b.String()
+	if name == "" {
+		return fallback
+	}
+	if name[0] >= '0' && name[0] <= '9' {
+		return "N" + name
+	}
+	return name
+}
+
+// words splits s at separators and case changes: "orderID-v2" → order, ID, v2.
+func words(s string) []string {
+	var out []string
+	var cur []rune
+	rs := []rune(s)
+	for i, r := range rs {
+		if r >= 128 || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if len(cur) > 0 {
+				out = append(out, string(cur))
+				cur = nil
+			}
+			continue
+		}
+		if len(cur) > 0 && unicode.IsUpper(r) {
+			prev := rs[i-1]
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && nextLower {
+				out = append(out, string(cur))
+				cur = nil
+			}
+		}
+		cur = append(cur, r)
+	}
+	if len(cur) > 0 {
+		out = append(out, string(cur))
+	}
+	return out
+}
+
+// lowerCamel lowers an identifier's leading capitals: OrderID → orderID,
+// IDCard → idCard.
+func lowerCamel(s string) string {
+	rs := []rune(s)
+	n := 0
+	for n < len(rs) && unicode.IsUpper(rs[n]) {
+		n++
+	}
+	if n > 1 && n < len(rs) {
+		n--
+	}
+	for i := 0; i < n; i++ {
+		rs[i] = unicode.ToLower(rs[i])
+	}
+	return string(rs)
+}
+
+func uniqueName(name string, taken map[string]bool) string {
+	candidate := name
+	for n := 2; taken[candidate]; n++ {
+		candidate = fmt.Sprintf("%s%d", name, n)
+	}
+	taken[candidate] = true
+	return candidate
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// oneLine flattens a description for a line comment.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
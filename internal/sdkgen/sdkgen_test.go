@@ -0,0 +1,216 @@
+package sdkgen
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+func fixture() *types.GeneratedDoc {
+	user := func(required bool) types.Param {
+		return types.Param{Name: "user", Type: "object", Required: required, SchemaName: "User", Children: []types.Param{
+			{Name: "id", Type: "integer", Required: true},
+			{Name: "display_name", Type: "string", Description: "昵称"},
+		}}
+	}
+	return &types.GeneratedDoc{
+		Scenario: "Checkout flow",
+		Endpoints: []types.Endpoint{
+			{
+				Method: "POST", Path: "/api/v1/login", Summary: "登录",
+				RequestBody: &types.BodySchema{ContentType: "application/x-www-form-urlencoded", Fields: []types.Param{
+					{Name: "username", Type: "string", Required: true},
+					{Name: "remember", Type: "boolean"},
+				}},
+				Responses: []types.Response{{StatusCode: 200, Fields: []types.Param{{Name: "token", Type: "string", Required: true}, user(true)}}},
+			},
+			{
+				Method: "GET", Path: "/api/v1/orders/{id}", Summary: "获取订单",
+				PathParams:  []types.Param{{Name: "id", Type: "string", Required: true}},
+				QueryParams: []types.Param{{Name: "expand", Type: "string"}, {Name: "tag-ids", Type: "array<integer>"}},
+				Responses: []types.Response{
+					{StatusCode: 404, Description: "订单不存在", Fields: []types.Param{{Name: "message", Type: "string"}}},
+					{StatusCode: 200, Fields: []types.Param{
+						{Name: "id", Type: "string", Required: true},
+						user(false),
+						{Name: "items", Type: "array", Required: true, Children: []types.Param{{Name: "sku", Type: "string", Required: true}, {Name: "qty", Type: "integer"}}},
+						{Name: "meta", Type: "object"},
+					}},
+				},
+				Auth: [][]types.AuthScheme{{{Type: types.AuthBearer}}},
+			},
+			{
+				Method: "DELETE", Path: "/api/v1/orders/{id}",
+				Responses: []types.Response{{StatusCode: 204}, {StatusCode: 401}},
+				Auth:      [][]types.AuthScheme{{{Type: types.AuthAPIKey, In: "header", Name: "X-API-Key"}}, {{Type: types.AuthSessionCookie, In: "cookie", Name: "sid"}}},
+			},
+			{Method: "GET", Path: "/health", Responses: []types.Response{{StatusCode: 200}}},
+		},
+	}
+}
+
+func TestOperationName(t *testing.T) {
+	for _, c := range []struct{ method, path, want string }{
+		{"GET", "/api/v1/orders/{id}", "GetOrdersByID"},
+		{"post", "/users/:userId/avatar-url", "PostUsersByUserIDAvatarURL"},
+		{"GET", "/", "Get"},
+		{"GET", "/搜索", "Get"},
+	} {
+		if got := operationName(c.method, c.path); got != c.want {
+			t.Errorf("operationName(%s, %s) = %s, want %s", c.method, c.path, got, c.want)
+		}
+	}
+	if got := lowerCamel("IDCard"); got != "idCard" {
+		t.Errorf("lowerCamel(IDCard) = %s", got)
+	}
+}
+
+func TestGenerateRejectsUnknownLang(t *testing.T) {
+	if _, err := Generate(fixture(), Options{Lang: "ruby"}); err == nil {
+		t.Fatal("expected error for unsupported language")
+	}
+	if _, err := Generate(fixture(), Options{Package: "func"}); err == nil {
+		t.Fatal("expected error for a keyword package name")
+	}
+	if _, err := Generate(&types.GeneratedDoc{}, Options{}); err == nil {
+		t.Fatal("expected error without endpoints")
+	}
+}
+
+func TestGenerateTypeScript(t *testing.T) {
+	files, err := Generate(fixture(), Options{Lang: LangTypeScript, BaseURL: "https://shop.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := string(files[0].Content)
+	for _, want := range []string{
+		`export const DEFAULT_BASE_URL = "https://shop.example.com";`,
+		"export interface User {\n  id: number;\n  /** 昵称 */\n  display_name?: string;\n}",
+		"  items: GetOrdersByIDResponseItemsItem[];",
+		"  tagIds?: number[];",
+		"export class GetOrdersByID404Error extends ApiError {\n  declare readonly body: GetOrdersByID404ErrorBody;",
+		"  async getOrdersByID(req: GetOrdersByIDRequest): Promise<GetOrdersByIDResponse> {",
+		"`/api/v1/orders/${encodeURIComponent(String(req.id))}`",
+		`{ "expand": req.expand, "tag-ids": req.tagIds }`,
+		`404: (body) => new GetOrdersByID404Error(body as GetOrdersByID404ErrorBody),`,
+		`return this.request<void>("DELETE"`,
+		`["apiKey_header_X-API-Key", "sessionCookie_sid"]`,
+		`if (this.options.bearerAuth) headers["Authorization"] = "Bearer " + this.options.bearerAuth;`,
+		`cookies.push("sid=" + encodeURIComponent(this.options.sessionCookieSid));`,
+	} {
+		if !strings.Contains(ts, want) {
+			t.Fatalf("typescript client missing %q:\n%s", want, ts)
+		}
+	}
+	if strings.Count(ts, "export interface User ") != 1 {
+		t.Fatalf("shared schema should be declared once")
+	}
+}
+
+// clientTest exercises the generated Go client against a fake server.
+const clientTest = `package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.EscapedPath() {
+		case "POST /api/v1/login":
+			if r.Header.Get("Content-Type") != "application/x-www-form-urlencoded" || r.FormValue("username") != "alice" || r.Form.Has("remember") {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"token": "t1", "user": map[string]any{"id": 7, "display_name": "Alice"}})
+		case "GET /api/v1/orders/a%2Fb":
+			if r.Header.Get("Authorization") != "Bearer t1" || r.URL.Query().Get("expand") != "items" || len(r.URL.Query()["tag-ids"]) != 2 {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "a/b", "items": []any{map[string]any{"sku": "A-1", "qty": 2}}})
+		case "GET /api/v1/orders/missing":
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]any{"message": "gone"})
+		case "DELETE /api/v1/orders/a%2Fb":
+			if c, err := r.Cookie("sid"); err != nil || c.Value != "s1" || r.Header.Get("X-API-Key") != "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusTeapot)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := NewClient(srv.URL)
+	login, err := c.PostLogin(ctx, PostLoginRequest{Body: PostLoginBody{Username: "alice"}})
+	if err != nil || login.User.ID != 7 || login.User.DisplayName != "Alice" {
+		t.Fatalf("login = %+v, %v", login, err)
+	}
+	c.BearerAuth = login.Token
+	expand := "items"
+	order, err := c.GetOrdersByID(ctx, GetOrdersByIDRequest{ID: "a/b", Expand: &expand, TagIds: []int64{1, 2}})
+	if err != nil || order.ID != "a/b" || order.Items[0].Qty != 2 || order.User != nil {
+		t.Fatalf("order = %+v, %v", order, err)
+	}
+	_, err = c.GetOrdersByID(ctx, GetOrdersByIDRequest{ID: "missing"})
+	var notFound *GetOrdersByID404Error
+	if !errors.As(err, &notFound) || notFound.Body.Message != "gone" {
+		t.Fatalf("want typed 404 error, got %v", err)
+	}
+	c.SessionCookieSid = "s1"
+	if err := c.DeleteOrdersByID(ctx, DeleteOrdersByIDRequest{ID: "a/b"}); err != nil {
+		t.Fatal(err)
+	}
+	var apiErr *APIError
+	if err := c.GetHealth(ctx); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTeapot {
+		t.Fatalf("want APIError for an undocumented status, got %v", err)
+	}
+}
+`
+
+// TestGeneratedGoClient builds the generated client and runs it against a
+// fake server.
+func TestGeneratedGoClient(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go tool")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+	files, err := Generate(fixture(), Options{BaseURL: "https://shop.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"go.mod":         "module apiclient\n\ngo 1.22\n",
+		files[0].Name:    string(files[0].Content),
+		"client_test.go": clientTest,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{{"build", "./..."}, {"vet", "./..."}, {"test", "-count=1", "./..."}} {
+		cmd := exec.Command(goBin, args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("go %s failed: %v\n%s\n%s", args[0], err, out, files[0].Content)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package sdkgen
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+func renderTypeScript(a *api, opts Options) []byte {
+	b := &strings.Builder{}
+	fmt.Fprintln(b, "// Code generated by apidoc sdk; DO NOT EDIT.")
+	if a.scenario != "" {
+		fmt.Fprintf(b, "// Typed client for: %s\n", oneLine(a.scenario))
+	}
+	fmt.Fprintf(b, "\n/** The server the docs were recorded against. */\nexport const DEFAULT_BASE_URL = %s;\n\n", strconv.Quote(opts.BaseURL))
+
+	b.WriteString("export interface ClientOptions {\n  baseUrl?: string;\n  fetch?: typeof fetch;\n")
+	for _, s := range a.schemes {
+		name := lowerCamel(s.ident)
+		switch s.Type {
+		case types.AuthBearer:
+			fmt.Fprintf(b, "  /** Sent as \"Authorization: Bearer <token>\". */\n  %s?: string;\n", name)
+		case types.AuthBasic:
+			fmt.Fprintf(b, "  /** Sent as HTTP basic auth. */\n  %sUsername?: string;\n  %sPassword?: string;\n", name, name)
+		default:
+			fmt.Fprintf(b, "  /** Sent in %s %s. */\n  %s?: string;\n", credentialIn(s.AuthScheme), tsComment(s.Name), name)
+		}
+	}
+	b.WriteString("}\n\n")
+	b.WriteString(tsAPIError)
+
+	for _, obj := range a.objects {
+		fmt.Fprintf(b, "export interface %s {\n", obj.name)
+		for _, f := range obj.fields {
+			writeTSProperty(b, tsKey(f.name), f)
+		}
+		b.WriteString("}\n\n")
+	}
+	for _, op := range a.operations {
+		if op.hasRequest() {
+			fmt.Fprintf(b, "export interface %sRequest {\n", op.name)
+			for _, f := range append(append([]field(nil), op.pathParams...), op.queryParams...) {
+				writeTSProperty(b, lowerCamel(f.ident), f)
+			}
+			if op.body != "" {
+				fmt.Fprintf(b, "  body: %s;\n", op.body)
+			}
+			b.WriteString("}\n\n")
+		}
+		for _, e := range op.errors {
+			body := "unknown"
+			if e.body != "" {
+				body = e.body
+			}
+			if e.doc != "" {
+				fmt.Fprintf(b, "/** Status %d: %s */\n", e.status, tsComment(e.doc))
+			}
+			fmt.Fprintf(b, "export class %s extends ApiError {\n  declare readonly body: %s;\n\n", e.name, body)
+			fmt.Fprintf(b, "  constructor(body: %s) {\n    super(%d, body, %s);\n  }\n}\n\n", body, e.status, strconv.Quote(fmt.Sprintf("%s %s: status %d", op.method, op.path, e.status)))
+		}
+	}
+
+	b.WriteString("export class Client {\n  private readonly options: ClientOptions;\n\n")
+	b.WriteString("  constructor(options: ClientOptions = {}) {\n    this.options = options;\n  }\n\n")
+	for _, op := range a.operations {
+		writeTSOperation(b, op)
+	}
+	b.WriteString(tsRequest)
+	writeTSAuthorize(b, a.schemes)
+	b.WriteString("}\n")
+	b.WriteString(tsHelpers)
+	return []byte(b.String())
+}
+
+func writeTSProperty(b *strings.Builder, key string, f field) {
+	if f.doc != "" {
+		fmt.Fprintf(b, "  /** %s */\n", tsComment(f.doc))
+	}
+	optional := ""
+	if !f.required {
+		optional = "?"
+	}
+	fmt.Fprintf(b, "  %s%s: %s;\n", key, optional, tsType(f.typ))
+}
+
+func writeTSOperation(b *strings.Builder, op operation) {
+	comment := op.method + " " + op.path
+	if summary := oneLine(op.summary); summary != "" {
+		comment = summary + " (" + comment + ")"
+	}
+	fmt.Fprintf(b, "  /** %s */\n", tsComment(comment))
+	params := ""
+	if op.hasRequest() {
+		params = "req: " + op.name + "Request"
+	}
+	result := "void"
+	if op.result != "" {
+		result = op.result
+	}
+	fmt.Fprintf(b, "  async %s(%s): Promise<%s> {\n", lowerCamel(op.name), params, result)
+
+	query := "{}"
+	if len(op.queryParams) > 0 {
+		entries := make([]string, len(op.queryParams))
+		for i, f := range op.queryParams {
+			entries[i] = fmt.Sprintf("%s: req.%s", strconv.Quote(f.name), lowerCamel(f.ident))
+		}
+		query = "{ " + strings.Join(entries, ", ") + " }"
+	}
+	body := "undefined"
+	if op.body != "" {
+		body = "req.body"
+	}
+	quoted := make([]string, len(op.auth))
+	for i, id := range op.auth {
+		quoted[i] = strconv.Quote(id)
+	}
+	errors := "{}"
+	if len(op.errors) > 0 {
+		entries := make([]string, len(op.errors))
+		for i, e := range op.errors {
+			cast := "body as " + e.body
+			if e.body == "" {
+				cast = "body"
+			}
+			entries[i] = fmt.Sprintf("      %d: (body) => new %s(%s),\n", e.status, e.name, cast)
+		}
+		errors = "{\n" + strings.Join(entries, "") + "    }"
+	}
+	fmt.Fprintf(b, "    return this.request<%s>(%s, %s, %s, %s, %t, [%s], %s);\n  }\n\n",
+		result, strconv.Quote(op.method), tsPath(op), query, body, op.form, strings.Join(quoted, ", "), errors)
+}
+
+// tsPath builds a template literal, encoding parameters.
+func tsPath(op operation) string {
+	b := &strings.Builder{}
+	b.WriteString("`")
+	esc := strings.NewReplacer("\\", "\\\\", "`", "\\`", "${", "\\${")
+	for _, p := range op.parts {
+		b.WriteString(esc.Replace(p.literal))
+		if p.param >= 0 {
+			fmt.Fprintf(b, "${encodeURIComponent(String(req.%s))}", lowerCamel(op.pathParams[p.param].ident))
+		}
+	}
+	b.WriteString("`")
+	return b.String()
+}
+
+func writeTSAuthorize(b *strings.Builder, schemes []scheme) {
+	b.WriteString("  private authorize(headers: Record<string, string>, url: URL, schemes: string[]): void {\n")
+	if len(schemes) == 0 {
+		b.WriteString("    void headers;\n    void url;\n    void schemes;\n  }\n")
+		return
+	}
+	b.WriteString("    const cookies: string[] = [];\n    for (const scheme of schemes) {\n      switch (scheme) {\n")
+	for _, s := range schemes {
+		name := "this.options." + lowerCamel(s.ident)
+		fmt.Fprintf(b, "        case %s:\n", strconv.Quote(s.id))
+		switch {
+		case s.Type == types.AuthBearer:
+			fmt.Fprintf(b, "          if (%[1]s) headers[\"Authorization\"] = \"Bearer \" + %[1]s;\n", name)
+		case s.Type == types.AuthBasic:
+			fmt.Fprintf(b, "          if (%[1]sUsername || %[1]sPassword) {\n            headers[\"Authorization\"] = \"Basic \" + btoa((%[1]sUsername ?? \"\") + \":\" + (%[1]sPassword ?? \"\"));\n          }\n", name)
+		case credentialIn(s.AuthScheme) == "header":
+			fmt.Fprintf(b, "          if (%[1]s) headers[%[2]s] = %[1]s;\n", name, strconv.Quote(s.Name))
+		case credentialIn(s.AuthScheme) == "query":
+			fmt.Fprintf(b, "          if (%[1]s) url.searchParams.set(%[2]s, %[1]s);\n", name, strconv.Quote(s.Name))
+		default:
+			fmt.Fprintf(b, "          if (%[1]s) cookies.push(%[2]s + encodeURIComponent(%[1]s));\n", name, strconv.Quote(s.Name+"="))
+		}
+		b.WriteString("          break;\n")
+	}
+	b.WriteString("      }\n    }\n    if (cookies.length > 0) headers[\"Cookie\"] = cookies.join(\"; \");\n  }\n")
+}
+
+func tsType(t typeRef) string {
+	switch t.kind {
+	case kindString:
+		return "string"
+	case kindInteger, kindNumber:
+		return "number"
+	case kindBoolean:
+		return "boolean"
+	case kindObject:
+		if t.object == "" {
+			return "Record<string, unknown>"
+		}
+		return t.object
+	case kindArray:
+		return tsType(*t.elem) + "[]"
+	}
+	return "unknown"
+}
+
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func tsKey(name string) string {
+	if tsIdentifier.MatchString(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+func tsComment(s string) string {
+	return strings.ReplaceAll(oneLine(s), "*/", "* /")
+}
+
+const tsAPIError = `/** Thrown for statuses the docs do not describe, and the base of the documented error types. */
+export class ApiError extends Error {
+  readonly status: number;
+  readonly body: unknown;
+
+  constructor(status: number, body: unknown, message?: string) {
+    super(message ?? "unexpected status " + status);
+    this.name = new.target.name;
+    this.status = status;
+    this.body = body;
+  }
+}
+
+`
+
+// tsRequest is the static part of the generated client. Bodies are sent as
+// JSON, or form-encoded from their top-level fields.
+const tsRequest = `  private async request<T>(
+    method: string,
+    path: string,
+    query: Record<string, unknown>,
+    body: unknown,
+    form: boolean,
+    auth: string[],
+    errors: Record<number, (body: unknown) => ApiError>,
+  ): Promise<T> {
+    const url = new URL((this.options.baseUrl || DEFAULT_BASE_URL).replace(/\/+$/, "") + path);
+    for (const [key, value] of Object.entries(query)) {
+      appendValue(url.searchParams, key, value);
+    }
+    const headers: Record<string, string> = { Accept: "application/json" };
+    let payload: string | undefined;
+    if (body !== undefined) {
+      if (form) {
+        const params = new URLSearchParams();
+        for (const [key, value] of Object.entries(body as Record<string, unknown>)) {
+          appendValue(params, key, value);
+        }
+        payload = params.toString();
+        headers["Content-Type"] = "application/x-www-form-urlencoded";
+      } else {
+        payload = JSON.stringify(body);
+        headers["Content-Type"] = "application/json";
+      }
+    }
+    this.authorize(headers, url, auth);
+
+    const res = await (this.options.fetch ?? fetch)(url.toString(), { method, headers, body: payload });
+    const text = await res.text();
+    let data: unknown = undefined;
+    if (text) {
+      try {
+        data = JSON.parse(text);
+      } catch {
+        data = text;
+      }
+    }
+    if (!res.ok) {
+      const make = errors[res.status];
+      throw make ? make(data) : new ApiError(res.status, data);
+    }
+    return data as T;
+  }
+
+`
+
+// tsHelpers follow the client class.
+const tsHelpers = `
+function appendValue(params: URLSearchParams, key: string, value: unknown): void {
+  if (value === undefined || value === null) return;
+  for (const item of Array.isArray(value) ? value : [value]) {
+    params.append(key, typeof item === "object" ? JSON.stringify(item) : String(item));
+  }
+}
+`
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"time"
+
+	"github.com/yourorg/apidoc/internal/har"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// trafficRequest is the JSON body accepted by the traffic ingest endpoints.
+type trafficRequest struct {
+	Scenario string         `json:"scenario"`
+	Logs     []trafficEntry `json:"logs"`
+}
+
+// trafficEntry is one captured exchange as sent by the extension. It also
+// accepts a types.TrafficLog as posted by pkg/capture, whose start time is
+// "timestamp" rather than "started_at".
+type trafficEntry struct {
+	Method               string              `json:"method"`
+	URL                  string              `json:"url"`
+	Scheme               string              `json:"scheme"`
+	Host                 string              `json:"host"`
+	Path                 string              `json:"path"`
+	HTTPVersion          string              `json:"http_version"`
+	StartedAt            string              `json:"started_at"`
+	Timestamp            string              `json:"timestamp"`
+	QueryParams          map[string][]string `json:"query_params"`
+	RequestHeaders       types.Headers       `json:"request_headers"`
+	RequestCookies       []types.Cookie      `json:"request_cookies"`
+	RequestBody          string              `json:"request_body"`
+	RequestBodyEncoding  string              `json:"request_body_encoding"`
+	FormParams           map[string][]string `json:"form_params"`
+	ContentType          string              `json:"content_type"`
+	StatusCode           int                 `json:"status_code"`
+	ResponseHeaders      types.Headers       `json:"response_headers"`
+	ResponseCookies      []types.Cookie      `json:"response_cookies"`
+	ResponseBody         string              `json:"response_body"`
+	ResponseBodyEncoding string              `json:"response_body_encoding"`
+	ResponseContentType  string              `json:"response_content_type"`
+	LatencyMs            int64               `json:"latency_ms"`
+	Timings              *types.Timings      `json:"timings"`
+}
+
+// trafficLogs validates the entries and converts them to logs in payload
+// order. Entries without started_at or timestamp are stamped with the
+// receive time; callers order the result with har.Sequence before storing it.
+func (req *trafficRequest) trafficLogs() ([]types.TrafficLog, error) {
+	logs := make([]types.TrafficLog, 0, len(req.Logs))
+	now := time.Now().UTC()
+	for i, l := range req.Logs {
+		ts := now
+		if l.StartedAt != "" {
+			parsed, err := time.Parse(time.RFC3339Nano, l.StartedAt)
+			if err != nil {
+				return nil, fmt.Errorf("logs[%d].started_at: %w", i, err)
+			}
+			ts = parsed.UTC()
+		} else if l.Timestamp != "" {
+			parsed, err := time.Parse(time.RFC3339Nano, l.Timestamp)
+			if err != nil {
+				return nil, fmt.Errorf("logs[%d].timestamp: %w", i, err)
+			}
+			// A zero time.Time means the client had none.
+			if !parsed.IsZero() {
+				ts = parsed.UTC()
+			}
+		}
+		if l.LatencyMs < 0 {
+			return nil, fmt.Errorf("logs[%d].latency_ms must not be negative", i)
+		}
+		if err := validateTimings(l.Timings); err != nil {
+			return nil, fmt.Errorf("logs[%d].timings: %w", i, err)
+		}
+		reqBody, reqEnc, err := decodeEntryBody(l.RequestBody, l.RequestBodyEncoding, l.ContentType)
+		if err != nil {
+			return nil, fmt.Errorf("logs[%d].request_body_encoding: %w", i, err)
+		}
+		respBody, _, err := decodeEntryBody(l.ResponseBody, l.ResponseBodyEncoding, l.ResponseContentType)
+		if err != nil {
+			return nil, fmt.Errorf("logs[%d].response_body_encoding: %w", i, err)
+		}
+		scheme := l.Scheme
+		if scheme == "" && l.URL != "" {
+			if u, err := url.Parse(l.URL); err == nil {
+				scheme = u.Scheme
+			}
+		}
+		logs = append(logs, types.TrafficLog{
+			Timestamp:           ts,
+			Method:              l.Method,
+			Scheme:              scheme,
+			Host:                l.Host,
+			Path:                l.Path,
+			HTTPVersion:         l.HTTPVersion,
+			QueryParams:         l.QueryParams,
+			RequestHeaders:      l.RequestHeaders,
+			RequestCookies:      l.RequestCookies,
+			RequestBody:         reqBody,
+			RequestBodyEncoding: reqEnc,
+			FormParams:          l.FormParams,
+			ContentType:         l.ContentType,
+			StatusCode:          l.StatusCode,
+			ResponseHeaders:     l.ResponseHeaders,
+			ResponseCookies:     l.ResponseCookies,
+			ResponseBody:        respBody,
+			ResponseContentType: l.ResponseContentType,
+			LatencyMs:           l.LatencyMs,
+			Timings:             l.Timings,
+		})
+	}
+	return logs, nil
+}
+
+// decodeEntryBody applies the HAR body rules to an ingest body. The encoding
+// may be empty, "plain", "base64" or "omitted".
+func decodeEntryBody(text, encoding, mimeType string) (string, string, error) {
+	switch encoding {
+	case "", "plain", "base64":
+		body, enc := har.DecodeBody(text, encoding, mimeType)
+		return body, enc, nil
+	case "omitted":
+		return "", "omitted", nil
+	default:
+		return "", "", fmt.Errorf("unsupported encoding %q", encoding)
+	}
+}
+
+// validateTimings accepts HAR timings, where -1 marks a phase that does not apply.
+func validateTimings(t *types.Timings) error {
+	if t == nil {
+		return nil
+	}
+	phases := []struct {
+		name string
+		v    float64
+	}{
+		{"blocked", t.Blocked}, {"dns", t.DNS}, {"connect", t.Connect}, {"ssl", t.SSL},
+		{"send", t.Send}, {"wait", t.Wait}, {"receive", t.Receive},
+	}
+	for _, p := range phases {
+		if math.IsNaN(p.v) || math.IsInf(p.v, 0) || p.v < -1 {
+			return fmt.Errorf("%s must be -1 or a non-negative duration", p.name)
+		}
+	}
+	return nil
+}
@@ -7,10 +7,10 @@ import (
 	"html/template"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/yourorg/apidoc/internal/config"
 	"github.com/yourorg/apidoc/internal/generator"
+	"github.com/yourorg/apidoc/internal/har"
 	"github.com/yourorg/apidoc/internal/store"
 	"github.com/yourorg/apidoc/pkg/types"
 )
@@ -24,13 +24,16 @@ var (
 
 // Server wraps the preview UI and API handlers.
 type Server struct {
-	cfg   *config.Config
-	store store.Store
-	mux   *http.ServeMux
+	cfg     *config.Config
+	store   store.Store
+	mux     *http.ServeMux
+	uploads *uploads
 }
 
 type uiData struct {
 	SessionID string
+	// Languages are the configured doc languages, offered as a switch.
+	Languages []string
 }
 
 // New constructs a new Server with routes registered.
@@ -43,9 +46,10 @@ func New(cfg *config.Config, st store.Store) (*Server, error) {
 	}
 
 	srv := &Server{
-		cfg:   cfg,
-		store: st,
-		mux:   http.NewServeMux(),
+		cfg:     cfg,
+		store:   st,
+		mux:     http.NewServeMux(),
+		uploads: newUploads(),
 	}
 	srv.registerRoutes()
 	return srv, nil
@@ -73,6 +77,8 @@ func (s *Server) registerRoutes() {
 	s.mux.HandleFunc("/api/sessions", s.handleSessions)
 	s.mux.HandleFunc("/api/sessions/", s.handleSessionRoutes)
 	s.mux.HandleFunc("/api/traffic", s.handleTraffic)
+	s.mux.HandleFunc("/api/uploads", s.handleUploadOpen)
+	s.mux.HandleFunc("/api/uploads/", s.handleUploadRoutes)
 	s.mux.HandleFunc("/api/generate", s.handleGenerate)
 }
 
@@ -120,9 +126,16 @@ func (s *Server) handleSessionRoutes(w http.ResponseWriter, r *http.Request) {
 		http.NotFound(w, r)
 		return
 	}
-	if tail == "doc" {
+	switch tail {
+	case "doc":
 		s.handleSessionDoc(w, r, id)
 		return
+	case "traffic":
+		s.handleSessionTraffic(w, r, id)
+		return
+	case "diagram":
+		s.handleSessionDiagram(w, r, id)
+		return
 	}
 	if tail != "" {
 		http.NotFound(w, r)
@@ -147,7 +160,7 @@ func (s *Server) handleSessionDetail(w http.ResponseWriter, r *http.Request, id
 		return
 	}
 	resp := struct {
-		Session *types.Session    `json:"session"`
+		Session *types.Session     `json:"session"`
 		Logs    []types.TrafficLog `json:"logs"`
 	}{
 		Session: sess,
@@ -156,6 +169,8 @@ func (s *Server) handleSessionDetail(w http.ResponseWriter, r *http.Request, id
 	writeJSON(w, http.StatusOK, resp)
 }
 
+// handleSessionDoc serves the merged doc; ?lang= picks one of
+// output.languages.
 func (s *Server) handleSessionDoc(w http.ResponseWriter, r *http.Request, id string) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -166,31 +181,68 @@ func (s *Server) handleSessionDoc(w http.ResponseWriter, r *http.Request, id str
 		http.Error(w, "session not found", http.StatusNotFound)
 		return
 	}
-	caches, err := s.store.GetBatchCaches(id)
-	if err != nil {
+	merged, ok := s.loadDoc(w, r, sess)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, merged)
+}
+
+// loadDoc loads the session's doc in the ?lang= language, writing the
+// error response when it cannot.
+func (s *Server) loadDoc(w http.ResponseWriter, r *http.Request, sess *types.Session) (*types.GeneratedDoc, bool) {
+	doc, err := generator.LoadDocLang(s.store, sess, s.cfg, r.URL.Query().Get("lang"))
+	switch {
+	case errors.Is(err, generator.ErrNoDoc):
+		http.Error(w, "doc not found", http.StatusNotFound)
+		return nil, false
+	case errors.Is(err, generator.ErrNoTranslation):
+		http.Error(w, "translation not found", http.StatusNotFound)
+		return nil, false
+	case err != nil:
 		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	return doc, true
+}
+
+// handleSessionDiagram serves the call chain diagram source as text.
+// ?format=mermaid|plantuml picks the syntax; ?kind=flow returns the Mermaid
+// dependency flowchart instead of the sequence diagram; ?lang= as for the doc.
+func (s *Server) handleSessionDiagram(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	docs := make([]*types.GeneratedDoc, 0)
-	for _, cache := range caches {
-		if cache.Status != "ok" || cache.RawOutput == "" {
-			continue
-		}
-		var doc types.GeneratedDoc
-		if err := json.Unmarshal([]byte(cache.RawOutput), &doc); err != nil {
-			continue
-		}
-		if doc.Scenario == "" {
-			doc.Scenario = sess.Scenario
-		}
-		docs = append(docs, &doc)
+	sess, err := s.store.GetSession(id)
+	if err != nil {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
 	}
-	if len(docs) == 0 {
-		http.Error(w, "doc not found", http.StatusNotFound)
+	merged, ok := s.loadDoc(w, r, sess)
+	if !ok {
 		return
 	}
-	merged := generator.MergeDocs(docs)
-	writeJSON(w, http.StatusOK, merged)
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = generator.DiagramMermaid
+	}
+	if format != generator.DiagramMermaid && format != generator.DiagramPlantUML {
+		http.Error(w, "unknown diagram format", http.StatusBadRequest)
+		return
+	}
+	var diagram string
+	if r.URL.Query().Get("kind") == "flow" {
+		if format != generator.DiagramMermaid {
+			http.Error(w, "flow diagrams are Mermaid only", http.StatusBadRequest)
+			return
+		}
+		diagram = generator.FlowDiagram(merged)
+	} else {
+		diagram = generator.SequenceDiagram(merged, format, sess.Host)
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte(diagram))
 }
 
 func (s *Server) handleTraffic(w http.ResponseWriter, r *http.Request) {
@@ -203,65 +255,85 @@ func (s *Server) handleTraffic(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	var req struct {
-		Scenario string `json:"scenario"`
-		Logs     []struct {
-			Method              string              `json:"method"`
-			URL                 string              `json:"url"`
-			Host                string              `json:"host"`
-			Path                string              `json:"path"`
-			QueryParams         map[string][]string `json:"query_params"`
-			RequestHeaders      map[string]string   `json:"request_headers"`
-			RequestBody         string              `json:"request_body"`
-			ContentType         string              `json:"content_type"`
-			StatusCode          int                 `json:"status_code"`
-			ResponseHeaders     map[string]string   `json:"response_headers"`
-			ResponseBody        string              `json:"response_body"`
-			ResponseContentType string              `json:"response_content_type"`
-			LatencyMs           int64               `json:"latency_ms"`
-		} `json:"logs"`
+	var req trafficRequest
+	if !s.decodeBody(w, r, &req) {
+		return
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json: "+err.Error(), http.StatusBadRequest)
+	logs, err := req.trafficLogs()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	logs := make([]types.TrafficLog, 0, len(req.Logs))
-	now := time.Now().UTC()
-	for i, l := range req.Logs {
-		logs = append(logs, types.TrafficLog{
-			Seq:                 i + 1,
-			Method:              l.Method,
-			Host:                l.Host,
-			Path:                l.Path,
-			QueryParams:         l.QueryParams,
-			RequestHeaders:      l.RequestHeaders,
-			RequestBody:         l.RequestBody,
-			ContentType:         l.ContentType,
-			StatusCode:          l.StatusCode,
-			ResponseHeaders:     l.ResponseHeaders,
-			ResponseBody:        l.ResponseBody,
-			ResponseContentType: l.ResponseContentType,
-			LatencyMs:           l.LatencyMs,
-			Timestamp:           now,
-		})
+	sess, err := s.importTraffic(req.Scenario, logs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	host := "unknown"
-	if len(req.Logs) > 0 && req.Logs[0].Host != "" {
-		host = req.Logs[0].Host
+	writeJSON(w, http.StatusOK, map[string]string{"session_id": sess.ID, "status": "imported"})
+}
+
+// handleSessionTraffic appends logs to an existing session. Batch caches for
+// the touched path prefixes are dropped so a resumed generate re-asks the LLM.
+func (s *Server) handleSessionTraffic(w http.ResponseWriter, r *http.Request, id string) {
+	setCORS(w, s.cfg.Server.CORSExtensionID)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, err := s.store.GetSession(id); err != nil {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
 	}
-	sess, err := s.store.CreateSession("extension", req.Scenario, host)
+	var req trafficRequest
+	if !s.decodeBody(w, r, &req) {
+		return
+	}
+	logs, err := req.trafficLogs()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	if err := s.store.SaveLogs(sess.ID, logs); err != nil {
+	sess, err := s.appendTraffic(id, logs)
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	writeJSON(w, http.StatusOK, map[string]any{"session_id": sess.ID, "appended": len(logs), "log_count": sess.LogCount})
+}
 
-	writeJSON(w, http.StatusOK, map[string]string{"session_id": sess.ID, "status": "imported"})
+// importTraffic stores logs as a new extension session, ordered by start time.
+func (s *Server) importTraffic(scenario string, logs []types.TrafficLog) (*types.Session, error) {
+	har.Sequence(logs)
+	host := "unknown"
+	if len(logs) > 0 && logs[0].Host != "" {
+		host = logs[0].Host
+	}
+	sess, err := s.store.CreateSession("extension", scenario, host)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.SaveLogs(sess.ID, logs); err != nil {
+		return nil, err
+	}
+	return s.store.GetSession(sess.ID)
+}
+
+// appendTraffic adds logs to session id, ordered by start time, and drops
+// the batch caches they affect.
+func (s *Server) appendTraffic(id string, logs []types.TrafficLog) (*types.Session, error) {
+	har.Sequence(logs)
+	if err := s.store.AppendLogs(id, logs); err != nil {
+		return nil, err
+	}
+	if err := s.store.ClearBatchCaches(id, generator.StaleBatchKeys(logs)); err != nil {
+		return nil, err
+	}
+	return s.store.GetSession(id)
 }
 
 func (s *Server) handleGenerate(w http.ResponseWriter, r *http.Request) {
@@ -295,7 +367,7 @@ func (s *Server) handleGenerate(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	doc, err := generator.Generate(sess, logs, s.cfg.LLM, s.store, nil, false, false)
+	doc, err := generator.GenerateWithConfig(sess, logs, s.cfg, s.store, nil, false, false)
 	if err != nil {
 		http.Error(w, "generate failed: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -306,7 +378,7 @@ func (s *Server) handleGenerate(w http.ResponseWriter, r *http.Request) {
 func (s *Server) renderUI(w http.ResponseWriter, sessionID string) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.Header().Set("Cache-Control", "no-store")
-	_ = uiTemplate.Execute(w, uiData{SessionID: sessionID})
+	_ = uiTemplate.Execute(w, uiData{SessionID: sessionID, Languages: s.cfg.Output.Languages})
 }
 
 func splitPath(fullPath, prefix string) (string, string, bool) {
@@ -339,6 +411,6 @@ func setCORS(w http.ResponseWriter, extensionID string) {
 		origin = "chrome-extension://" + extensionID
 	}
 	w.Header().Set("Access-Control-Allow-Origin", origin)
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Encoding")
 }
@@ -7,7 +7,9 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/yourorg/apidoc/internal/config"
 	"github.com/yourorg/apidoc/internal/store"
@@ -133,6 +135,47 @@ func TestServerTrafficAndSessionDetail(t *testing.T) {
 	}
 }
 
+func TestServerAppendSessionTraffic(t *testing.T) {
+	srv, st := newTestServer(t)
+
+	sess, _ := st.CreateSession("extension", "checkout", "example.com")
+	_ = st.SaveLogs(sess.ID, []types.TrafficLog{{Seq: 1, Method: "GET", Host: "example.com", Path: "/cart", StatusCode: 200}})
+	_ = st.SaveBatchCache(&types.LLMCache{SessionID: sess.ID, BatchIndex: 0, BatchKey: "/cart", Status: "ok", RawOutput: "{}"})
+
+	body := []byte(`{"logs":[{"method":"POST","host":"example.com","path":"/cart","status_code":201},{"method":"POST","host":"example.com","path":"/orders","status_code":201}]}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions/"+sess.ID+"/traffic", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Appended int `json:"appended"`
+		LogCount int `json:"log_count"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Appended != 2 || resp.LogCount != 3 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	logs, _ := st.GetLogs(sess.ID)
+	if len(logs) != 3 || logs[1].Method != "POST" || logs[1].Seq != 2 || logs[2].Seq != 3 {
+		t.Fatalf("unexpected logs: %+v", logs)
+	}
+	if caches, _ := st.GetBatchCaches(sess.ID); len(caches) != 0 {
+		t.Fatalf("expected /cart cache to be invalidated, got %+v", caches)
+	}
+
+	missing := httptest.NewRequest(http.MethodPost, "/api/sessions/nope/traffic", bytes.NewReader(body))
+	missingRec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(missingRec, missing)
+	if missingRec.Code != http.StatusNotFound {
+		t.Fatalf("missing session status = %d", missingRec.Code)
+	}
+}
+
 func TestServerIndexHTML(t *testing.T) {
 	srv, _ := newTestServer(t)
 
@@ -148,3 +191,114 @@ func TestServerIndexHTML(t *testing.T) {
 		t.Fatalf("expected body to contain apidoc")
 	}
 }
+
+func TestServerSessionDiagram(t *testing.T) {
+	srv, st := newTestServer(t)
+	sess, err := st.CreateSession("har", "login", "api.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := `{"scenario":"login","call_chain":[{"seq":1,"method":"POST","path":"/login","description":"log in"}],"endpoints":[{"method":"POST","path":"/login","summary":"","description":"","responses":[{"status_code":200,"content_type":"","description":"ok"}]}]}`
+	if err := st.SaveBatchCache(&types.LLMCache{SessionID: sess.ID, BatchIndex: 0, BatchKey: "k", Status: "ok", RawOutput: raw}); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/sessions/"+sess.ID+"/diagram"+query, nil)
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+	rec := get("")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "participant S as api.example.com") {
+		t.Fatalf("mermaid diagram: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := get("?format=plantuml"); !strings.HasPrefix(rec.Body.String(), "@startuml") {
+		t.Fatalf("plantuml diagram: %s", rec.Body.String())
+	}
+	if rec := get("?kind=flow"); !strings.HasPrefix(rec.Body.String(), "flowchart TD") {
+		t.Fatalf("flow diagram: %s", rec.Body.String())
+	}
+	if rec := get("?format=svg"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown format status = %d", rec.Code)
+	}
+}
+
+func TestServerSessionDocLang(t *testing.T) {
+	srv, st := newTestServer(t)
+	srv.cfg.Output.Languages = []string{"zh", "en"}
+	sess, err := st.CreateSession("har", "login", "api.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := `{"scenario":"登录","endpoints":[{"method":"POST","path":"/login","summary":"登录","responses":[{"status_code":200,"description":"成功"}]}]}`
+	if err := st.SaveBatchCache(&types.LLMCache{SessionID: sess.ID, BatchIndex: 0, BatchKey: "k", Status: "ok", RawOutput: raw}); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+	if rec := get("/api/sessions/" + sess.ID + "/doc?lang=zh"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "登录") {
+		t.Fatalf("zh doc: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := get("/api/sessions/" + sess.ID + "/doc?lang=en"); rec.Code != http.StatusNotFound {
+		t.Fatalf("untranslated doc status = %d", rec.Code)
+	}
+	if rec := get("/api/sessions/" + sess.ID + "/diagram?lang=en"); rec.Code != http.StatusNotFound {
+		t.Fatalf("untranslated diagram status = %d", rec.Code)
+	}
+	if rec := get("/"); !strings.Contains(rec.Body.String(), `const languages = ["zh","en"] || [];`) {
+		t.Fatalf("ui should list the languages:\n%s", rec.Body.String())
+	}
+}
+
+func TestServerTrafficOrdersByStartedAt(t *testing.T) {
+	srv, st := newTestServer(t)
+
+	body := []byte(`{"scenario":"ordered","logs":[
+		{"method":"GET","host":"example.com","path":"/second","status_code":200,"started_at":"2024-05-01T10:00:02.500Z","latency_ms":30,
+		 "timings":{"blocked":-1,"dns":-1,"connect":-1,"ssl":-1,"send":1,"wait":25,"receive":4}},
+		{"method":"POST","host":"example.com","path":"/first","status_code":201,"started_at":"2024-05-01T10:00:01Z",
+		 "request_body":"eyJhIjoxfQ==","request_body_encoding":"base64","content_type":"application/json"}
+	]}`)
+	rec := doJSON(t, srv, http.MethodPost, "/api/traffic", body, false)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp map[string]string
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+	logs, err := st.GetLogs(resp["session_id"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 || logs[0].Path != "/first" || logs[0].Seq != 1 || logs[1].Path != "/second" || logs[1].Seq != 2 {
+		t.Fatalf("logs not ordered by started_at: %+v", logs)
+	}
+	if !logs[0].Timestamp.Equal(time.Date(2024, 5, 1, 10, 0, 1, 0, time.UTC)) {
+		t.Fatalf("started_at not preserved: %v", logs[0].Timestamp)
+	}
+	if logs[0].RequestBody != `{"a":1}` || logs[0].RequestBodyEncoding != "base64" {
+		t.Fatalf("base64 body not decoded: %q (%s)", logs[0].RequestBody, logs[0].RequestBodyEncoding)
+	}
+	if logs[1].Timings == nil || logs[1].Timings.Wait != 25 {
+		t.Fatalf("timings not preserved: %+v", logs[1].Timings)
+	}
+}
+
+func TestServerTrafficRejectsInvalidEntries(t *testing.T) {
+	srv, _ := newTestServer(t)
+	for name, entry := range map[string]string{
+		"started_at": `{"method":"GET","path":"/x","started_at":"yesterday"}`,
+		"encoding":   `{"method":"GET","path":"/x","request_body":"a","request_body_encoding":"rot13"}`,
+		"timings":    `{"method":"GET","path":"/x","timings":{"wait":-5}}`,
+		"latency":    `{"method":"GET","path":"/x","latency_ms":-1}`,
+	} {
+		rec := doJSON(t, srv, http.MethodPost, "/api/traffic", []byte(`{"logs":[`+entry+`]}`), false)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d", name, rec.Code)
+		}
+	}
+}
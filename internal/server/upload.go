@@ -0,0 +1,368 @@
+package server
+
+import (
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// uploadTTL bounds how long an unfinished or committed upload is kept.
+const uploadTTL = time.Hour
+
+var uploadIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// upload is an in-progress chunked ingest. Chunks are validated on arrival
+// and keyed by their sequence number, so a retried chunk simply replaces the
+// earlier copy.
+type upload struct {
+	mu        sync.Mutex
+	id        string
+	scenario  string
+	sessionID string
+	chunks    map[int][]types.TrafficLog
+	sizes     map[int]int64 // decoded bytes of each chunk
+	bytes     int64         // sum of sizes
+	committed bool
+	result    map[string]any
+	touched   time.Time
+}
+
+type uploads struct {
+	mu sync.Mutex
+	m  map[string]*upload
+}
+
+func newUploads() *uploads {
+	return &uploads{m: make(map[string]*upload)}
+}
+
+// open returns the upload for id, creating it when missing; an empty id gets
+// a random one. Reopening an existing id makes the call safe to retry.
+func (u *uploads) open(id, scenario, sessionID string) (*upload, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	now := time.Now()
+	u.sweep(now)
+	if id == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		id = hex.EncodeToString(b)
+	}
+	if up, ok := u.m[id]; ok {
+		return up, nil
+	}
+	up := &upload{id: id, scenario: scenario, sessionID: sessionID, chunks: make(map[int][]types.TrafficLog), sizes: make(map[int]int64), touched: now}
+	u.m[id] = up
+	return up, nil
+}
+
+func (u *uploads) get(id string) (*upload, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.sweep(time.Now())
+	up, ok := u.m[id]
+	return up, ok
+}
+
+// sweep drops uploads, committed or not, untouched for uploadTTL. Callers
+// hold u.mu.
+func (u *uploads) sweep(now time.Time) {
+	for k, up := range u.m {
+		up.mu.Lock()
+		expired := now.Sub(up.touched) > uploadTTL
+		up.mu.Unlock()
+		if expired {
+			delete(u.m, k)
+		}
+	}
+}
+
+// received lists chunk sequence numbers in order. Callers hold up.mu.
+func (up *upload) received() []int {
+	seqs := make([]int, 0, len(up.chunks))
+	for n := range up.chunks {
+		seqs = append(seqs, n)
+	}
+	sort.Ints(seqs)
+	return seqs
+}
+
+func (up *upload) status() map[string]any {
+	return map[string]any{
+		"upload_id": up.id,
+		"received":  up.received(),
+		"committed": up.committed,
+		"result":    up.result,
+	}
+}
+
+// handleUploadOpen starts a chunked upload:
+//
+//	POST /api/uploads                     {"scenario", "session_id"?, "upload_id"?}
+//	PUT  /api/uploads/{id}/chunks/{seq}   {"logs": [...]}, optionally gzip-encoded
+//	GET  /api/uploads/{id}                received chunks, for resuming
+//	POST /api/uploads/{id}/commit         {"chunks": n}
+//
+// session_id appends to an existing session instead of creating one.
+func (s *Server) handleUploadOpen(w http.ResponseWriter, r *http.Request) {
+	setCORS(w, s.cfg.Server.CORSExtensionID)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		UploadID  string `json:"upload_id"`
+		Scenario  string `json:"scenario"`
+		SessionID string `json:"session_id"`
+	}
+	if !s.decodeBody(w, r, &req) {
+		return
+	}
+	if req.UploadID != "" && !uploadIDPattern.MatchString(req.UploadID) {
+		http.Error(w, "invalid upload_id", http.StatusBadRequest)
+		return
+	}
+	if req.SessionID != "" {
+		if _, err := s.store.GetSession(req.SessionID); err != nil {
+			http.Error(w, "session not found", http.StatusNotFound)
+			return
+		}
+	}
+	up, err := s.uploads.open(req.UploadID, req.Scenario, req.SessionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	up.mu.Lock()
+	defer up.mu.Unlock()
+	writeJSON(w, http.StatusOK, up.status())
+}
+
+func (s *Server) handleUploadRoutes(w http.ResponseWriter, r *http.Request) {
+	setCORS(w, s.cfg.Server.CORSExtensionID)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	id, tail, ok := splitPath(r.URL.Path, "/api/uploads/")
+	if !ok || id == "" {
+		http.NotFound(w, r)
+		return
+	}
+	up, ok := s.uploads.get(id)
+	if !ok {
+		http.Error(w, "upload not found", http.StatusNotFound)
+		return
+	}
+	switch {
+	case tail == "":
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		up.mu.Lock()
+		defer up.mu.Unlock()
+		writeJSON(w, http.StatusOK, up.status())
+	case strings.HasPrefix(tail, "chunks/"):
+		s.handleUploadChunk(w, r, up, strings.TrimPrefix(tail, "chunks/"))
+	case tail == "commit":
+		s.handleUploadCommit(w, r, up)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) handleUploadChunk(w http.ResponseWriter, r *http.Request, up *upload, seqStr string) {
+	if r.Method != http.MethodPut && r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	seq, err := strconv.Atoi(seqStr)
+	if err != nil || seq < 0 {
+		http.Error(w, "invalid chunk sequence", http.StatusBadRequest)
+		return
+	}
+	if seq >= s.cfg.Server.MaxUploadChunks {
+		http.Error(w, "chunk sequence exceeds server.max_upload_chunks", http.StatusBadRequest)
+		return
+	}
+	var chunk trafficRequest
+	size, ok := s.decodeBodySize(w, r, &chunk)
+	if !ok {
+		return
+	}
+	logs, err := chunk.trafficLogs()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	up.mu.Lock()
+	defer up.mu.Unlock()
+	if up.committed {
+		http.Error(w, "upload already committed", http.StatusConflict)
+		return
+	}
+	// A retried chunk replaces its earlier copy, so only the difference counts.
+	if total := up.bytes - up.sizes[seq] + size; total > s.cfg.Server.MaxUploadBytes {
+		http.Error(w, "upload exceeds server.max_upload_bytes", http.StatusRequestEntityTooLarge)
+		return
+	}
+	up.bytes += size - up.sizes[seq]
+	up.sizes[seq] = size
+	up.chunks[seq] = logs
+	up.touched = time.Now()
+	writeJSON(w, http.StatusOK, up.status())
+}
+
+// handleUploadCommit stores chunks 0..n-1 in order. Committing again returns
+// the original result, so a client may retry after a lost response.
+func (s *Server) handleUploadCommit(w http.ResponseWriter, r *http.Request, up *upload) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Chunks int `json:"chunks"`
+	}
+	if !s.decodeBody(w, r, &req) {
+		return
+	}
+	up.mu.Lock()
+	defer up.mu.Unlock()
+	if up.committed {
+		writeJSON(w, http.StatusOK, up.result)
+		return
+	}
+	if req.Chunks <= 0 || req.Chunks > s.cfg.Server.MaxUploadChunks {
+		http.Error(w, "chunks must be between 1 and server.max_upload_chunks", http.StatusBadRequest)
+		return
+	}
+	var missing []int
+	for i := 0; i < req.Chunks; i++ {
+		if _, ok := up.chunks[i]; !ok {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) > 0 {
+		writeJSON(w, http.StatusConflict, map[string]any{"error": "missing chunks", "missing": missing})
+		return
+	}
+
+	var logs []types.TrafficLog
+	for i := 0; i < req.Chunks; i++ {
+		logs = append(logs, up.chunks[i]...)
+	}
+	var result map[string]any
+	if up.sessionID != "" {
+		sess, err := s.appendTraffic(up.sessionID, logs)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		result = map[string]any{"session_id": sess.ID, "appended": len(logs), "log_count": sess.LogCount, "status": sess.Status}
+	} else {
+		sess, err := s.importTraffic(up.scenario, logs)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		result = map[string]any{"session_id": sess.ID, "log_count": sess.LogCount, "status": "imported"}
+	}
+	up.committed = true
+	up.result = result
+	up.chunks = make(map[int][]types.TrafficLog)
+	up.sizes = make(map[int]int64)
+	up.bytes = 0
+	up.touched = time.Now()
+	writeJSON(w, http.StatusOK, result)
+}
+
+// errBodyTooLarge is returned when a decompressed body exceeds the limit.
+var errBodyTooLarge = errors.New("request body too large")
+
+// decodeBody decodes a JSON body, accepting Content-Encoding: gzip and
+// enforcing server.max_body_bytes on both the wire and decoded size. It
+// writes the error response itself and reports whether decoding succeeded.
+func (s *Server) decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	_, ok := s.decodeBodySize(w, r, v)
+	return ok
+}
+
+// decodeBodySize is decodeBody, also returning the decoded size in bytes.
+func (s *Server) decodeBodySize(w http.ResponseWriter, r *http.Request, v any) (int64, bool) {
+	limit := s.cfg.Server.MaxBodyBytes
+	var body io.Reader = http.MaxBytesReader(w, r.Body, limit)
+	if strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+		zr, err := gzip.NewReader(body)
+		if err != nil {
+			http.Error(w, "invalid gzip body: "+err.Error(), http.StatusBadRequest)
+			return 0, false
+		}
+		defer zr.Close()
+		body = &capReader{r: zr, n: limit}
+	}
+	counted := &countReader{r: body}
+	if err := json.NewDecoder(counted).Decode(v); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) || errors.Is(err, errBodyTooLarge) {
+			http.Error(w, errBodyTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return 0, false
+		}
+		http.Error(w, "invalid json: "+err.Error(), http.StatusBadRequest)
+		return 0, false
+	}
+	return counted.n, true
+}
+
+// countReader counts the bytes read through it.
+type countReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// capReader yields at most n bytes, then fails with errBodyTooLarge if the
+// underlying reader has more.
+type capReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *capReader) Read(p []byte) (int, error) {
+	if c.n <= 0 {
+		var one [1]byte
+		if n, _ := c.r.Read(one[:]); n > 0 {
+			return 0, errBodyTooLarge
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > c.n {
+		p = p[:c.n]
+	}
+	n, err := c.r.Read(p)
+	c.n -= int64(n)
+	return n, err
+}
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func doJSON(t *testing.T, srv *Server, method, path string, body []byte, gzipped bool) *httptest.ResponseRecorder {
+	t.Helper()
+	if gzipped {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, _ = zw.Write(body)
+		_ = zw.Close()
+		body = buf.Bytes()
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if gzipped {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func chunkBody(paths ...string) []byte {
+	logs := make([]map[string]any, 0, len(paths))
+	for _, p := range paths {
+		logs = append(logs, map[string]any{"method": "GET", "host": "example.com", "path": p, "status_code": 200})
+	}
+	b, _ := json.Marshal(map[string]any{"logs": logs})
+	return b
+}
+
+func TestChunkedUpload(t *testing.T) {
+	srv, st := newTestServer(t)
+
+	rec := doJSON(t, srv, http.MethodPost, "/api/uploads", []byte(`{"scenario":"long","upload_id":"rec-1"}`), false)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("open status = %d: %s", rec.Code, rec.Body.String())
+	}
+	// Reopening with the same id is a no-op retry.
+	if rec := doJSON(t, srv, http.MethodPost, "/api/uploads", []byte(`{"scenario":"long","upload_id":"rec-1"}`), false); rec.Code != http.StatusOK {
+		t.Fatalf("reopen status = %d", rec.Code)
+	}
+
+	// Chunks arrive out of order, gzipped, and chunk 1 is retried.
+	for _, c := range []struct {
+		seq   int
+		paths []string
+	}{
+		{1, []string{"/c", "/d"}},
+		{0, []string{"/a", "/b"}},
+		{1, []string{"/c", "/d"}},
+	} {
+		rec := doJSON(t, srv, http.MethodPut, fmt.Sprintf("/api/uploads/rec-1/chunks/%d", c.seq), chunkBody(c.paths...), true)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("chunk %d status = %d: %s", c.seq, rec.Code, rec.Body.String())
+		}
+	}
+
+	rec = doJSON(t, srv, http.MethodPost, "/api/uploads/rec-1/commit", []byte(`{"chunks":3}`), false)
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), `"missing":[2]`) {
+		t.Fatalf("expected missing chunk 2, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = doJSON(t, srv, http.MethodPost, "/api/uploads/rec-1/commit", []byte(`{"chunks":2}`), false)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("commit status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var first map[string]any
+	_ = json.Unmarshal(rec.Body.Bytes(), &first)
+	sessionID, _ := first["session_id"].(string)
+	if sessionID == "" {
+		t.Fatalf("missing session_id: %v", first)
+	}
+
+	rec = doJSON(t, srv, http.MethodPost, "/api/uploads/rec-1/commit", []byte(`{"chunks":2}`), false)
+	var second map[string]any
+	_ = json.Unmarshal(rec.Body.Bytes(), &second)
+	if rec.Code != http.StatusOK || second["session_id"] != sessionID {
+		t.Fatalf("retried commit should return the same session: %d %v", rec.Code, second)
+	}
+
+	logs, err := st.GetLogs(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, 0, len(logs))
+	for _, l := range logs {
+		got = append(got, fmt.Sprintf("%d%s", l.Seq, l.Path))
+	}
+	if strings.Join(got, ",") != "1/a,2/b,3/c,4/d" {
+		t.Fatalf("unexpected logs: %v", got)
+	}
+	if sessions, _ := st.ListSessions(); len(sessions) != 1 {
+		t.Fatalf("expected exactly one session, got %d", len(sessions))
+	}
+}
+
+func TestUploadAppendsToSession(t *testing.T) {
+	srv, st := newTestServer(t)
+	sess, _ := st.CreateSession("extension", "base", "example.com")
+
+	rec := doJSON(t, srv, http.MethodPost, "/api/uploads", []byte(`{"session_id":"`+sess.ID+`"}`), false)
+	var opened struct {
+		UploadID string `json:"upload_id"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &opened)
+	if rec.Code != http.StatusOK || opened.UploadID == "" {
+		t.Fatalf("open failed: %d %s", rec.Code, rec.Body.String())
+	}
+	doJSON(t, srv, http.MethodPut, "/api/uploads/"+opened.UploadID+"/chunks/0", chunkBody("/x"), false)
+	rec = doJSON(t, srv, http.MethodPost, "/api/uploads/"+opened.UploadID+"/commit", []byte(`{"chunks":1}`), false)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("commit status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if got, _ := st.GetSession(sess.ID); got.LogCount != 1 {
+		t.Fatalf("expected log appended, log_count = %d", got.LogCount)
+	}
+
+	if rec := doJSON(t, srv, http.MethodPost, "/api/uploads", []byte(`{"session_id":"missing"}`), false); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown session, got %d", rec.Code)
+	}
+}
+
+func TestUploadLimits(t *testing.T) {
+	srv, _ := newTestServer(t)
+	srv.cfg.Server.MaxUploadChunks = 4
+	chunk := chunkBody("/a")
+	srv.cfg.Server.MaxUploadBytes = int64(len(chunk))*2 + 1
+
+	if rec := doJSON(t, srv, http.MethodPost, "/api/uploads", []byte(`{"upload_id":"big"}`), false); rec.Code != http.StatusOK {
+		t.Fatalf("open status = %d", rec.Code)
+	}
+	if rec := doJSON(t, srv, http.MethodPut, "/api/uploads/big/chunks/4", chunk, false); rec.Code != http.StatusBadRequest {
+		t.Fatalf("chunk past max_upload_chunks: status = %d", rec.Code)
+	}
+	if rec := doJSON(t, srv, http.MethodPost, "/api/uploads/big/commit", []byte(`{"chunks":2000000000}`), false); rec.Code != http.StatusBadRequest {
+		t.Fatalf("commit past max_upload_chunks: status = %d", rec.Code)
+	}
+	// Retried chunks replace their earlier size rather than adding to it.
+	for _, seq := range []int{0, 1, 1, 0} {
+		if rec := doJSON(t, srv, http.MethodPut, fmt.Sprintf("/api/uploads/big/chunks/%d", seq), chunk, true); rec.Code != http.StatusOK {
+			t.Fatalf("chunk %d status = %d: %s", seq, rec.Code, rec.Body.String())
+		}
+	}
+	if rec := doJSON(t, srv, http.MethodPut, "/api/uploads/big/chunks/2", chunk, false); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("chunk past max_upload_bytes: status = %d", rec.Code)
+	}
+
+	// Expired uploads are dropped on any access, not only on open.
+	up, _ := srv.uploads.get("big")
+	up.mu.Lock()
+	up.touched = time.Now().Add(-2 * uploadTTL)
+	up.mu.Unlock()
+	if rec := doJSON(t, srv, http.MethodGet, "/api/uploads/big", nil, false); rec.Code != http.StatusNotFound {
+		t.Fatalf("expired upload: status = %d", rec.Code)
+	}
+}
+
+func TestIngestBodyLimit(t *testing.T) {
+	srv, _ := newTestServer(t)
+	srv.cfg.Server.MaxBodyBytes = 256
+
+	big := chunkBody(strings.Repeat("/p", 200))
+	if rec := doJSON(t, srv, http.MethodPost, "/api/traffic", big, false); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("plain body: status = %d", rec.Code)
+	}
+	// Compresses well below the limit but expands past it.
+	if rec := doJSON(t, srv, http.MethodPost, "/api/traffic", big, true); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("gzip body: status = %d", rec.Code)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	_ "modernc.org/sqlite"
@@ -84,6 +85,73 @@ func (s *SQLiteStore) Init() error {
 			return err
 		}
 	}
+	return s.migrate()
+}
+
+// migrations are applied in order on top of the base schema; the index of
+// the last applied migration (plus one) is tracked in PRAGMA user_version.
+var migrations = [][]string{
+	// 1: multi-valued headers, cookies, HTTP version, form params and timings.
+	// Header columns keep their JSON encoding; the legacy {"k":"v"} form is
+	// still accepted by types.Headers on read.
+	{
+		`ALTER TABLE traffic_logs ADD COLUMN http_version TEXT NOT NULL DEFAULT '';`,
+		`ALTER TABLE traffic_logs ADD COLUMN request_cookies TEXT NOT NULL DEFAULT '';`,
+		`ALTER TABLE traffic_logs ADD COLUMN response_cookies TEXT NOT NULL DEFAULT '';`,
+		`ALTER TABLE traffic_logs ADD COLUMN form_params TEXT NOT NULL DEFAULT '';`,
+		`ALTER TABLE traffic_logs ADD COLUMN timings TEXT NOT NULL DEFAULT '';`,
+	},
+	// 2: request scheme, used for OpenAPI servers.
+	{
+		`ALTER TABLE traffic_logs ADD COLUMN scheme TEXT NOT NULL DEFAULT '';`,
+	},
+	// 3: prompt version of each cached output; older rows never match.
+	{
+		`ALTER TABLE llm_cache ADD COLUMN prompt_version TEXT NOT NULL DEFAULT '';`,
+	},
+	// 4: translations get their own table keyed by language. They used to sit
+	// in llm_cache at negative batch indexes with batch_key "lang:<code>".
+	{
+		`CREATE TABLE translations (
+			session_id TEXT NOT NULL,
+			lang TEXT NOT NULL,
+			texts TEXT NOT NULL,
+			model TEXT NOT NULL,
+			prompt_version TEXT NOT NULL,
+			created_at DATETIME NOT NULL,
+			PRIMARY KEY(session_id, lang)
+		);`,
+		`INSERT INTO translations(session_id,lang,texts,model,prompt_version,created_at)
+		SELECT session_id, substr(batch_key, 6), raw_output, model, prompt_version, created_at
+		FROM llm_cache WHERE batch_index < 0 AND status = 'ok' AND batch_key LIKE 'lang:%';`,
+		`DELETE FROM llm_cache WHERE batch_index < 0;`,
+	},
+}
+
+func (s *SQLiteStore) migrate() error {
+	var version int
+	if err := s.db.QueryRow(`PRAGMA user_version;`).Scan(&version); err != nil {
+		return err
+	}
+	for i := version; i < len(migrations); i++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+		for _, stmt := range migrations[i] {
+			if _, err := tx.Exec(stmt); err != nil {
+				_ = tx.Rollback()
+				return fmt.Errorf("migration %d: %w", i+1, err)
+			}
+		}
+		if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d;`, i+1)); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -164,6 +232,9 @@ func (s *SQLiteStore) DeleteSession(id string) error {
 	if _, err := tx.Exec(`DELETE FROM llm_cache WHERE session_id=?`, id); err != nil {
 		return err
 	}
+	if _, err := tx.Exec(`DELETE FROM translations WHERE session_id=?`, id); err != nil {
+		return err
+	}
 	if _, err := tx.Exec(`DELETE FROM sessions WHERE id=?`, id); err != nil {
 		return err
 	}
@@ -176,7 +247,41 @@ func (s *SQLiteStore) SaveLogs(sessionID string, logs []types.TrafficLog) error
 		return err
 	}
 	defer tx.Rollback()
-	stmt, err := tx.Prepare(`INSERT INTO traffic_logs(session_id,seq,timestamp,method,host,path,query_params,request_headers,request_body,request_body_encoding,content_type,status_code,response_headers,response_body,response_content_type,latency_ms,call_count) VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`)
+	if err := insertLogs(tx, sessionID, logs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) AppendLogs(sessionID string, logs []types.TrafficLog) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	var exists int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM sessions WHERE id=?`, sessionID).Scan(&exists); err != nil {
+		return err
+	}
+	if exists == 0 {
+		return sql.ErrNoRows
+	}
+	var last int
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(seq),0) FROM traffic_logs WHERE session_id=?`, sessionID).Scan(&last); err != nil {
+		return err
+	}
+	for i := range logs {
+		logs[i].Seq = last + i + 1
+	}
+	if err := insertLogs(tx, sessionID, logs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertLogs writes logs with their existing Seq and bumps the session's log_count.
+func insertLogs(tx *sql.Tx, sessionID string, logs []types.TrafficLog) error {
+	stmt, err := tx.Prepare(`INSERT INTO traffic_logs(session_id,seq,timestamp,method,scheme,host,path,http_version,query_params,request_headers,request_cookies,request_body,request_body_encoding,form_params,content_type,status_code,response_headers,response_cookies,response_body,response_content_type,latency_ms,timings,call_count) VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`)
 	if err != nil {
 		return err
 	}
@@ -185,22 +290,24 @@ func (s *SQLiteStore) SaveLogs(sessionID string, logs []types.TrafficLog) error
 		qp, _ := json.Marshal(l.QueryParams)
 		rh, _ := json.Marshal(l.RequestHeaders)
 		respH, _ := json.Marshal(l.ResponseHeaders)
+		reqC := marshalOptional(l.RequestCookies, len(l.RequestCookies) > 0)
+		respC := marshalOptional(l.ResponseCookies, len(l.ResponseCookies) > 0)
+		form := marshalOptional(l.FormParams, len(l.FormParams) > 0)
+		timings := marshalOptional(l.Timings, l.Timings != nil)
 		callCount := l.CallCount
 		if callCount == 0 {
 			callCount = 1
 		}
-		if _, err := stmt.Exec(sessionID, l.Seq, l.Timestamp, l.Method, l.Host, l.Path, string(qp), string(rh), l.RequestBody, l.RequestBodyEncoding, l.ContentType, l.StatusCode, string(respH), l.ResponseBody, l.ResponseContentType, l.LatencyMs, callCount); err != nil {
+		if _, err := stmt.Exec(sessionID, l.Seq, l.Timestamp, l.Method, l.Scheme, l.Host, l.Path, l.HTTPVersion, string(qp), string(rh), reqC, l.RequestBody, l.RequestBodyEncoding, form, l.ContentType, l.StatusCode, string(respH), respC, l.ResponseBody, l.ResponseContentType, l.LatencyMs, timings, callCount); err != nil {
 			return err
 		}
 	}
-	if _, err := tx.Exec(`UPDATE sessions SET log_count=log_count+?, updated_at=? WHERE id=?`, len(logs), time.Now().UTC(), sessionID); err != nil {
-		return err
-	}
-	return tx.Commit()
+	_, err = tx.Exec(`UPDATE sessions SET log_count=log_count+?, updated_at=? WHERE id=?`, len(logs), time.Now().UTC(), sessionID)
+	return err
 }
 
 func (s *SQLiteStore) GetLogs(sessionID string) ([]types.TrafficLog, error) {
-	rows, err := s.db.Query(`SELECT id,session_id,seq,timestamp,method,host,path,query_params,request_headers,request_body,request_body_encoding,content_type,status_code,response_headers,response_body,response_content_type,latency_ms,call_count FROM traffic_logs WHERE session_id=? ORDER BY seq ASC`, sessionID)
+	rows, err := s.db.Query(`SELECT id,session_id,seq,timestamp,method,scheme,host,path,http_version,query_params,request_headers,request_cookies,request_body,request_body_encoding,form_params,content_type,status_code,response_headers,response_cookies,response_body,response_content_type,latency_ms,timings,call_count FROM traffic_logs WHERE session_id=? ORDER BY seq ASC`, sessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -208,8 +315,8 @@ func (s *SQLiteStore) GetLogs(sessionID string) ([]types.TrafficLog, error) {
 	out := make([]types.TrafficLog, 0)
 	for rows.Next() {
 		var l types.TrafficLog
-		var qpS, rhS, respHS string
-		if err := rows.Scan(&l.ID, &l.SessionID, &l.Seq, &l.Timestamp, &l.Method, &l.Host, &l.Path, &qpS, &rhS, &l.RequestBody, &l.RequestBodyEncoding, &l.ContentType, &l.StatusCode, &respHS, &l.ResponseBody, &l.ResponseContentType, &l.LatencyMs, &l.CallCount); err != nil {
+		var qpS, rhS, reqCS, formS, respHS, respCS, timingsS string
+		if err := rows.Scan(&l.ID, &l.SessionID, &l.Seq, &l.Timestamp, &l.Method, &l.Scheme, &l.Host, &l.Path, &l.HTTPVersion, &qpS, &rhS, &reqCS, &l.RequestBody, &l.RequestBodyEncoding, &formS, &l.ContentType, &l.StatusCode, &respHS, &respCS, &l.ResponseBody, &l.ResponseContentType, &l.LatencyMs, &timingsS, &l.CallCount); err != nil {
 			return nil, err
 		}
 		if qpS != "" {
@@ -221,24 +328,46 @@ func (s *SQLiteStore) GetLogs(sessionID string) ([]types.TrafficLog, error) {
 		if respHS != "" {
 			_ = json.Unmarshal([]byte(respHS), &l.ResponseHeaders)
 		}
+		if reqCS != "" {
+			_ = json.Unmarshal([]byte(reqCS), &l.RequestCookies)
+		}
+		if respCS != "" {
+			_ = json.Unmarshal([]byte(respCS), &l.ResponseCookies)
+		}
+		if formS != "" {
+			_ = json.Unmarshal([]byte(formS), &l.FormParams)
+		}
+		if timingsS != "" {
+			_ = json.Unmarshal([]byte(timingsS), &l.Timings)
+		}
 		out = append(out, l)
 	}
 	return out, rows.Err()
 }
 
+// marshalOptional encodes v as JSON, or returns "" when present is false so
+// absent values stay empty in the database.
+func marshalOptional(v any, present bool) string {
+	if !present {
+		return ""
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
 func (s *SQLiteStore) SaveBatchCache(cache *types.LLMCache) error {
 	if cache.CreatedAt.IsZero() {
 		cache.CreatedAt = time.Now().UTC()
 	}
-	_, err := s.db.Exec(`INSERT INTO llm_cache(session_id,batch_index,batch_key,status,raw_output,model,tokens_used,error_msg,created_at)
-	VALUES(?,?,?,?,?,?,?,?,?)
-	ON CONFLICT(session_id,batch_index) DO UPDATE SET batch_key=excluded.batch_key,status=excluded.status,raw_output=excluded.raw_output,model=excluded.model,tokens_used=excluded.tokens_used,error_msg=excluded.error_msg,created_at=excluded.created_at`,
-		cache.SessionID, cache.BatchIndex, cache.BatchKey, cache.Status, cache.RawOutput, cache.Model, cache.TokensUsed, cache.ErrorMsg, cache.CreatedAt)
+	_, err := s.db.Exec(`INSERT INTO llm_cache(session_id,batch_index,batch_key,status,raw_output,model,tokens_used,error_msg,created_at,prompt_version)
+	VALUES(?,?,?,?,?,?,?,?,?,?)
+	ON CONFLICT(session_id,batch_index) DO UPDATE SET batch_key=excluded.batch_key,status=excluded.status,raw_output=excluded.raw_output,model=excluded.model,tokens_used=excluded.tokens_used,error_msg=excluded.error_msg,created_at=excluded.created_at,prompt_version=excluded.prompt_version`,
+		cache.SessionID, cache.BatchIndex, cache.BatchKey, cache.Status, cache.RawOutput, cache.Model, cache.TokensUsed, cache.ErrorMsg, cache.CreatedAt, cache.PromptVersion)
 	return err
 }
 
 func (s *SQLiteStore) GetBatchCaches(sessionID string) ([]types.LLMCache, error) {
-	rows, err := s.db.Query(`SELECT session_id,batch_index,batch_key,status,raw_output,model,tokens_used,error_msg,created_at FROM llm_cache WHERE session_id=?`, sessionID)
+	rows, err := s.db.Query(`SELECT session_id,batch_index,batch_key,status,raw_output,model,tokens_used,error_msg,created_at,prompt_version FROM llm_cache WHERE session_id=?`, sessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -246,7 +375,7 @@ func (s *SQLiteStore) GetBatchCaches(sessionID string) ([]types.LLMCache, error)
 	var out []types.LLMCache
 	for rows.Next() {
 		var c types.LLMCache
-		if err := rows.Scan(&c.SessionID, &c.BatchIndex, &c.BatchKey, &c.Status, &c.RawOutput, &c.Model, &c.TokensUsed, &c.ErrorMsg, &c.CreatedAt); err != nil {
+		if err := rows.Scan(&c.SessionID, &c.BatchIndex, &c.BatchKey, &c.Status, &c.RawOutput, &c.Model, &c.TokensUsed, &c.ErrorMsg, &c.CreatedAt, &c.PromptVersion); err != nil {
 			return nil, err
 		}
 		out = append(out, c)
@@ -256,7 +385,7 @@ func (s *SQLiteStore) GetBatchCaches(sessionID string) ([]types.LLMCache, error)
 }
 
 func (s *SQLiteStore) GetFailedBatches(sessionID string) ([]types.LLMCache, error) {
-	rows, err := s.db.Query(`SELECT session_id,batch_index,batch_key,status,raw_output,model,tokens_used,error_msg,created_at FROM llm_cache WHERE session_id=? AND status='failed' ORDER BY batch_index ASC`, sessionID)
+	rows, err := s.db.Query(`SELECT session_id,batch_index,batch_key,status,raw_output,model,tokens_used,error_msg,created_at,prompt_version FROM llm_cache WHERE session_id=? AND status='failed' ORDER BY batch_index ASC`, sessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -264,7 +393,7 @@ func (s *SQLiteStore) GetFailedBatches(sessionID string) ([]types.LLMCache, erro
 	var out []types.LLMCache
 	for rows.Next() {
 		var c types.LLMCache
-		if err := rows.Scan(&c.SessionID, &c.BatchIndex, &c.BatchKey, &c.Status, &c.RawOutput, &c.Model, &c.TokensUsed, &c.ErrorMsg, &c.CreatedAt); err != nil {
+		if err := rows.Scan(&c.SessionID, &c.BatchIndex, &c.BatchKey, &c.Status, &c.RawOutput, &c.Model, &c.TokensUsed, &c.ErrorMsg, &c.CreatedAt, &c.PromptVersion); err != nil {
 			return nil, err
 		}
 		out = append(out, c)
@@ -273,10 +402,65 @@ func (s *SQLiteStore) GetFailedBatches(sessionID string) ([]types.LLMCache, erro
 }
 
 func (s *SQLiteStore) ClearCaches(sessionID string) error {
-	_, err := s.db.Exec(`DELETE FROM llm_cache WHERE session_id=?`, sessionID)
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM llm_cache WHERE session_id=?`, sessionID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM translations WHERE session_id=?`, sessionID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) ClearBatchCaches(sessionID string, batchKeys []string) error {
+	if len(batchKeys) == 0 {
+		return nil
+	}
+	args := make([]any, 0, len(batchKeys)+1)
+	args = append(args, sessionID)
+	for _, k := range batchKeys {
+		args = append(args, k)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(batchKeys)), ",")
+	_, err := s.db.Exec(`DELETE FROM llm_cache WHERE session_id=? AND batch_key IN (`+placeholders+`)`, args...)
 	return err
 }
 
+func (s *SQLiteStore) SaveTranslation(t *types.Translation) error {
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now().UTC()
+	}
+	texts, err := json.Marshal(t.Texts)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT INTO translations(session_id,lang,texts,model,prompt_version,created_at)
+	VALUES(?,?,?,?,?,?)
+	ON CONFLICT(session_id,lang) DO UPDATE SET texts=excluded.texts,model=excluded.model,prompt_version=excluded.prompt_version,created_at=excluded.created_at`,
+		t.SessionID, t.Lang, string(texts), t.Model, t.PromptVersion, t.CreatedAt)
+	return err
+}
+
+func (s *SQLiteStore) GetTranslation(sessionID, lang string) (*types.Translation, error) {
+	row := s.db.QueryRow(`SELECT session_id,lang,texts,model,prompt_version,created_at FROM translations WHERE session_id=? AND lang=?`, sessionID, lang)
+	var t types.Translation
+	var texts string
+	if err := row.Scan(&t.SessionID, &t.Lang, &texts, &t.Model, &t.PromptVersion, &t.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(texts), &t.Texts); err != nil {
+		return nil, fmt.Errorf("translation %s/%s: %w", sessionID, lang, err)
+	}
+	return &t, nil
+}
+
 func (s *SQLiteStore) Close() error {
 	if s.db == nil {
 		return errors.New("store is nil")
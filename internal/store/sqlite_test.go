@@ -1,6 +1,7 @@
 package store
 
 import (
+	"database/sql"
 	"fmt"
 	"path/filepath"
 	"sync"
@@ -30,7 +31,7 @@ func TestSessionAndLogsCRUD(t *testing.T) {
 	if sess.ID == "" {
 		t.Fatalf("empty session id")
 	}
-	if err := s.SaveLogs(sess.ID, []types.TrafficLog{{Seq: 1, Timestamp: time.Now().UTC(), Method: "GET", Host: "api.example.com", Path: "/v1/me", QueryParams: map[string][]string{"a": {"1", "2"}}, RequestHeaders: map[string]string{"Accept": "application/json"}, RequestBodyEncoding: "plain", StatusCode: 200, ResponseHeaders: map[string]string{"Content-Type": "application/json"}, LatencyMs: 10}}); err != nil {
+	if err := s.SaveLogs(sess.ID, []types.TrafficLog{{Seq: 1, Timestamp: time.Now().UTC(), Method: "GET", Host: "api.example.com", Path: "/v1/me", QueryParams: map[string][]string{"a": {"1", "2"}}, RequestHeaders: types.Headers{"Accept": {"application/json"}}, RequestBodyEncoding: "plain", StatusCode: 200, ResponseHeaders: types.Headers{"Content-Type": {"application/json"}}, LatencyMs: 10}}); err != nil {
 		t.Fatal(err)
 	}
 	logs, err := s.GetLogs(sess.ID)
@@ -45,17 +46,105 @@ func TestSessionAndLogsCRUD(t *testing.T) {
 	}
 }
 
+func TestLogsRoundTripHeadersCookiesTimings(t *testing.T) {
+	s := newTestStore(t)
+	defer s.Close()
+
+	sess, _ := s.CreateSession("har", "cookies", "api.example.com")
+	in := types.TrafficLog{
+		Seq:             1,
+		Timestamp:       time.Now().UTC(),
+		Method:          "POST",
+		Scheme:          "https",
+		Host:            "api.example.com",
+		Path:            "/v1/login",
+		HTTPVersion:     "HTTP/2",
+		RequestCookies:  []types.Cookie{{Name: "sid", Value: "abc"}},
+		FormParams:      map[string][]string{"user": {"alice"}},
+		StatusCode:      200,
+		ResponseHeaders: types.Headers{"Set-Cookie": {"sid=abc; HttpOnly", "theme=dark"}},
+		ResponseCookies: []types.Cookie{{Name: "sid", Value: "abc", HTTPOnly: true}, {Name: "theme", Value: "dark"}},
+		Timings:         &types.Timings{Blocked: -1, DNS: 2, Connect: 3, SSL: -1, Send: 1, Wait: 20, Receive: 4},
+		LatencyMs:       30,
+	}
+	if err := s.SaveLogs(sess.ID, []types.TrafficLog{in}); err != nil {
+		t.Fatal(err)
+	}
+	logs, err := s.GetLogs(sess.ID)
+	if err != nil || len(logs) != 1 {
+		t.Fatalf("get logs: %v (%d)", err, len(logs))
+	}
+	got := logs[0]
+	if got.Scheme != "https" {
+		t.Fatalf("scheme not stored: %q", got.Scheme)
+	}
+	if got.HTTPVersion != "HTTP/2" {
+		t.Fatalf("http version not stored: %q", got.HTTPVersion)
+	}
+	if len(got.ResponseHeaders.Values("set-cookie")) != 2 {
+		t.Fatalf("expected both Set-Cookie headers, got %v", got.ResponseHeaders)
+	}
+	if len(got.ResponseCookies) != 2 || !got.ResponseCookies[0].HTTPOnly {
+		t.Fatalf("unexpected response cookies: %+v", got.ResponseCookies)
+	}
+	if len(got.RequestCookies) != 1 || got.FormParams["user"][0] != "alice" {
+		t.Fatalf("unexpected request cookies/form params: %+v %v", got.RequestCookies, got.FormParams)
+	}
+	if got.Timings == nil || got.Timings.Wait != 20 {
+		t.Fatalf("timings not stored: %+v", got.Timings)
+	}
+}
+
+func TestMigrateLegacyDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy := []string{
+		`CREATE TABLE sessions (id TEXT PRIMARY KEY, source TEXT NOT NULL, scenario TEXT NOT NULL, host TEXT NOT NULL, log_count INTEGER NOT NULL DEFAULT 0, status TEXT NOT NULL, created_at DATETIME NOT NULL, updated_at DATETIME NOT NULL);`,
+		`CREATE TABLE traffic_logs (id INTEGER PRIMARY KEY AUTOINCREMENT, session_id TEXT NOT NULL, seq INTEGER NOT NULL, timestamp DATETIME NOT NULL, method TEXT NOT NULL, host TEXT NOT NULL, path TEXT NOT NULL, query_params TEXT, request_headers TEXT, request_body TEXT, request_body_encoding TEXT, content_type TEXT, status_code INTEGER NOT NULL, response_headers TEXT, response_body TEXT, response_content_type TEXT, latency_ms INTEGER NOT NULL, call_count INTEGER NOT NULL DEFAULT 1);`,
+		`INSERT INTO sessions VALUES('sess_old','har','old','h',1,'imported','2026-01-01T00:00:00Z','2026-01-01T00:00:00Z');`,
+		`INSERT INTO traffic_logs(session_id,seq,timestamp,method,host,path,query_params,request_headers,request_body,request_body_encoding,content_type,status_code,response_headers,response_body,response_content_type,latency_ms,call_count) VALUES('sess_old',1,'2026-01-01T00:00:00Z','GET','h','/v1','{}','{"Accept":"application/json"}','','plain','',200,'{}','','',5,1);`,
+	}
+	for _, stmt := range legacy {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = db.Close()
+
+	s, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("open legacy db: %v", err)
+	}
+	defer s.Close()
+	logs, err := s.GetLogs("sess_old")
+	if err != nil || len(logs) != 1 {
+		t.Fatalf("get legacy logs: %v (%d)", err, len(logs))
+	}
+	if logs[0].RequestHeaders.Get("Accept") != "application/json" {
+		t.Fatalf("legacy headers not decoded: %v", logs[0].RequestHeaders)
+	}
+}
+
 func TestCacheAndCascadeDelete(t *testing.T) {
 	s := newTestStore(t)
 	defer s.Close()
 
 	sess, _ := s.CreateSession("har", "flow", "api.example.com")
 	_ = s.SaveLogs(sess.ID, []types.TrafficLog{{Seq: 1, Timestamp: time.Now().UTC(), Method: "GET", Host: "api.example.com", Path: "/v1", RequestBodyEncoding: "plain", StatusCode: 200, LatencyMs: 1}})
-	_ = s.SaveBatchCache(&types.LLMCache{SessionID: sess.ID, BatchIndex: 0, BatchKey: "k", Status: "failed", Model: "gpt-4o", ErrorMsg: "boom"})
+	_ = s.SaveBatchCache(&types.LLMCache{SessionID: sess.ID, BatchIndex: 0, BatchKey: "k", Status: "failed", Model: "gpt-4o", ErrorMsg: "boom", PromptVersion: "v1-abc"})
+	if err := s.SaveTranslation(&types.Translation{SessionID: sess.ID, Lang: "en", Texts: map[string]string{"用户": "User"}, Model: "gpt-4o", PromptVersion: "v1-abc"}); err != nil {
+		t.Fatal(err)
+	}
+	if tr, err := s.GetTranslation(sess.ID, "en"); err != nil || tr == nil || tr.Texts["用户"] != "User" || tr.PromptVersion != "v1-abc" {
+		t.Fatalf("translation mismatch: %+v err=%v", tr, err)
+	}
 
 	failed, err := s.GetFailedBatches(sess.ID)
-	if err != nil || len(failed) != 1 {
-		t.Fatalf("failed batches mismatch")
+	if err != nil || len(failed) != 1 || failed[0].PromptVersion != "v1-abc" {
+		t.Fatalf("failed batches mismatch: %+v", failed)
 	}
 	if err := s.DeleteSession(sess.ID); err != nil {
 		t.Fatal(err)
@@ -66,6 +155,93 @@ func TestCacheAndCascadeDelete(t *testing.T) {
 	if caches, _ := s.GetBatchCaches(sess.ID); len(caches) != 0 {
 		t.Fatalf("expected caches deleted")
 	}
+	if tr, err := s.GetTranslation(sess.ID, "en"); err != nil || tr != nil {
+		t.Fatalf("expected translation deleted: %+v err=%v", tr, err)
+	}
+}
+
+func TestMigrateTranslationsOutOfBatchCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "v3.db")
+	s, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = s.Close()
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Before migration 4, translations sat at negative batch indexes.
+	v3 := []string{
+		`DROP TABLE translations;`,
+		`PRAGMA user_version = 3;`,
+		`INSERT INTO llm_cache VALUES('sess_old',0,'k','ok','{}','gpt-4o',0,'','2026-01-01T00:00:00Z','v1-abc');`,
+		`INSERT INTO llm_cache VALUES('sess_old',-2,'lang:en','ok','{"用户":"User"}','gpt-4o',0,'','2026-01-01T00:00:00Z','v1-abc');`,
+	}
+	for _, stmt := range v3 {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = db.Close()
+
+	s, err = NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	defer s.Close()
+	tr, err := s.GetTranslation("sess_old", "en")
+	if err != nil || tr == nil || tr.Texts["用户"] != "User" || tr.PromptVersion != "v1-abc" {
+		t.Fatalf("migrated translation = %+v err=%v", tr, err)
+	}
+	caches, err := s.GetBatchCaches("sess_old")
+	if err != nil || len(caches) != 1 || caches[0].BatchIndex != 0 {
+		t.Fatalf("only the batch cache should remain: %+v err=%v", caches, err)
+	}
+}
+
+func TestAppendLogsRenumbersAndClearsBatchCaches(t *testing.T) {
+	s := newTestStore(t)
+	defer s.Close()
+
+	sess, _ := s.CreateSession("har", "flow", "api.example.com")
+	now := time.Now().UTC()
+	_ = s.SaveLogs(sess.ID, []types.TrafficLog{
+		{Seq: 1, Timestamp: now, Method: "GET", Path: "/v1/users", StatusCode: 200},
+		{Seq: 2, Timestamp: now, Method: "GET", Path: "/v1/orders", StatusCode: 200},
+	})
+	_ = s.SaveBatchCache(&types.LLMCache{SessionID: sess.ID, BatchIndex: 0, BatchKey: "/v1/users", Status: "ok"})
+	_ = s.SaveBatchCache(&types.LLMCache{SessionID: sess.ID, BatchIndex: 1, BatchKey: "/v1/orders", Status: "ok"})
+
+	added := []types.TrafficLog{
+		{Seq: 1, Timestamp: now, Method: "POST", Path: "/v1/users", StatusCode: 201},
+		{Seq: 1, Timestamp: now, Method: "DELETE", Path: "/v1/users/1", StatusCode: 204},
+	}
+	if err := s.AppendLogs(sess.ID, added); err != nil {
+		t.Fatal(err)
+	}
+	if added[0].Seq != 3 || added[1].Seq != 4 {
+		t.Fatalf("appended logs not renumbered: %d, %d", added[0].Seq, added[1].Seq)
+	}
+	logs, _ := s.GetLogs(sess.ID)
+	if len(logs) != 4 || logs[2].Method != "POST" || logs[3].Seq != 4 {
+		t.Fatalf("unexpected logs after append: %+v", logs)
+	}
+	if got, _ := s.GetSession(sess.ID); got.LogCount != 4 {
+		t.Fatalf("log_count = %d, want 4", got.LogCount)
+	}
+
+	if err := s.ClearBatchCaches(sess.ID, []string{"mixed", "/v1/users"}); err != nil {
+		t.Fatal(err)
+	}
+	caches, _ := s.GetBatchCaches(sess.ID)
+	if len(caches) != 1 || caches[0].BatchKey != "/v1/orders" {
+		t.Fatalf("expected only /v1/orders cache to survive: %+v", caches)
+	}
+
+	if err := s.AppendLogs("missing", added); err == nil {
+		t.Fatal("expected error appending to a missing session")
+	}
 }
 
 func TestConcurrentReadWrite(t *testing.T) {
@@ -11,11 +11,20 @@ type Store interface {
 
 	SaveLogs(sessionID string, logs []types.TrafficLog) error
 	GetLogs(sessionID string) ([]types.TrafficLog, error)
+	// AppendLogs adds logs to an existing session, numbering them after its
+	// last Seq in the given order. The Seq fields of logs are updated in place.
+	AppendLogs(sessionID string, logs []types.TrafficLog) error
 
 	SaveBatchCache(cache *types.LLMCache) error
 	GetBatchCaches(sessionID string) ([]types.LLMCache, error)
 	GetFailedBatches(sessionID string) ([]types.LLMCache, error)
+	// ClearCaches drops the session's batch caches and translations.
 	ClearCaches(sessionID string) error
+	ClearBatchCaches(sessionID string, batchKeys []string) error
+
+	SaveTranslation(t *types.Translation) error
+	// GetTranslation returns nil when the session has no translation into lang.
+	GetTranslation(sessionID, lang string) (*types.Translation, error)
 
 	Close() error
 }
@@ -0,0 +1,233 @@
+package testgen
+
+import (
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+)
+
+func renderGo(scenario string, steps []Step, opts Options) ([]byte, error) {
+	b := &strings.Builder{}
+	fmt.Fprintln(b, "// Code generated by apidoc testgen; DO NOT EDIT.")
+	fmt.Fprintln(b)
+	if scenario != "" {
+		fmt.Fprintf(b, "// Contract tests for: %s\n", strings.ReplaceAll(scenario, "\n", " "))
+	}
+	fmt.Fprintf(b, "package %s\n\n", opts.Package)
+	b.WriteString(goImports)
+	fmt.Fprintf(b, "const contractBaseURL = %q\n\n", opts.BaseURL)
+	b.WriteString("var contractSteps = []contractStep{\n")
+	for _, s := range steps {
+		b.WriteString("{\n")
+		fmt.Fprintf(b, "name: %q,\nmethod: %q,\npath: %q,\n", s.Name, s.Method, s.Path)
+		if len(s.Query) > 0 {
+			keys := make([]string, 0, len(s.Query))
+			for k := range s.Query {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			b.WriteString("query: url.Values{")
+			for _, k := range keys {
+				fmt.Fprintf(b, "%q: {", k)
+				for i, v := range s.Query[k] {
+					if i > 0 {
+						b.WriteString(", ")
+					}
+					fmt.Fprintf(b, "%q", v)
+				}
+				b.WriteString("}, ")
+			}
+			b.WriteString("},\n")
+		}
+		if s.ContentType != "" {
+			fmt.Fprintf(b, "contentType: %q,\n", s.ContentType)
+		}
+		if s.Body != "" {
+			fmt.Fprintf(b, "body: %q,\n", s.Body)
+		}
+		if s.Status != 0 {
+			fmt.Fprintf(b, "status: %d,\n", s.Status)
+		}
+		if len(s.Fields) > 0 {
+			b.WriteString("fields: []contractField{\n")
+			for _, f := range s.Fields {
+				fmt.Fprintf(b, "{%q, %q, %t},\n", f.Path, f.Type, f.Required)
+			}
+			b.WriteString("},\n")
+		}
+		if s.Skip != "" {
+			fmt.Fprintf(b, "skip: %q,\n", s.Skip)
+		}
+		b.WriteString("},\n")
+	}
+	b.WriteString("}\n\n")
+	b.WriteString(goRunner)
+
+	out, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return nil, fmt.Errorf("format generated Go test: %w", err)
+	}
+	return out, nil
+}
+
+const goImports = `import (
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+`
+
+// goRunner is the static part of the generated Go test. Steps share one
+// cookie jar so session cookies carry through the chain; recorded
+// credentials are redacted, so APIDOC_AUTHORIZATION supplies a live one.
+const goRunner = `type contractField struct {
+	path     string
+	typ      string
+	required bool
+}
+
+type contractStep struct {
+	name        string
+	method      string
+	path        string
+	query       url.Values
+	contentType string
+	body        string
+	status      int
+	fields      []contractField
+	skip        string
+}
+
+func TestContract(t *testing.T) {
+	base := os.Getenv("APIDOC_BASE_URL")
+	if base == "" {
+		base = contractBaseURL
+	}
+	base = strings.TrimRight(base, "/")
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Jar: jar, Timeout: 30 * time.Second}
+
+	for _, step := range contractSteps {
+		step := step
+		t.Run(step.name, func(t *testing.T) {
+			if step.skip != "" {
+				t.Skip(step.skip)
+			}
+			target := base + step.path
+			if len(step.query) > 0 {
+				target += "?" + step.query.Encode()
+			}
+			var body io.Reader
+			if step.body != "" {
+				body = strings.NewReader(step.body)
+			}
+			req, err := http.NewRequest(step.method, target, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if step.contentType != "" {
+				req.Header.Set("Content-Type", step.contentType)
+			}
+			req.Header.Set("Accept", "application/json")
+			if auth := os.Getenv("APIDOC_AUTHORIZATION"); auth != "" {
+				req.Header.Set("Authorization", auth)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			data, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if step.status != 0 && resp.StatusCode != step.status {
+				t.Fatalf("status = %d, want %d; body: %.200s", resp.StatusCode, step.status, data)
+			}
+			if len(step.fields) == 0 {
+				return
+			}
+			var doc any
+			if err := json.Unmarshal(data, &doc); err != nil {
+				t.Fatalf("response is not JSON: %v", err)
+			}
+			for _, f := range step.fields {
+				checkContractField(t, doc, f)
+			}
+		})
+	}
+}
+
+// checkContractField asserts a field's type wherever it is present, and its
+// presence when required in every enclosing object that exists.
+func checkContractField(t *testing.T, doc any, f contractField) {
+	t.Helper()
+	segs := strings.Split(f.path, ".")
+	values := []any{doc}
+	for i, seg := range segs {
+		name, isArray := strings.CutSuffix(seg, "[]")
+		var next []any
+		for _, v := range values {
+			obj, ok := v.(map[string]any)
+			if !ok {
+				continue
+			}
+			child, ok := obj[name]
+			if !ok {
+				if i == len(segs)-1 && f.required {
+					t.Errorf("missing required field %s", f.path)
+					return
+				}
+				continue
+			}
+			if items, ok := child.([]any); ok && isArray {
+				next = append(next, items...)
+				continue
+			}
+			next = append(next, child)
+		}
+		values = next
+	}
+	for _, v := range values {
+		if v != nil && !contractTypeMatches(v, f.typ) {
+			t.Errorf("field %s: got %T, want %s", f.path, v, f.typ)
+		}
+	}
+}
+
+func contractTypeMatches(v any, typ string) bool {
+	switch typ {
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "integer":
+		n, ok := v.(float64)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	}
+	return true
+}
+`
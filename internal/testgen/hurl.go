@@ -0,0 +1,68 @@
+package testgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// hurlPredicates maps field types to Hurl type predicates.
+var hurlPredicates = map[string]string{
+	"string":  "isString",
+	"integer": "isInteger",
+	"number":  "isNumber",
+	"boolean": "isBoolean",
+	"array":   "isCollection",
+	"object":  "isCollection",
+}
+
+// renderHurl writes one entry per step; run with
+// hurl --test --variable base_url=https://... file.hurl.
+func renderHurl(steps []Step) []byte {
+	b := &strings.Builder{}
+	fmt.Fprintln(b, "# Code generated by apidoc testgen; DO NOT EDIT.")
+	for _, s := range steps {
+		fmt.Fprintf(b, "\n# %s\n", s.Name)
+		if s.Skip != "" {
+			fmt.Fprintf(b, "# skipped: %s\n", s.Skip)
+			continue
+		}
+		fmt.Fprintf(b, "%s {{base_url}}%s\n", s.Method, s.Path)
+		b.WriteString("Accept: application/json\n")
+		if s.ContentType != "" {
+			fmt.Fprintf(b, "Content-Type: %s\n", s.ContentType)
+		}
+		if len(s.Query) > 0 {
+			keys := make([]string, 0, len(s.Query))
+			for k := range s.Query {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			b.WriteString("[QueryStringParams]\n")
+			for _, k := range keys {
+				for _, v := range s.Query[k] {
+					fmt.Fprintf(b, "%s: %s\n", k, v)
+				}
+			}
+		}
+		if s.Body != "" {
+			fmt.Fprintf(b, "```\n%s\n```\n", s.Body)
+		}
+		fmt.Fprintf(b, "\nHTTP %d\n", s.Status)
+		// Only required top-level paths are asserted: a Hurl predicate on a
+		// "[*]" query would fail for empty arrays and absent optional parents.
+		var asserts []string
+		for _, f := range s.Fields {
+			if f.Required && !strings.Contains(f.Path, "[]") {
+				asserts = append(asserts, fmt.Sprintf("jsonpath %q %s", "$."+f.Path, hurlPredicates[f.Type]))
+			}
+		}
+		if len(asserts) > 0 {
+			b.WriteString("[Asserts]\n")
+			for _, a := range asserts {
+				b.WriteString(a + "\n")
+			}
+		}
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,96 @@
+package testgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+func renderK6(scenario string, steps []Step, opts Options) ([]byte, error) {
+	data, err := json.MarshalIndent(steps, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	base, err := json.Marshal(opts.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	b := &strings.Builder{}
+	fmt.Fprintln(b, "// Code generated by apidoc testgen; DO NOT EDIT.")
+	if scenario != "" {
+		fmt.Fprintf(b, "// Contract tests for: %s\n", strings.ReplaceAll(scenario, "\n", " "))
+	}
+	fmt.Fprintln(b, "// Run: k6 run -e APIDOC_BASE_URL=https://staging.example.com <file>")
+	b.WriteString("import http from 'k6/http';\nimport { check } from 'k6';\n\n")
+	fmt.Fprintf(b, "const BASE_URL = (__ENV.APIDOC_BASE_URL || %s).replace(/\\/+$/, '');\n", base)
+	fmt.Fprintf(b, "const steps = %s;\n", data)
+	b.WriteString(k6Runner)
+	return []byte(b.String()), nil
+}
+
+const k6Runner = `
+function lookup(doc, path) {
+  let values = [doc];
+  let missing = false;
+  const segs = path.split('.');
+  segs.forEach((seg, i) => {
+    const isArray = seg.endsWith('[]');
+    const name = isArray ? seg.slice(0, -2) : seg;
+    const next = [];
+    for (const v of values) {
+      if (v === null || typeof v !== 'object' || Array.isArray(v)) continue;
+      if (!(name in v)) {
+        if (i === segs.length - 1) missing = true;
+        continue;
+      }
+      if (isArray && Array.isArray(v[name])) next.push(...v[name]);
+      else next.push(v[name]);
+    }
+    values = next;
+  });
+  return { values, missing };
+}
+
+function typeMatches(v, type) {
+  switch (type) {
+    case 'string': return typeof v === 'string';
+    case 'integer': return Number.isInteger(v);
+    case 'number': return typeof v === 'number';
+    case 'boolean': return typeof v === 'boolean';
+    case 'array': return Array.isArray(v);
+    case 'object': return v !== null && typeof v === 'object' && !Array.isArray(v);
+  }
+  return true;
+}
+
+function query(q) {
+  const parts = [];
+  for (const [k, vs] of Object.entries(q || {})) {
+    for (const v of vs) parts.push(encodeURIComponent(k) + '=' + encodeURIComponent(v));
+  }
+  return parts.length ? '?' + parts.join('&') : '';
+}
+
+export default function () {
+  for (const step of steps) {
+    if (step.skip) continue;
+    const headers = { Accept: 'application/json' };
+    if (step.content_type) headers['Content-Type'] = step.content_type;
+    if (__ENV.APIDOC_AUTHORIZATION) headers.Authorization = __ENV.APIDOC_AUTHORIZATION;
+    const res = http.request(step.method, BASE_URL + step.path + query(step.query), step.body || null, { headers, tags: { name: step.name } });
+    const checks = {};
+    checks[step.name + ': status ' + step.status] = (r) => r.status === step.status;
+    let doc = null;
+    try { doc = res.json(); } catch (e) { doc = undefined; }
+    for (const f of step.fields || []) {
+      checks[step.name + ': ' + f.path] = () => {
+        if (doc === undefined) return false;
+        const { values, missing } = lookup(doc, f.path);
+        if (missing && f.required) return false;
+        return values.every((v) => v === null || typeMatches(v, f.type));
+      };
+    }
+    check(res, checks);
+  }
+}
+`
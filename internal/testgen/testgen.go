@@ -0,0 +1,259 @@
+// Package testgen turns a documented session into runnable contract tests
+// that replay each call-chain step and assert the documented status codes
+// and response fields.
+package testgen
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yourorg/apidoc/internal/filter"
+	"github.com/yourorg/apidoc/internal/generator"
+	"github.com/yourorg/apidoc/internal/pathmatch"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// Supported output languages.
+const (
+	LangGo   = "go"
+	LangK6   = "k6"
+	LangHurl = "hurl"
+)
+
+// Options configures test generation.
+type Options struct {
+	// Langs lists the outputs to produce; defaults to LangGo.
+	Langs []string
+	// Package is the Go package name of generated tests; defaults to "contract".
+	Package string
+	// BaseURL is the default target, overridable at run time through
+	// APIDOC_BASE_URL (Go, k6) or --variable base_url (Hurl).
+	BaseURL string
+	// Filter and Sanitize are applied to logs before any request is copied
+	// into a test, as generate does, so recorded credentials never reach
+	// the generated files.
+	Filter   filter.FilterConfig
+	Sanitize filter.SanitizeConfig
+}
+
+// File is one generated test file.
+type File struct {
+	Name    string
+	Content []byte
+}
+
+// Step is one request replayed by the generated tests.
+type Step struct {
+	Name        string              `json:"name"`
+	Method      string              `json:"method"`
+	Path        string              `json:"path"`
+	Query       map[string][]string `json:"query,omitempty"`
+	ContentType string              `json:"content_type,omitempty"`
+	Body        string              `json:"body,omitempty"`
+	Status      int                 `json:"status"`
+	Fields      []Field             `json:"fields,omitempty"`
+	// Skip explains why a documented step has no recorded request to replay.
+	Skip string `json:"skip,omitempty"`
+}
+
+// Field is one asserted response field. Path uses dots for nesting and "[]"
+// for array elements, e.g. "items[].sku".
+type Field struct {
+	Path     string `json:"path"`
+	Type     string `json:"type"`
+	Required bool   `json:"required,omitempty"`
+}
+
+// Generate renders contract tests for doc, replaying requests from logs.
+func Generate(doc *types.GeneratedDoc, logs []types.TrafficLog, opts Options) ([]File, error) {
+	if doc == nil || len(doc.Endpoints) == 0 {
+		return nil, errors.New("no generated doc: run generate first")
+	}
+	langs := opts.Langs
+	if len(langs) == 0 {
+		langs = []string{LangGo}
+	}
+	if opts.Package == "" {
+		opts.Package = "contract"
+	}
+	logs = filter.Sanitize(filter.Apply(logs, opts.Filter), opts.Sanitize)
+	steps := BuildSteps(doc, logs)
+	if len(steps) == 0 {
+		return nil, errors.New("no recorded requests match the documented endpoints")
+	}
+	base := slug(doc.Scenario)
+
+	var files []File
+	for _, lang := range langs {
+		var (
+			f   File
+			err error
+		)
+		switch strings.ToLower(strings.TrimSpace(lang)) {
+		case LangGo:
+			f.Name = base + "_test.go"
+			f.Content, err = renderGo(doc.Scenario, steps, opts)
+		case LangK6:
+			f.Name = base + ".k6.js"
+			f.Content, err = renderK6(doc.Scenario, steps, opts)
+		case LangHurl:
+			f.Name = base + ".hurl"
+			f.Content = renderHurl(steps)
+		default:
+			return nil, fmt.Errorf("unsupported language %q (want %s, %s or %s)", lang, LangGo, LangK6, LangHurl)
+		}
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// BuildSteps orders requests by the call chain, falling back to recording
+// order for documented endpoints the chain does not mention.
+func BuildSteps(doc *types.GeneratedDoc, logs []types.TrafficLog) []Step {
+	sorted := append([]types.TrafficLog(nil), logs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Seq < sorted[j].Seq })
+
+	var steps []Step
+	covered := map[string]bool{}
+	last := 0
+	for _, cs := range doc.CallChain {
+		key := strings.ToUpper(cs.Method) + " " + cs.Path
+		covered[key] = true
+		ep, _ := endpointFor(doc, cs.Method, cs.Path)
+		name := fmt.Sprintf("%02d %s %s", cs.Seq, strings.ToUpper(cs.Method), cs.Path)
+		l, ok := findLog(sorted, cs.Method, cs.Path, last)
+		if !ok {
+			steps = append(steps, Step{Name: name, Method: strings.ToUpper(cs.Method), Path: cs.Path, Skip: "no recorded request"})
+			continue
+		}
+		last = l.Seq
+		steps = append(steps, stepFrom(name, l, ep))
+	}
+
+	type pending struct {
+		seq int
+		ep  types.Endpoint
+		log types.TrafficLog
+	}
+	var rest []pending
+	for _, ep := range doc.Endpoints {
+		if covered[strings.ToUpper(ep.Method)+" "+ep.Path] {
+			continue
+		}
+		if l, ok := findLog(sorted, ep.Method, ep.Path, 0); ok {
+			rest = append(rest, pending{seq: l.Seq, ep: ep, log: l})
+		}
+	}
+	sort.SliceStable(rest, func(i, j int) bool { return rest[i].seq < rest[j].seq })
+	for _, p := range rest {
+		name := fmt.Sprintf("%s %s", strings.ToUpper(p.ep.Method), p.ep.Path)
+		steps = append(steps, stepFrom(name, p.log, p.ep))
+	}
+	return steps
+}
+
+// endpointFor finds the documented endpoint whose path fits a call-chain
+// step most specifically.
+func endpointFor(doc *types.GeneratedDoc, method, path string) (types.Endpoint, bool) {
+	var templates []string
+	var candidates []types.Endpoint
+	for _, ep := range doc.Endpoints {
+		if strings.EqualFold(ep.Method, method) {
+			templates = append(templates, ep.Path)
+			candidates = append(candidates, ep)
+		}
+	}
+	if i := pathmatch.Best(templates, path); i >= 0 {
+		return candidates[i], true
+	}
+	return types.Endpoint{}, false
+}
+
+// findLog returns the first log after seq matching method and template,
+// wrapping around to earlier logs when none follows.
+func findLog(logs []types.TrafficLog, method, template string, after int) (types.TrafficLog, bool) {
+	var first *types.TrafficLog
+	for i, l := range logs {
+		if !strings.EqualFold(l.Method, method) {
+			continue
+		}
+		if _, ok := pathmatch.Match(template, l.Path); !ok {
+			continue
+		}
+		if l.Seq > after {
+			return l, true
+		}
+		if first == nil {
+			first = &logs[i]
+		}
+	}
+	if first != nil {
+		return *first, true
+	}
+	return types.TrafficLog{}, false
+}
+
+func stepFrom(name string, l types.TrafficLog, ep types.Endpoint) Step {
+	s := Step{
+		Name:        name,
+		Method:      strings.ToUpper(l.Method),
+		Path:        l.Path,
+		Query:       l.QueryParams,
+		ContentType: l.ContentType,
+		Status:      l.StatusCode,
+	}
+	if l.RequestBodyEncoding != "omitted" && l.RequestBodyEncoding != "base64" {
+		s.Body = l.RequestBody
+	}
+	if s.Body == "" {
+		s.ContentType = ""
+	}
+	for _, resp := range ep.Responses {
+		if resp.StatusCode == l.StatusCode {
+			s.Fields = flattenFields("", resp.Fields)
+			break
+		}
+	}
+	return s
+}
+
+func flattenFields(prefix string, params []types.Param) []Field {
+	var out []Field
+	for _, p := range params {
+		typ, _ := generator.FieldType(p.Type)
+		if len(p.Children) > 0 && typ != "array" {
+			typ = "object"
+		}
+		path := prefix + p.Name
+		out = append(out, Field{Path: path, Type: typ, Required: p.Required})
+		if len(p.Children) > 0 {
+			child := path + "."
+			if typ == "array" {
+				child = path + "[]."
+			}
+			out = append(out, flattenFields(child, p.Children)...)
+		}
+	}
+	return out
+}
+
+var nonWord = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slug derives a file name from the scenario; non-ASCII scenarios fall back
+// to "contract".
+func slug(s string) string {
+	s = strings.Trim(nonWord.ReplaceAllString(strings.ToLower(s), "_"), "_")
+	if s == "" {
+		return "contract"
+	}
+	if len(s) > 48 {
+		s = strings.TrimRight(s[:48], "_")
+	}
+	return s
+}
@@ -0,0 +1,195 @@
+package testgen
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/apidoc/internal/config"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+func intPtr(n int) *int { return &n }
+
+func fixture() (*types.GeneratedDoc, []types.TrafficLog) {
+	doc := &types.GeneratedDoc{
+		Scenario: "Checkout flow",
+		CallChain: []types.ChainStep{
+			{Seq: 1, Method: "POST", Path: "/api/login"},
+			{Seq: 2, Method: "GET", Path: "/api/orders/{id}", DependsOn: intPtr(1)},
+			{Seq: 3, Method: "DELETE", Path: "/api/orders/{id}"},
+		},
+		Endpoints: []types.Endpoint{
+			{Method: "POST", Path: "/api/login", Responses: []types.Response{{StatusCode: 200, Fields: []types.Param{
+				{Name: "user", Type: "object", Required: true, Children: []types.Param{{Name: "id", Type: "integer", Required: true}}},
+			}}}},
+			{Method: "GET", Path: "/api/orders/{id}", Responses: []types.Response{{StatusCode: 200, Fields: []types.Param{
+				{Name: "id", Type: "integer", Required: true},
+				{Name: "note", Type: "string"},
+				{Name: "items", Type: "array", Required: true, Children: []types.Param{{Name: "sku", Type: "string", Required: true}}},
+			}}}},
+			{Method: "GET", Path: "/api/health", Responses: []types.Response{{StatusCode: 204}}},
+		},
+	}
+	logs := []types.TrafficLog{
+		{Seq: 1, Method: "GET", Path: "/api/health", StatusCode: 204},
+		{Seq: 2, Method: "POST", Path: "/api/login", ContentType: "application/json", RequestBody: `{"user":"alice"}`, StatusCode: 200},
+		{Seq: 3, Method: "GET", Path: "/api/orders/42", QueryParams: map[string][]string{"expand": {"items"}}, StatusCode: 200},
+	}
+	return doc, logs
+}
+
+func TestBuildSteps(t *testing.T) {
+	doc, logs := fixture()
+	steps := BuildSteps(doc, logs)
+	var names []string
+	for _, s := range steps {
+		names = append(names, s.Name)
+	}
+	want := []string{"01 POST /api/login", "02 GET /api/orders/{id}", "03 DELETE /api/orders/{id}", "GET /api/health"}
+	if strings.Join(names, "|") != strings.Join(want, "|") {
+		t.Fatalf("steps = %v, want %v", names, want)
+	}
+	if steps[1].Path != "/api/orders/42" || steps[1].Query["expand"][0] != "items" {
+		t.Fatalf("recorded request not used: %+v", steps[1])
+	}
+	if steps[2].Skip == "" {
+		t.Fatal("chain step without a recording should be skipped")
+	}
+	var paths []string
+	for _, f := range steps[1].Fields {
+		paths = append(paths, f.Path+":"+f.Type)
+	}
+	if got := strings.Join(paths, ","); got != "id:integer,note:string,items:array,items[].sku:string" {
+		t.Fatalf("fields = %s", got)
+	}
+}
+
+func TestBuildStepsMatchesChainPathTemplates(t *testing.T) {
+	doc, logs := fixture()
+	doc.CallChain[1].Path = "/api/orders/:id"
+	steps := BuildSteps(doc, logs)
+	if len(steps[1].Fields) == 0 || steps[1].Path != "/api/orders/42" {
+		t.Fatalf("chain step should find the documented endpoint: %+v", steps[1])
+	}
+}
+
+func TestGenerateRejectsUnknownLang(t *testing.T) {
+	doc, logs := fixture()
+	if _, err := Generate(doc, logs, Options{Langs: []string{"ruby"}}); err == nil {
+		t.Fatal("expected error for unsupported language")
+	}
+	if _, err := Generate(&types.GeneratedDoc{}, logs, Options{}); err == nil {
+		t.Fatal("expected error without endpoints")
+	}
+}
+
+func TestGenerateK6AndHurl(t *testing.T) {
+	doc, logs := fixture()
+	files, err := Generate(doc, logs, Options{Langs: []string{"k6", "hurl"}, BaseURL: "https://shop.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files[0].Name != "checkout_flow.k6.js" || files[1].Name != "checkout_flow.hurl" {
+		t.Fatalf("names = %s, %s", files[0].Name, files[1].Name)
+	}
+	k6 := string(files[0].Content)
+	if !strings.Contains(k6, `__ENV.APIDOC_BASE_URL || "https://shop.example.com"`) || !strings.Contains(k6, `"path": "items[].sku"`) {
+		t.Fatalf("k6 script:\n%s", k6)
+	}
+	hurl := string(files[1].Content)
+	for _, want := range []string{
+		"POST {{base_url}}/api/login\n",
+		"[QueryStringParams]\nexpand: items\n",
+		`jsonpath "$.items" isCollection`,
+		"# skipped: no recorded request",
+		"HTTP 204",
+	} {
+		if !strings.Contains(hurl, want) {
+			t.Fatalf("hurl missing %q:\n%s", want, hurl)
+		}
+	}
+	if strings.Contains(hurl, "$.note") || strings.Contains(hurl, "sku") {
+		t.Fatalf("hurl should only assert required top-level fields:\n%s", hurl)
+	}
+}
+
+func TestGenerateRedactsRecordedCredentials(t *testing.T) {
+	doc, logs := fixture()
+	logs[1].RequestBody = `{"user":"alice","password":"hunter2"}`
+	logs[2].QueryParams["api_key"] = []string{"k-123456"}
+	cfg := config.Config{}
+	cfg.SetDefaults()
+	files, err := Generate(doc, logs, Options{Langs: []string{"go", "hurl"}, Filter: cfg.Filter, Sanitize: cfg.Sanitize})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		out := string(f.Content)
+		if strings.Contains(out, "hunter2") || strings.Contains(out, "k-123456") {
+			t.Fatalf("%s leaks recorded credentials:\n%s", f.Name, out)
+		}
+		if !strings.Contains(out, cfg.Sanitize.Replacement) {
+			t.Fatalf("%s should carry the redaction marker:\n%s", f.Name, out)
+		}
+	}
+}
+
+// TestGeneratedGoTestPasses runs the generated Go test against a server
+// that behaves like the recording.
+func TestGeneratedGoTestPasses(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go tool")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/login":
+			http.SetCookie(w, &http.Cookie{Name: "sid", Value: "s1", Path: "/"})
+			_ = json.NewEncoder(w).Encode(map[string]any{"user": map[string]any{"id": 7}})
+		case "GET /api/orders/42":
+			if c, err := r.Cookie("sid"); err != nil || c.Value != "s1" || r.URL.Query().Get("expand") != "items" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": 42, "note": nil, "items": []any{map[string]any{"sku": "A-1"}}})
+		case "GET /api/health":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer api.Close()
+
+	doc, logs := fixture()
+	files, err := Generate(doc, logs, Options{BaseURL: "http://unused.invalid"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module contract\n\ngo 1.22\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, files[0].Name), files[0].Content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(goBin, "test", "-count=1", "-v", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "APIDOC_BASE_URL="+api.URL, "GOWORK=off", "GOFLAGS=")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("generated test failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "--- SKIP: TestContract/03_DELETE_/api/orders/{id}") {
+		t.Fatalf("expected skipped step in output:\n%s", out)
+	}
+}
@@ -0,0 +1,195 @@
+package verify
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// validate checks v against the JSON Schema subset generated specs use:
+// type (including 3.1 type lists and 3.0 nullable), properties, required,
+// items, enum, allOf, anyOf and oneOf. Unknown keywords are ignored.
+func (s *Spec) validate(path string, v any, schema any, out *[]string) {
+	sch, ok := s.resolve(schema).(map[string]any)
+	if !ok || len(sch) == 0 {
+		return
+	}
+	for _, sub := range list(sch["allOf"]) {
+		s.validate(path, v, sub, out)
+	}
+	for _, key := range []string{"anyOf", "oneOf"} {
+		alts := list(sch[key])
+		if len(alts) == 0 {
+			continue
+		}
+		matched := false
+		for _, alt := range alts {
+			var errs []string
+			s.validate(path, v, alt, &errs)
+			if len(errs) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			*out = append(*out, fmt.Sprintf("%s: matches none of %s", path, key))
+		}
+	}
+
+	types := schemaTypes(sch)
+	if v == nil {
+		if len(types) > 0 && !contains(types, "null") && sch["nullable"] != true {
+			*out = append(*out, fmt.Sprintf("%s: null, want %s", path, strings.Join(types, "|")))
+		}
+		return
+	}
+	if len(types) > 0 && !typeMatches(v, types) {
+		*out = append(*out, fmt.Sprintf("%s: %s, want %s", path, jsonType(v), strings.Join(types, "|")))
+		return
+	}
+	if enum := list(sch["enum"]); len(enum) > 0 && !inEnum(v, enum) {
+		*out = append(*out, fmt.Sprintf("%s: %v not in enum", path, display(v)))
+	}
+
+	switch t := v.(type) {
+	case map[string]any:
+		props, _ := sch["properties"].(map[string]any)
+		for _, r := range list(sch["required"]) {
+			name, _ := r.(string)
+			if _, ok := t[name]; !ok {
+				*out = append(*out, fmt.Sprintf("%s.%s: required field missing", path, name))
+			}
+		}
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if ps, ok := props[k]; ok {
+				s.validate(path+"."+k, t[k], ps, out)
+			}
+		}
+	case []any:
+		if items, ok := sch["items"]; ok {
+			for i, item := range t {
+				before := len(*out)
+				s.validate(fmt.Sprintf("%s[%d]", path, i), item, items, out)
+				// One bad element usually means all are bad; report the first.
+				if len(*out) > before {
+					break
+				}
+			}
+		}
+	}
+}
+
+// validateParam checks a raw parameter string against a scalar schema.
+func (s *Spec) validateParam(label, raw string, schema map[string]any) string {
+	sch, _ := s.resolve(schema).(map[string]any)
+	types := schemaTypes(sch)
+	if len(types) == 0 {
+		return ""
+	}
+	for _, t := range types {
+		switch t {
+		case "string", "array", "object":
+			return ""
+		case "integer":
+			if _, err := strconv.ParseInt(raw, 10, 64); err == nil {
+				return ""
+			}
+		case "number":
+			if _, err := strconv.ParseFloat(raw, 64); err == nil {
+				return ""
+			}
+		case "boolean":
+			if raw == "true" || raw == "false" {
+				return ""
+			}
+		}
+	}
+	return fmt.Sprintf("%s: %q is not %s", label, raw, strings.Join(types, "|"))
+}
+
+func schemaTypes(sch map[string]any) []string {
+	switch t := sch["type"].(type) {
+	case string:
+		return []string{t}
+	case []any:
+		out := make([]string, 0, len(t))
+		for _, x := range t {
+			if s, ok := x.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	if _, ok := sch["properties"]; ok {
+		return []string{"object"}
+	}
+	return nil
+}
+
+func typeMatches(v any, types []string) bool {
+	actual := jsonType(v)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonType(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := t.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func inEnum(v any, enum []any) bool {
+	for _, e := range enum {
+		if fmt.Sprint(display(e)) == fmt.Sprint(display(v)) {
+			return true
+		}
+	}
+	return false
+}
+
+func display(v any) any {
+	if n, ok := v.(json.Number); ok {
+		return n.String()
+	}
+	return v
+}
+
+func list(v any) []any {
+	l, _ := v.([]any)
+	return l
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,191 @@
+package verify
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is the subset of an OpenAPI 3.x document that conformance checks use.
+type Spec struct {
+	root       map[string]any
+	operations []operation
+}
+
+type operation struct {
+	method      string
+	path        string
+	params      []parameter
+	bodyNeeded  bool
+	bodySchemas map[string]any // by media type
+	responses   map[string]map[string]any
+}
+
+type parameter struct {
+	name     string
+	in       string
+	required bool
+	schema   map[string]any
+}
+
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// LoadSpec reads an OpenAPI document in YAML or JSON.
+func LoadSpec(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSpec(data)
+}
+
+// ParseSpec parses an OpenAPI document in YAML or JSON.
+func ParseSpec(data []byte) (*Spec, error) {
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse spec: %w", err)
+	}
+	root, ok := normalize(raw).(map[string]any)
+	if !ok {
+		return nil, errors.New("spec is not an object")
+	}
+	if _, ok := root["openapi"]; !ok {
+		return nil, errors.New("not an OpenAPI 3 document: missing openapi field")
+	}
+	s := &Spec{root: root}
+	paths, _ := root["paths"].(map[string]any)
+	if len(paths) == 0 {
+		return nil, errors.New("spec has no paths")
+	}
+	keys := make([]string, 0, len(paths))
+	for k := range paths {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, p := range keys {
+		item, _ := s.resolve(paths[p]).(map[string]any)
+		shared := s.parameters(item["parameters"])
+		for _, m := range methods {
+			op, ok := s.resolve(item[m]).(map[string]any)
+			if !ok {
+				continue
+			}
+			s.operations = append(s.operations, s.operation(strings.ToUpper(m), p, op, shared))
+		}
+	}
+	return s, nil
+}
+
+func (s *Spec) operation(method, path string, op map[string]any, shared []parameter) operation {
+	o := operation{method: method, path: path, responses: map[string]map[string]any{}}
+	// Operation parameters override path-level ones with the same name and location.
+	own := s.parameters(op["parameters"])
+	seen := map[string]bool{}
+	for _, p := range own {
+		seen[p.in+":"+p.name] = true
+	}
+	o.params = own
+	for _, p := range shared {
+		if !seen[p.in+":"+p.name] {
+			o.params = append(o.params, p)
+		}
+	}
+	if rb, ok := s.resolve(op["requestBody"]).(map[string]any); ok {
+		o.bodyNeeded, _ = rb["required"].(bool)
+		o.bodySchemas = s.contentSchemas(rb["content"])
+	}
+	if rs, ok := op["responses"].(map[string]any); ok {
+		for code, r := range rs {
+			resp, _ := s.resolve(r).(map[string]any)
+			o.responses[strings.ToUpper(code)] = s.contentSchemas(resp["content"])
+		}
+	}
+	return o
+}
+
+func (s *Spec) parameters(v any) []parameter {
+	list, _ := v.([]any)
+	var out []parameter
+	for _, item := range list {
+		p, ok := s.resolve(item).(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := p["name"].(string)
+		in, _ := p["in"].(string)
+		required, _ := p["required"].(bool)
+		schema, _ := s.resolve(p["schema"]).(map[string]any)
+		out = append(out, parameter{name: name, in: in, required: required || in == "path", schema: schema})
+	}
+	return out
+}
+
+func (s *Spec) contentSchemas(v any) map[string]any {
+	content, _ := v.(map[string]any)
+	out := make(map[string]any, len(content))
+	for mediaType, m := range content {
+		media, _ := m.(map[string]any)
+		out[strings.ToLower(mediaType)] = media["schema"]
+	}
+	return out
+}
+
+// resolve follows a local "$ref" such as "#/components/schemas/User".
+func (s *Spec) resolve(v any) any {
+	for i := 0; i < 32; i++ {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return v
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return v
+		}
+		v = s.lookup(ref)
+	}
+	return nil
+}
+
+func (s *Spec) lookup(ref string) any {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil
+	}
+	var cur any = s.root
+	for _, part := range strings.Split(ref[2:], "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil
+		}
+		cur = m[part]
+	}
+	return cur
+}
+
+// normalize converts YAML's map[any]any (e.g. unquoted status code keys)
+// into map[string]any throughout.
+func normalize(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, child := range t {
+			t[k] = normalize(child)
+		}
+		return t
+	case map[any]any:
+		out := make(map[string]any, len(t))
+		for k, child := range t {
+			out[fmt.Sprint(k)] = normalize(child)
+		}
+		return out
+	case []any:
+		for i, child := range t {
+			t[i] = normalize(child)
+		}
+		return t
+	}
+	return v
+}
@@ -0,0 +1,220 @@
+// Package verify checks captured traffic against an OpenAPI spec: path
+// templates, required parameters, declared status codes and the JSON
+// schemas of request and response bodies.
+package verify
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yourorg/apidoc/internal/pathmatch"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// Violation is one way a request or response departs from the spec.
+type Violation struct {
+	Seq     int    `json:"seq"`
+	Message string `json:"message"`
+}
+
+// EndpointReport groups the checked requests of one operation. Requests
+// matching no operation are grouped under their concrete method and path
+// with Undocumented set.
+type EndpointReport struct {
+	Method       string      `json:"method"`
+	Path         string      `json:"path"`
+	Requests     int         `json:"requests"`
+	Undocumented bool        `json:"undocumented,omitempty"`
+	Violations   []Violation `json:"violations,omitempty"`
+}
+
+// Report is the outcome of a conformance check.
+type Report struct {
+	Checked   int              `json:"checked"`
+	Endpoints []EndpointReport `json:"endpoints"`
+}
+
+// Passed reports whether no request violated the spec.
+func (r *Report) Passed() bool {
+	for _, ep := range r.Endpoints {
+		if ep.Undocumented || len(ep.Violations) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Violations counts violations across endpoints, each undocumented endpoint
+// counting once.
+func (r *Report) Violations() int {
+	n := 0
+	for _, ep := range r.Endpoints {
+		n += len(ep.Violations)
+		if ep.Undocumented {
+			n++
+		}
+	}
+	return n
+}
+
+// Check validates every log against spec.
+func Check(spec *Spec, logs []types.TrafficLog) *Report {
+	byKey := map[string]*EndpointReport{}
+	var order []string
+	report := &Report{Checked: len(logs)}
+	for _, l := range logs {
+		op, ok := spec.match(l.Method, l.Path)
+		method, path := strings.ToUpper(l.Method), l.Path
+		if ok {
+			path = op.path
+		}
+		key := method + " " + path
+		ep, seen := byKey[key]
+		if !seen {
+			ep = &EndpointReport{Method: method, Path: path, Undocumented: !ok}
+			byKey[key] = ep
+			order = append(order, key)
+		}
+		ep.Requests++
+		if !ok {
+			continue
+		}
+		for _, msg := range spec.checkLog(op, l) {
+			ep.Violations = append(ep.Violations, Violation{Seq: l.Seq, Message: msg})
+		}
+	}
+	sort.Strings(order)
+	for _, key := range order {
+		report.Endpoints = append(report.Endpoints, *byKey[key])
+	}
+	return report
+}
+
+// match finds the operation whose path fits most specifically.
+func (s *Spec) match(method, path string) (operation, bool) {
+	var templates []string
+	var candidates []operation
+	for _, op := range s.operations {
+		if op.method == strings.ToUpper(method) {
+			templates = append(templates, op.path)
+			candidates = append(candidates, op)
+		}
+	}
+	if i := pathmatch.Best(templates, path); i >= 0 {
+		return candidates[i], true
+	}
+	return operation{}, false
+}
+
+func (s *Spec) checkLog(op operation, l types.TrafficLog) []string {
+	var out []string
+	pathValues, _ := pathmatch.Match(op.path, l.Path)
+	for _, p := range op.params {
+		var raw string
+		var present bool
+		switch p.in {
+		case "path":
+			raw, present = pathValues[p.name]
+		case "query":
+			if vs, ok := l.QueryParams[p.name]; ok && len(vs) > 0 {
+				raw, present = vs[0], true
+			}
+		case "header":
+			if vs := l.RequestHeaders.Values(p.name); len(vs) > 0 {
+				raw, present = vs[0], true
+			}
+		case "cookie":
+			for _, c := range l.RequestCookies {
+				if c.Name == p.name {
+					raw, present = c.Value, true
+				}
+			}
+		default:
+			continue
+		}
+		label := fmt.Sprintf("%s parameter %q", p.in, p.name)
+		if !present {
+			if p.required {
+				out = append(out, label+": required but missing")
+			}
+			continue
+		}
+		if msg := s.validateParam(label, raw, p.schema); msg != "" {
+			out = append(out, msg)
+		}
+	}
+
+	if l.RequestBodyEncoding != "omitted" {
+		switch {
+		case l.RequestBody == "" && len(l.FormParams) == 0:
+			if op.bodyNeeded {
+				out = append(out, "request body: required but missing")
+			}
+		case op.bodySchemas != nil:
+			out = append(out, s.checkBody("request", l.ContentType, l.RequestBody, op.bodySchemas)...)
+		}
+	}
+
+	content, ok := responseFor(op.responses, l.StatusCode)
+	if !ok {
+		return append(out, fmt.Sprintf("status %d: not declared", l.StatusCode))
+	}
+	if l.ResponseBody != "" && len(content) > 0 {
+		out = append(out, s.checkBody("response", l.ResponseContentType, l.ResponseBody, content)...)
+	}
+	return out
+}
+
+// responseFor finds the declared response for a status: the exact code,
+// then a range such as "2XX", then "default".
+func responseFor(responses map[string]map[string]any, status int) (map[string]any, bool) {
+	code := strconv.Itoa(status)
+	if r, ok := responses[code]; ok {
+		return r, true
+	}
+	if len(code) == 3 {
+		if r, ok := responses[code[:1]+"XX"]; ok {
+			return r, true
+		}
+	}
+	r, ok := responses["DEFAULT"]
+	return r, ok
+}
+
+// checkBody validates JSON bodies against the schema for their media type.
+// JSON variants such as application/problem+json fall back to any declared
+// JSON media type.
+func (s *Spec) checkBody(label, contentType, body string, schemas map[string]any) []string {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	isJSON := mediaType == "" || strings.Contains(mediaType, "json")
+	schema, ok := schemas[mediaType]
+	if !ok && isJSON {
+		for mt, sch := range schemas {
+			if strings.Contains(mt, "json") || mt == "*/*" {
+				schema, ok = sch, true
+				break
+			}
+		}
+	}
+	if !ok {
+		if mediaType == "" {
+			return nil
+		}
+		return []string{fmt.Sprintf("%s body: content type %s not declared", label, mediaType)}
+	}
+	if !isJSON {
+		return nil
+	}
+	dec := json.NewDecoder(strings.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return []string{fmt.Sprintf("%s body: invalid JSON: %v", label, err)}
+	}
+	var out []string
+	s.validate(label+" $", v, schema, &out)
+	return out
+}
@@ -0,0 +1,150 @@
+package verify
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/apidoc/internal/config"
+	"github.com/yourorg/apidoc/internal/filter"
+	"github.com/yourorg/apidoc/internal/generator"
+	"github.com/yourorg/apidoc/internal/har"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+const handSpec = `
+openapi: 3.1.0
+info: {title: t, version: "1"}
+paths:
+  /users/{id}:
+    parameters:
+      - {name: id, in: path, required: true, schema: {type: integer}}
+    get:
+      parameters:
+        - {name: X-Tenant, in: header, required: true, schema: {type: string}}
+        - {name: verbose, in: query, schema: {type: boolean}}
+      responses:
+        2XX:
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/User"}
+        default:
+          description: error
+  /users/me:
+    get:
+      responses:
+        200: {description: ok}
+  /users:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name: {type: string}
+                role: {type: string, enum: [admin, member]}
+      responses:
+        "201": {description: created}
+components:
+  schemas:
+    User:
+      type: object
+      required: [id, tags]
+      properties:
+        id: {type: integer}
+        nickname: {type: [string, "null"]}
+        tags: {type: array, items: {type: string}}
+`
+
+func messages(r *Report) []string {
+	var out []string
+	for _, ep := range r.Endpoints {
+		if ep.Undocumented {
+			out = append(out, ep.Method+" "+ep.Path+": undocumented")
+		}
+		for _, v := range ep.Violations {
+			out = append(out, v.Message)
+		}
+	}
+	return out
+}
+
+func TestCheckHandWrittenSpec(t *testing.T) {
+	spec, err := ParseSpec([]byte(handSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tenant := types.Headers{"X-Tenant": {"acme"}}
+	logs := []types.TrafficLog{
+		{Seq: 1, Method: "GET", Path: "/users/7", RequestHeaders: tenant, StatusCode: 200, ResponseContentType: "application/json",
+			ResponseBody: `{"id":7,"nickname":null,"tags":["a"]}`},
+		{Seq: 2, Method: "GET", Path: "/users/me", StatusCode: 200, ResponseBody: `{}`},
+		{Seq: 3, Method: "GET", Path: "/users/abc", QueryParams: map[string][]string{"verbose": {"yes"}}, StatusCode: 500},
+		{Seq: 4, Method: "GET", Path: "/users/8", RequestHeaders: tenant, StatusCode: 200, ResponseContentType: "application/json",
+			ResponseBody: `{"id":"8","tags":[1]}`},
+		{Seq: 5, Method: "POST", Path: "/users", ContentType: "application/json", RequestBody: `{"role":"owner"}`, StatusCode: 400},
+		{Seq: 6, Method: "POST", Path: "/users", StatusCode: 201},
+		{Seq: 7, Method: "DELETE", Path: "/users/7", StatusCode: 204},
+	}
+	rep := Check(spec, logs)
+	got := strings.Join(messages(rep), "\n")
+	want := strings.Join([]string{
+		"DELETE /users/7: undocumented",
+		`header parameter "X-Tenant": required but missing`,
+		`query parameter "verbose": "yes" is not boolean`,
+		`path parameter "id": "abc" is not integer`,
+		"response $.id: string, want integer",
+		"response $.tags[0]: integer, want string",
+		"request $.name: required field missing",
+		`request $.role: owner not in enum`,
+		"status 400: not declared",
+		"request body: required but missing",
+	}, "\n")
+	if got != want {
+		t.Fatalf("violations:\n%s\nwant:\n%s", got, want)
+	}
+	if rep.Passed() || rep.Violations() != 10 {
+		t.Fatalf("passed=%v violations=%d", rep.Passed(), rep.Violations())
+	}
+	if rep.Endpoints[2].Path != "/users/{id}" || rep.Endpoints[2].Requests != 3 {
+		t.Fatalf("templated grouping: %+v", rep.Endpoints[2])
+	}
+}
+
+func TestCheckGeneratedSpecWithHAR(t *testing.T) {
+	doc := &types.GeneratedDoc{Scenario: "sample", Endpoints: []types.Endpoint{
+		{Method: "GET", Path: "/v1/users", QueryParams: []types.Param{{Name: "id", Type: "integer", Required: true}},
+			Responses: []types.Response{{StatusCode: 200, ContentType: "application/json", Fields: []types.Param{{Name: "ok", Type: "boolean", Required: true}}}}},
+		{Method: "POST", Path: "/v1/login",
+			Responses: []types.Response{{StatusCode: 200, ContentType: "application/json", Fields: []types.Param{{Name: "token", Type: "string", Required: true}}}}},
+	}}
+	dir := t.TempDir()
+	if err := generator.RenderOpenAPI(doc, dir); err != nil {
+		t.Fatal(err)
+	}
+	spec, err := LoadSpec(filepath.Join(dir, "openapi.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs, err := har.Parse(filepath.Join("..", "..", "testdata", "sample.har"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Config{}
+	cfg.SetDefaults()
+	rep := Check(spec, filter.Apply(logs, cfg.Filter))
+	got := strings.Join(messages(rep), "\n")
+	if got != "status 201: not declared" {
+		t.Fatalf("violations:\n%s", got)
+	}
+}
+
+func TestParseSpecRejectsNonOpenAPI(t *testing.T) {
+	if _, err := ParseSpec([]byte("swagger: '2.0'\npaths: {}\n")); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,170 @@
+// Package capture records HTTP traffic from Go clients and servers as
+// types.TrafficLog so it can be fed to apidoc without a browser or proxy.
+//
+// Wrap a client transport with Recorder.Transport, or a server handler with
+// Recorder.Middleware, and call Close when done to ship the buffered logs to
+// the configured Sink (a running `apidoc serve` or a HAR file).
+package capture
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourorg/apidoc/internal/har"
+	"github.com/yourorg/apidoc/internal/httplog"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// DefaultMaxBodyBytes caps how much of each body is kept when
+// Options.MaxBodyBytes is zero.
+const DefaultMaxBodyBytes = 1 << 20
+
+// Sink receives recorded logs.
+type Sink interface {
+	Write(logs []types.TrafficLog) error
+	Close() error
+}
+
+// Options configures a Recorder.
+type Options struct {
+	// Sink receives logs on Flush and Close. Required.
+	Sink Sink
+	// SampleRate is the fraction of exchanges recorded, in (0, 1].
+	// Zero records everything.
+	SampleRate float64
+	// MaxBodyBytes drops bodies larger than this; zero uses DefaultMaxBodyBytes.
+	MaxBodyBytes int64
+}
+
+// Recorder buffers captured exchanges until they are flushed to the sink.
+type Recorder struct {
+	sink         Sink
+	sampleRate   float64
+	maxBodyBytes int64
+
+	mu     sync.Mutex
+	logs   []types.TrafficLog
+	closed bool
+	rand   *rand.Rand
+}
+
+// New creates a Recorder.
+func New(opts Options) (*Recorder, error) {
+	if opts.Sink == nil {
+		return nil, errors.New("capture: sink is nil")
+	}
+	if opts.SampleRate < 0 || opts.SampleRate > 1 {
+		return nil, errors.New("capture: sample rate must be within [0, 1]")
+	}
+	if opts.SampleRate == 0 {
+		opts.SampleRate = 1
+	}
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	return &Recorder{
+		sink:         opts.Sink,
+		sampleRate:   opts.SampleRate,
+		maxBodyBytes: opts.MaxBodyBytes,
+		rand:         rand.New(rand.NewSource(time.Now().UnixNano())),
+	}, nil
+}
+
+// Len returns the number of buffered, not yet flushed logs.
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.logs)
+}
+
+// Flush sends buffered logs to the sink, ordered by start time.
+func (r *Recorder) Flush() error {
+	r.mu.Lock()
+	logs := r.logs
+	r.logs = nil
+	r.mu.Unlock()
+	if len(logs) == 0 {
+		return nil
+	}
+	sort.SliceStable(logs, func(i, j int) bool { return logs[i].Timestamp.Before(logs[j].Timestamp) })
+	for i := range logs {
+		logs[i].Seq = i + 1
+	}
+	return r.sink.Write(logs)
+}
+
+// Close flushes remaining logs and closes the sink. Exchanges finishing
+// after Close are dropped.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	r.mu.Unlock()
+	return errors.Join(r.Flush(), r.sink.Close())
+}
+
+func (r *Recorder) sample() bool {
+	if r.sampleRate >= 1 {
+		return true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Float64() < r.sampleRate
+}
+
+func (r *Recorder) add(l types.TrafficLog) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	r.logs = append(r.logs, l)
+}
+
+// newLog fills the request side of a log from req.
+func newLog(req *http.Request, start time.Time) types.TrafficLog {
+	l := types.TrafficLog{
+		Timestamp:      start.UTC(),
+		Method:         req.Method,
+		Host:           req.Host,
+		Path:           req.URL.Path,
+		HTTPVersion:    req.Proto,
+		RequestHeaders: types.Headers(req.Header.Clone()),
+		ContentType:    req.Header.Get("Content-Type"),
+		CallCount:      1,
+	}
+	if l.Host == "" {
+		l.Host = req.URL.Host
+	}
+	switch {
+	case req.URL.Scheme != "":
+		l.Scheme = req.URL.Scheme
+	case req.TLS != nil:
+		l.Scheme = "https"
+	default:
+		l.Scheme = "http"
+	}
+	if q := req.URL.Query(); len(q) > 0 {
+		l.QueryParams = q
+	}
+	l.RequestCookies = httplog.Cookies(req.Cookies())
+	return l
+}
+
+// capturedBody returns the text kept in buf and its encoding marker the way
+// the proxy records it: "omitted" for oversized bodies, otherwise whatever
+// har.DecodeBody makes of it.
+func capturedBody(buf *httplog.Buffer, contentType string) (string, string) {
+	body, overflow := buf.Captured()
+	if overflow {
+		return "", "omitted"
+	}
+	return har.DecodeBody(string(body), "", contentType)
+}
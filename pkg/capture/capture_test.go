@@ -0,0 +1,254 @@
+package capture
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourorg/apidoc/internal/config"
+	"github.com/yourorg/apidoc/internal/har"
+	"github.com/yourorg/apidoc/internal/server"
+	"github.com/yourorg/apidoc/internal/store"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+type memorySink struct {
+	logs   []types.TrafficLog
+	closed bool
+}
+
+func (m *memorySink) Write(logs []types.TrafficLog) error {
+	m.logs = append(m.logs, logs...)
+	return nil
+}
+
+func (m *memorySink) Close() error {
+	m.closed = true
+	return nil
+}
+
+func newUpstream(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: "abc", HttpOnly: true})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"echo":` + string(body) + `}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestTransportRecordsExchange(t *testing.T) {
+	upstream := newUpstream(t)
+	sink := &memorySink{}
+	rec, err := New(Options{Sink: sink})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: rec.Transport(nil)}
+
+	req, _ := http.NewRequest(http.MethodPost, upstream.URL+"/api/users?page=2", strings.NewReader(`{"name":"a"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != `{"echo":{"name":"a"}}` {
+		t.Fatalf("client saw body %q", body)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !sink.closed || len(sink.logs) != 1 {
+		t.Fatalf("expected 1 log and a closed sink, got %d logs closed=%v", len(sink.logs), sink.closed)
+	}
+	l := sink.logs[0]
+	if l.Seq != 1 || l.Method != http.MethodPost || l.Path != "/api/users" || l.StatusCode != http.StatusCreated {
+		t.Fatalf("unexpected log: %+v", l)
+	}
+	if l.QueryParams["page"][0] != "2" {
+		t.Fatalf("query not recorded: %v", l.QueryParams)
+	}
+	if l.RequestBody != `{"name":"a"}` || l.ResponseBody != `{"echo":{"name":"a"}}` {
+		t.Fatalf("bodies not recorded: %q / %q", l.RequestBody, l.ResponseBody)
+	}
+	if len(l.ResponseCookies) != 1 || l.ResponseCookies[0].Name != "sid" || !l.ResponseCookies[0].HTTPOnly {
+		t.Fatalf("response cookies not recorded: %+v", l.ResponseCookies)
+	}
+}
+
+func TestMiddlewareRecordsAndLimitsBodies(t *testing.T) {
+	sink := &memorySink{}
+	rec, err := New(Options{Sink: sink, MaxBodyBytes: 16})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := rec.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/big" {
+			_, _ = w.Write([]byte(`{"data":"` + strings.Repeat("x", 64) + `"}`))
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	for _, path := range []string{"/small", "/big"} {
+		resp, err := http.Post(srv.URL+path, "application/json", strings.NewReader(`{"q":1}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.logs) != 2 {
+		t.Fatalf("expected 2 logs, got %d", len(sink.logs))
+	}
+	small, big := sink.logs[0], sink.logs[1]
+	if small.Path != "/small" || small.StatusCode != http.StatusAccepted || small.ResponseBody != `{"ok":true}` || small.RequestBody != `{"q":1}` {
+		t.Fatalf("unexpected small log: %+v", small)
+	}
+	if big.StatusCode != http.StatusOK || big.ResponseBody != "" {
+		t.Fatalf("oversized body should be dropped: %+v", big)
+	}
+}
+
+func TestSampleRate(t *testing.T) {
+	if _, err := New(Options{Sink: &memorySink{}, SampleRate: 1.5}); err == nil {
+		t.Fatal("expected error for sample rate above 1")
+	}
+	if _, err := New(Options{}); err == nil {
+		t.Fatal("expected error for missing sink")
+	}
+	sink := &memorySink{}
+	rec, err := New(Options{Sink: sink, SampleRate: 0.000001})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := rec.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for i := 0; i < 20; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	if rec.Len() > 1 {
+		t.Fatalf("expected sampling to drop nearly everything, kept %d", rec.Len())
+	}
+}
+
+func TestHARSinkRoundTrip(t *testing.T) {
+	upstream := newUpstream(t)
+	path := filepath.Join(t.TempDir(), "capture.har")
+	rec, err := New(Options{Sink: NewHARSink(path)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: rec.Transport(nil)}
+	for _, p := range []string{"/api/a", "/api/b?x=1"} {
+		resp, err := client.Post(upstream.URL+p, "application/json", strings.NewReader(`{"n":1}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	logs, err := har.Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("expected 2 logs from HAR, got %d", len(logs))
+	}
+	if logs[0].Path != "/api/a" || logs[1].Path != "/api/b" || logs[1].QueryParams["x"][0] != "1" {
+		t.Fatalf("unexpected paths: %+v", logs)
+	}
+	if logs[0].RequestBody != `{"n":1}` || logs[0].ResponseBody != `{"echo":{"n":1}}` || logs[0].StatusCode != http.StatusCreated {
+		t.Fatalf("unexpected log: %+v", logs[0])
+	}
+}
+
+func TestServerSinkUploadsSession(t *testing.T) {
+	st, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "apidoc.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	cfg := &config.Config{}
+	cfg.SetDefaults()
+	srv, err := server.New(cfg, st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	apidoc := httptest.NewServer(srv.Handler())
+	defer apidoc.Close()
+
+	sink := NewServerSink(apidoc.URL, "signup")
+	rec, err := New(Options{Sink: sink})
+	if err != nil {
+		t.Fatal(err)
+	}
+	upstream := newUpstream(t)
+	client := &http.Client{Transport: rec.Transport(nil)}
+	for _, p := range []string{"/api/signup", "/api/verify"} {
+		resp, err := client.Post(upstream.URL+p, "application/json", strings.NewReader(`{"email":"a@b.c"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if err := rec.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	id := sink.SessionID()
+	if id == "" {
+		t.Fatal("expected a session id")
+	}
+	logs, err := st.GetLogs(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 || logs[0].Path != "/api/signup" || logs[1].Path != "/api/verify" || logs[1].Seq != 2 {
+		t.Fatalf("flushes should append to one session: %+v", logs)
+	}
+
+	// The recorded start time and form params survive the upload.
+	started := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := sink.Write([]types.TrafficLog{{
+		Timestamp: started, Method: "POST", Host: "example.com", Path: "/api/form", StatusCode: 200,
+		ContentType: "application/x-www-form-urlencoded", RequestBody: "name=bob", FormParams: map[string][]string{"name": {"bob"}},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	logs, err = st.GetLogs(id)
+	if err != nil || len(logs) != 3 {
+		t.Fatalf("expected 3 logs, got %d err=%v", len(logs), err)
+	}
+	form := logs[2]
+	if !form.Timestamp.Equal(started) || form.FormParams["name"][0] != "bob" {
+		t.Fatalf("timestamp or form params lost: %v %v", form.Timestamp, form.FormParams)
+	}
+	if logs[0].Timestamp.After(logs[1].Timestamp) {
+		t.Fatalf("recorded timestamps out of order: %v %v", logs[0].Timestamp, logs[1].Timestamp)
+	}
+}
@@ -0,0 +1,76 @@
+package capture
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/yourorg/apidoc/internal/httplog"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// Middleware wraps a server handler and records each request it serves.
+// Use the method value (rec.Middleware) wherever a
+// func(http.Handler) http.Handler is expected.
+func (r *Recorder) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !r.sample() {
+			next.ServeHTTP(w, req)
+			return
+		}
+		start := time.Now()
+		reqBuf := httplog.NewBuffer(r.maxBodyBytes)
+		if req.Body != nil && req.Body != http.NoBody {
+			req.Body = httplog.NewBody(req.Body, reqBuf, nil)
+		}
+		l := newLog(req, start)
+		rw := &responseRecorder{ResponseWriter: w, buf: httplog.NewBuffer(r.maxBodyBytes)}
+
+		next.ServeHTTP(rw, req)
+
+		l.StatusCode = rw.status
+		if l.StatusCode == 0 {
+			l.StatusCode = http.StatusOK
+		}
+		l.LatencyMs = time.Since(start).Milliseconds()
+		l.ResponseHeaders = types.Headers(w.Header().Clone())
+		l.ResponseContentType = w.Header().Get("Content-Type")
+		l.ResponseCookies = httplog.ResponseCookies(w.Header())
+		l.RequestBody, l.RequestBodyEncoding = capturedBody(reqBuf, l.ContentType)
+		l.ResponseBody, _ = capturedBody(rw.buf, l.ResponseContentType)
+		r.add(l)
+	})
+}
+
+// responseRecorder tees the response body while still writing through.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	buf    *httplog.Buffer
+}
+
+func (rw *responseRecorder) WriteHeader(code int) {
+	if rw.status == 0 {
+		rw.status = code
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *responseRecorder) Write(p []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	_, _ = rw.buf.Write(p)
+	return rw.ResponseWriter.Write(p)
+}
+
+// Flush keeps streaming handlers working behind the middleware.
+func (rw *responseRecorder) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (rw *responseRecorder) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,248 @@
+package capture
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// ServerSink posts logs to a running `apidoc serve`. The first Write creates
+// a session via POST /api/traffic; later writes append to it.
+type ServerSink struct {
+	BaseURL  string
+	Scenario string
+	Client   *http.Client
+
+	mu        sync.Mutex
+	sessionID string
+}
+
+// NewServerSink creates a sink for the server at baseURL, e.g.
+// "http://127.0.0.1:3000".
+func NewServerSink(baseURL, scenario string) *ServerSink {
+	return &ServerSink{BaseURL: baseURL, Scenario: scenario}
+}
+
+// AppendTo makes the sink add logs to an existing session.
+func (s *ServerSink) AppendTo(sessionID string) *ServerSink {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessionID = sessionID
+	return s
+}
+
+// SessionID returns the session written to, or "" before the first Write.
+func (s *ServerSink) SessionID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessionID
+}
+
+func (s *ServerSink) Write(logs []types.TrafficLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+	body, err := json.Marshal(map[string]any{"scenario": s.Scenario, "logs": logs})
+	if err != nil {
+		return err
+	}
+	endpoint := strings.TrimRight(s.BaseURL, "/") + "/api/traffic"
+	if s.sessionID != "" {
+		endpoint = strings.TrimRight(s.BaseURL, "/") + "/api/sessions/" + url.PathEscape(s.sessionID) + "/traffic"
+	}
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("capture: upload status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	var out struct {
+		SessionID string `json:"session_id"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	s.sessionID = out.SessionID
+	return nil
+}
+
+func (s *ServerSink) Close() error {
+	return nil
+}
+
+// HARSink collects logs and writes them as a HAR 1.2 file on Close, ready
+// for `apidoc import --har`.
+type HARSink struct {
+	Path string
+	// Scheme rebuilds request URLs for logs that do not carry one. Defaults to "http".
+	Scheme string
+
+	mu   sync.Mutex
+	logs []types.TrafficLog
+}
+
+// NewHARSink creates a sink writing to path.
+func NewHARSink(path string) *HARSink {
+	return &HARSink{Path: path}
+}
+
+func (s *HARSink) Write(logs []types.TrafficLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logs = append(s.logs, logs...)
+	return nil
+}
+
+func (s *HARSink) Close() error {
+	if s.Path == "" {
+		return errors.New("capture: HAR path is empty")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scheme := s.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	entries := make([]harEntry, 0, len(s.logs))
+	for _, l := range s.logs {
+		entries = append(entries, toHAREntry(l, scheme))
+	}
+	doc := map[string]any{
+		"log": map[string]any{
+			"version": "1.2",
+			"creator": map[string]string{"name": "apidoc capture", "version": "1.0"},
+			"entries": entries,
+		},
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.Path, data, 0o644)
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harCookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+}
+
+type harEntry struct {
+	StartedDateTime string `json:"startedDateTime"`
+	Time            int64  `json:"time"`
+	Request         struct {
+		Method      string         `json:"method"`
+		URL         string         `json:"url"`
+		HTTPVersion string         `json:"httpVersion"`
+		Headers     []harNameValue `json:"headers"`
+		QueryString []harNameValue `json:"queryString"`
+		Cookies     []harCookie    `json:"cookies"`
+		PostData    *struct {
+			MimeType string `json:"mimeType"`
+			Text     string `json:"text"`
+		} `json:"postData,omitempty"`
+		HeadersSize int `json:"headersSize"`
+		BodySize    int `json:"bodySize"`
+	} `json:"request"`
+	Response struct {
+		Status      int            `json:"status"`
+		StatusText  string         `json:"statusText"`
+		HTTPVersion string         `json:"httpVersion"`
+		Headers     []harNameValue `json:"headers"`
+		Cookies     []harCookie    `json:"cookies"`
+		Content     struct {
+			Size     int    `json:"size"`
+			MimeType string `json:"mimeType"`
+			Text     string `json:"text"`
+		} `json:"content"`
+		RedirectURL string `json:"redirectURL"`
+		HeadersSize int    `json:"headersSize"`
+		BodySize    int    `json:"bodySize"`
+	} `json:"response"`
+	Cache   struct{}       `json:"cache"`
+	Timings *types.Timings `json:"timings"`
+}
+
+func toHAREntry(l types.TrafficLog, scheme string) harEntry {
+	var e harEntry
+	e.StartedDateTime = l.Timestamp.UTC().Format(time.RFC3339Nano)
+	e.Time = l.LatencyMs
+
+	if l.Scheme != "" {
+		scheme = l.Scheme
+	}
+	u := url.URL{Scheme: scheme, Host: l.Host, Path: l.Path, RawQuery: url.Values(l.QueryParams).Encode()}
+	e.Request.Method = l.Method
+	e.Request.URL = u.String()
+	e.Request.HTTPVersion = l.HTTPVersion
+	e.Request.Headers = harHeaders(l.RequestHeaders)
+	e.Request.QueryString = harHeaders(types.Headers(l.QueryParams))
+	e.Request.Cookies = harCookies(l.RequestCookies)
+	if l.RequestBody != "" {
+		e.Request.PostData = &struct {
+			MimeType string `json:"mimeType"`
+			Text     string `json:"text"`
+		}{MimeType: l.ContentType, Text: l.RequestBody}
+	}
+	e.Request.HeadersSize = -1
+	e.Request.BodySize = len(l.RequestBody)
+
+	e.Response.Status = l.StatusCode
+	e.Response.StatusText = http.StatusText(l.StatusCode)
+	e.Response.HTTPVersion = l.HTTPVersion
+	e.Response.Headers = harHeaders(l.ResponseHeaders)
+	e.Response.Cookies = harCookies(l.ResponseCookies)
+	e.Response.Content.Size = len(l.ResponseBody)
+	e.Response.Content.MimeType = l.ResponseContentType
+	e.Response.Content.Text = l.ResponseBody
+	e.Response.HeadersSize = -1
+	e.Response.BodySize = len(l.ResponseBody)
+
+	e.Timings = l.Timings
+	if e.Timings == nil {
+		e.Timings = &types.Timings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1, Wait: float64(l.LatencyMs)}
+	}
+	return e
+}
+
+func harHeaders(h types.Headers) []harNameValue {
+	out := make([]harNameValue, 0, len(h))
+	for name, values := range h {
+		for _, v := range values {
+			out = append(out, harNameValue{Name: name, Value: v})
+		}
+	}
+	return out
+}
+
+func harCookies(cookies []types.Cookie) []harCookie {
+	out := make([]harCookie, 0, len(cookies))
+	for _, c := range cookies {
+		out = append(out, harCookie{Name: c.Name, Value: c.Value, Path: c.Path, Domain: c.Domain, HTTPOnly: c.HTTPOnly, Secure: c.Secure})
+	}
+	return out
+}
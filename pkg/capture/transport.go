@@ -0,0 +1,55 @@
+package capture
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/yourorg/apidoc/internal/httplog"
+	"github.com/yourorg/apidoc/pkg/types"
+)
+
+// Transport returns an http.RoundTripper that records exchanges made
+// through base (http.DefaultTransport when nil). The exchange is recorded
+// once the caller has read or closed the response body.
+func (r *Recorder) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &roundTripper{rec: r, base: base}
+}
+
+type roundTripper struct {
+	rec  *Recorder
+	base http.RoundTripper
+}
+
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !rt.rec.sample() {
+		return rt.base.RoundTrip(req)
+	}
+	start := time.Now()
+	out := req.Clone(req.Context())
+	reqBuf := httplog.NewBuffer(rt.rec.maxBodyBytes)
+	if req.Body != nil && req.Body != http.NoBody {
+		out.Body = httplog.NewBody(req.Body, reqBuf, nil)
+	}
+	l := newLog(out, start)
+
+	resp, err := rt.base.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	l.StatusCode = resp.StatusCode
+	l.HTTPVersion = resp.Proto
+	l.ResponseHeaders = types.Headers(resp.Header.Clone())
+	l.ResponseContentType = resp.Header.Get("Content-Type")
+	l.ResponseCookies = httplog.ResponseCookies(resp.Header)
+	respBuf := httplog.NewBuffer(rt.rec.maxBodyBytes)
+	resp.Body = httplog.NewBody(resp.Body, respBuf, func() {
+		l.LatencyMs = time.Since(start).Milliseconds()
+		l.RequestBody, l.RequestBodyEncoding = capturedBody(reqBuf, l.ContentType)
+		l.ResponseBody, _ = capturedBody(respBuf, l.ResponseContentType)
+		rt.rec.add(l)
+	})
+	return resp, nil
+}
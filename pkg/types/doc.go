@@ -1,10 +1,35 @@
 package types
 
+import (
+	"strings"
+	"unicode"
+)
+
 // GeneratedDoc is the LLM output document.
 type GeneratedDoc struct {
 	Scenario  string      `json:"scenario"`
 	CallChain []ChainStep `json:"call_chain"`
 	Endpoints []Endpoint  `json:"endpoints"`
+	// DataFlow is traced from traffic, not produced by the LLM.
+	DataFlow []DataFlow `json:"data_flow,omitempty"`
+	// PromptVersion identifies the prompts the doc was generated with.
+	PromptVersion string `json:"prompt_version,omitempty"`
+}
+
+// DataFlow is a response field whose value a later request sent. Paths are
+// endpoint templates.
+type DataFlow struct {
+	FromMethod string `json:"from_method"`
+	FromPath   string `json:"from_path"`
+	// Field is the JSONPath into the response body, e.g. $.data.id.
+	Field    string `json:"field"`
+	ToMethod string `json:"to_method"`
+	ToPath   string `json:"to_path"`
+	// In is where the request sent it: path, query, header, cookie or body.
+	In string `json:"in"`
+	// Param names the path or query parameter, header or cookie, or is the
+	// JSONPath into the request body.
+	Param string `json:"param"`
 }
 
 // ChainStep is one step in the call chain.
@@ -28,6 +53,74 @@ type Endpoint struct {
 	RequestBody *BodySchema `json:"request_body,omitempty"`
 	Responses   []Response  `json:"responses"`
 	Example     *Example    `json:"example,omitempty"`
+	// Auth lists the alternative ways the endpoint was called; each entry
+	// holds the schemes sent together, and an empty entry means some calls
+	// were anonymous. Detected from traffic, not produced by the LLM.
+	Auth [][]AuthScheme `json:"auth,omitempty"`
+	// Examples are recorded calls, one per status code and parameter
+	// combination, taken from sanitized traffic rather than the LLM.
+	Examples []RecordedExample `json:"examples,omitempty"`
+}
+
+// RecordedExample is one sanitized call to an endpoint.
+type RecordedExample struct {
+	Name       string `json:"name"`
+	Seq        int    `json:"seq"`
+	StatusCode int    `json:"status_code"`
+	// Path is the concrete request path, e.g. /users/42.
+	Path  string              `json:"path"`
+	Query map[string][]string `json:"query,omitempty"`
+	// Params are the sorted query and top-level body field names sent.
+	Params              []string `json:"params,omitempty"`
+	RequestContentType  string   `json:"request_content_type,omitempty"`
+	RequestBody         string   `json:"request_body,omitempty"`
+	ResponseContentType string   `json:"response_content_type,omitempty"`
+	ResponseBody        string   `json:"response_body,omitempty"`
+}
+
+// Auth scheme types.
+const (
+	AuthBearer        = "bearer"
+	AuthBasic         = "basic"
+	AuthAPIKey        = "apikey"
+	AuthSessionCookie = "session_cookie"
+)
+
+// AuthScheme is one credential a request carried.
+type AuthScheme struct {
+	Type string `json:"type"`
+	// In and Name locate API keys and session cookies: header, query or cookie.
+	In   string `json:"in,omitempty"`
+	Name string `json:"name,omitempty"`
+	// Format is "JWT" for bearer tokens that decode as JWTs.
+	Format string `json:"format,omitempty"`
+}
+
+// ID names the scheme in OpenAPI components.securitySchemes.
+func (a AuthScheme) ID() string {
+	switch a.Type {
+	case AuthBearer:
+		if a.Format != "" {
+			return "bearer" + a.Format
+		}
+		return "bearerAuth"
+	case AuthBasic:
+		return "basicAuth"
+	case AuthSessionCookie:
+		return "sessionCookie_" + ComponentName(a.Name)
+	}
+	return "apiKey_" + a.In + "_" + ComponentName(a.Name)
+}
+
+// ComponentName keeps the characters OpenAPI allows in component keys,
+// dropping the rest.
+func ComponentName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 128 && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' || r == '_') {
+			return r
+		}
+		return -1
+	}, s)
 }
 
 // Param defines a parameter (supports nested children).
@@ -37,6 +130,9 @@ type Param struct {
 	Required    bool    `json:"required"`
 	Description string  `json:"description"`
 	Children    []Param `json:"children,omitempty"`
+	// SchemaName optionally names the object (or array element) type, e.g.
+	// "User", so shared structures get a readable OpenAPI component name.
+	SchemaName string `json:"schema_name,omitempty"`
 }
 
 // BodySchema describes a request body.
@@ -1,6 +1,10 @@
 package types
 
-import "time"
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
 
 // Session records one imported traffic session.
 type Session struct {
@@ -21,21 +25,108 @@ type TrafficLog struct {
 	Seq                 int                 `json:"seq"`
 	Timestamp           time.Time           `json:"timestamp"`
 	Method              string              `json:"method"`
+	Scheme              string              `json:"scheme,omitempty"`
 	Host                string              `json:"host"`
 	Path                string              `json:"path"`
+	HTTPVersion         string              `json:"http_version,omitempty"`
 	QueryParams         map[string][]string `json:"query_params,omitempty"`
-	RequestHeaders      map[string]string   `json:"request_headers,omitempty"`
+	RequestHeaders      Headers             `json:"request_headers,omitempty"`
+	RequestCookies      []Cookie            `json:"request_cookies,omitempty"`
 	RequestBody         string              `json:"request_body,omitempty"`
 	RequestBodyEncoding string              `json:"request_body_encoding,omitempty"`
+	FormParams          map[string][]string `json:"form_params,omitempty"`
 	ContentType         string              `json:"content_type,omitempty"`
 	StatusCode          int                 `json:"status_code"`
-	ResponseHeaders     map[string]string   `json:"response_headers,omitempty"`
+	ResponseHeaders     Headers             `json:"response_headers,omitempty"`
+	ResponseCookies     []Cookie            `json:"response_cookies,omitempty"`
 	ResponseBody        string              `json:"response_body,omitempty"`
 	ResponseContentType string              `json:"response_content_type,omitempty"`
 	LatencyMs           int64               `json:"latency_ms"`
+	Timings             *Timings            `json:"timings,omitempty"`
 	CallCount           int                 `json:"call_count,omitempty"`
 }
 
+// Headers maps header names to all of their values, so repeated headers
+// such as Set-Cookie or Vary survive import.
+type Headers map[string][]string
+
+// Get returns the first value of the named header, matching case-insensitively.
+func (h Headers) Get(name string) string {
+	if vs := h.Values(name); len(vs) > 0 {
+		return vs[0]
+	}
+	return ""
+}
+
+// Values returns all values of the named header, matching case-insensitively.
+func (h Headers) Values(name string) []string {
+	if vs, ok := h[name]; ok {
+		return vs
+	}
+	for k, vs := range h {
+		if strings.EqualFold(k, name) {
+			return vs
+		}
+	}
+	return nil
+}
+
+// Add appends a value to the named header.
+func (h Headers) Add(name, value string) {
+	h[name] = append(h[name], value)
+}
+
+// UnmarshalJSON accepts both {"name": ["v1","v2"]} and the legacy
+// single-valued {"name": "v"} form.
+func (h *Headers) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		*h = nil
+		return nil
+	}
+	out := make(Headers, len(raw))
+	for k, v := range raw {
+		var single string
+		if err := json.Unmarshal(v, &single); err == nil {
+			out[k] = []string{single}
+			continue
+		}
+		var multi []string
+		if err := json.Unmarshal(v, &multi); err != nil {
+			return err
+		}
+		out[k] = multi
+	}
+	*h = out
+	return nil
+}
+
+// Cookie is one request or response cookie as recorded in HAR.
+type Cookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	Expires  string `json:"expires,omitempty"`
+	HTTPOnly bool   `json:"http_only,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+	SameSite string `json:"same_site,omitempty"`
+}
+
+// Timings is the HAR timing breakdown in milliseconds; -1 means not applicable.
+type Timings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	SSL     float64 `json:"ssl"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
 // LLMCache stores one batch generation output.
 type LLMCache struct {
 	SessionID  string    `json:"session_id"`
@@ -47,4 +138,17 @@ type LLMCache struct {
 	TokensUsed int       `json:"tokens_used"`
 	ErrorMsg   string    `json:"error_msg"`
 	CreatedAt  time.Time `json:"created_at"`
+	// PromptVersion is the Prompts.Version the output was generated with.
+	PromptVersion string `json:"prompt_version"`
+}
+
+// Translation stores a session's doc texts translated into one language.
+type Translation struct {
+	SessionID string `json:"session_id"`
+	Lang      string `json:"lang"`
+	// Texts maps each source-language text to its translation.
+	Texts         map[string]string `json:"texts"`
+	Model         string            `json:"model"`
+	PromptVersion string            `json:"prompt_version"`
+	CreatedAt     time.Time         `json:"created_at"`
 }